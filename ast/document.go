@@ -1,10 +1,157 @@
 package ast
 
+import (
+	"strconv"
+	"strings"
+)
+
+// --[ Location ]-----------------------------------------------------
+
+// Loc locates a node within the source of its document.  Start and End are the byte offsets
+// of the first character of the node and of the character following it.  Line and Column
+// locate Start; both begin at 1 and the column counts characters rather than bytes.  Only
+// Line and Column are marshaled so that a Loc may be reported as the location of an error.
+type Loc struct {
+	Start  Pos `json:"-"`
+	End    Pos `json:"-"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// --[ Values ]-------------------------------------------------------
+
+// Value is implemented by each kind of input value that may appear as an argument or a
+// default; String returns the value as it would be written in a query
+type Value interface {
+	String() string
+	Location() Loc
+	isValue()
+}
+
+// IntValue holds an integer literal as written e.g. 123
+type IntValue struct {
+	Value string `json:"int"`
+	Loc   Loc    `json:"loc"`
+}
+
+// FloatValue holds a floating point literal as written e.g. 1.5e3
+type FloatValue struct {
+	Value string `json:"float"`
+	Loc   Loc    `json:"loc"`
+}
+
+// StringValue holds a string literal with any escape sequences decoded
+type StringValue struct {
+	Value string `json:"string"`
+	Loc   Loc    `json:"loc"`
+}
+
+type BooleanValue struct {
+	Value bool `json:"boolean"`
+	Loc   Loc  `json:"loc"`
+}
+
+type NullValue struct {
+	Loc Loc `json:"loc"`
+}
+
+// EnumValue holds an unquoted name used as a value e.g. NEWHOPE
+type EnumValue struct {
+	Value string `json:"enum"`
+	Loc   Loc    `json:"loc"`
+}
+
+type ListValue struct {
+	Values []Value `json:"list"`
+	Loc    Loc     `json:"loc"`
+}
+
+type ObjectValue struct {
+	Fields []*ObjectField `json:"object"`
+	Loc    Loc            `json:"loc"`
+}
+
+type ObjectField struct {
+	Name  string `json:"name"`
+	Value Value  `json:"value"`
+	Loc   Loc    `json:"loc"`
+}
+
+// Variable references the value of an operation variable e.g. $id
+type Variable struct {
+	Name string `json:"variable"`
+	Loc  Loc    `json:"loc"`
+}
+
+func (v *IntValue) String() string     { return v.Value }
+func (v *FloatValue) String() string   { return v.Value }
+func (v *StringValue) String() string  { return strconv.Quote(v.Value) }
+func (v *BooleanValue) String() string { return strconv.FormatBool(v.Value) }
+func (v *NullValue) String() string    { return "null" }
+func (v *EnumValue) String() string    { return v.Value }
+func (v *Variable) String() string     { return "$" + v.Name }
+
+func (v *ListValue) String() string {
+	values := make([]string, len(v.Values))
+	for index, value := range v.Values {
+		values[index] = value.String()
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func (v *ObjectValue) String() string {
+	fields := make([]string, len(v.Fields))
+	for index, field := range v.Fields {
+		fields[index] = field.Name + ": " + field.Value.String()
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (v *IntValue) Location() Loc     { return v.Loc }
+func (v *FloatValue) Location() Loc   { return v.Loc }
+func (v *StringValue) Location() Loc  { return v.Loc }
+func (v *BooleanValue) Location() Loc { return v.Loc }
+func (v *NullValue) Location() Loc    { return v.Loc }
+func (v *EnumValue) Location() Loc    { return v.Loc }
+func (v *ListValue) Location() Loc    { return v.Loc }
+func (v *ObjectValue) Location() Loc  { return v.Loc }
+func (v *Variable) Location() Loc     { return v.Loc }
+
+func (v *IntValue) isValue()     {}
+func (v *FloatValue) isValue()   {}
+func (v *StringValue) isValue()  {}
+func (v *BooleanValue) isValue() {}
+func (v *NullValue) isValue()    {}
+func (v *EnumValue) isValue()    {}
+func (v *ListValue) isValue()    {}
+func (v *ObjectValue) isValue()  {}
+func (v *Variable) isValue()     {}
+
 // --[ Arg ]----------------------------------------------------------
 
 type Arg struct {
 	Name  string `json:"name,omitempty"`
-	Value string `json:"value"`
+	Value Value  `json:"value"`
+	Loc   Loc    `json:"loc"`
+}
+
+// --[ Directive ]----------------------------------------------------
+
+// Directive annotates a field or fragment e.g. @include(if: $withFriends)
+type Directive struct {
+	Name string `json:"name"`
+	Args []*Arg `json:"args,omitempty"`
+	Loc  Loc    `json:"loc"`
+}
+
+// Arg returns the named argument or nil if the directive wasn't given one
+func (d *Directive) Arg(name string) *Arg {
+	for _, arg := range d.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
 }
 
 // --[ Filter ]-------------------------------------------------------
@@ -14,7 +161,7 @@ type Filter struct {
 	Args []*Arg `json:"args,omitempty"`
 }
 
-func (op *Filter) addArg(name, value string) *Arg {
+func (op *Filter) addArg(name string, value Value) *Arg {
 	arg := &Arg{
 		Name:  name,
 		Value: value,
@@ -26,11 +173,13 @@ func (op *Filter) addArg(name, value string) *Arg {
 // --[ Field ]--------------------------------------------------------
 
 type Field struct {
-	Alias      string     `json:"alias,omitempty"`
-	Name       string     `json:"name,omitempty"`
-	Args       []*Arg     `json:"args,omitempty"`
-	Selection  *Selection `json:"selector,omitempty"`
-	Operations []*Filter  `json:"operations,omitempty"`
+	Alias      string       `json:"alias,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Args       []*Arg       `json:"args,omitempty"`
+	Directives []*Directive `json:"directives,omitempty"`
+	Selection  *Selection   `json:"selector,omitempty"`
+	Operations []*Filter    `json:"operations,omitempty"`
+	Loc        Loc          `json:"loc"`
 }
 
 func (f *Field) Key() string {
@@ -42,16 +191,7 @@ func (f *Field) Key() string {
 }
 
 func (f *Field) IsScalar() bool {
-	return f.Selection == nil || len(f.Selection.Fields) == 0
-}
-
-func (f *Field) addArg(name, value string) *Arg {
-	arg := &Arg{
-		Name:  name,
-		Value: value,
-	}
-	f.Args = append(f.Args, arg)
-	return arg
+	return f.Selection == nil || len(f.Selection.Selectors) == 0
 }
 
 func (f *Field) addSelection() *Selection {
@@ -66,10 +206,20 @@ func newField(alias, name string) *Field {
 	}
 }
 
+func (f *Field) isSelector() {}
+
 // --[ Selector ]-----------------------------------------------------
 
+// Selector is implemented by the elements of a selection set; *Field,
+// *FragmentSpread and *InlineFragment
+type Selector interface {
+	isSelector()
+}
+
+// Selection holds the selectors of a selection set in document order
 type Selection struct {
-	Fields []*Field `json:"fields,omitempty"`
+	Selectors []Selector `json:"selectors,omitempty"`
+	Loc       Loc        `json:"loc"`
 }
 
 func (s *Selection) addAlias(alias, name string) *Field {
@@ -77,7 +227,7 @@ func (s *Selection) addAlias(alias, name string) *Field {
 		Alias: alias,
 		Name:  name,
 	}
-	s.Fields = append(s.Fields, field)
+	s.Selectors = append(s.Selectors, field)
 	return field
 }
 
@@ -85,6 +235,98 @@ func (s *Selection) addField(name string) *Field {
 	return s.addAlias("", name)
 }
 
+func (s *Selection) addFragmentSpread(name string) *FragmentSpread {
+	spread := &FragmentSpread{
+		Name: name,
+	}
+	s.Selectors = append(s.Selectors, spread)
+	return spread
+}
+
+func (s *Selection) addInlineFragment(on string) *InlineFragment {
+	fragment := &InlineFragment{
+		On: on,
+	}
+	s.Selectors = append(s.Selectors, fragment)
+	return fragment
+}
+
+// --[ Fragments ]----------------------------------------------------
+
+// FragmentSpread references a named fragment from within a selection set e.g. ...friendFields
+type FragmentSpread struct {
+	Name       string       `json:"name"`
+	Directives []*Directive `json:"directives,omitempty"`
+	Loc        Loc          `json:"loc"`
+}
+
+func (f *FragmentSpread) isSelector() {}
+
+// Fragment holds a named fragment definition e.g. fragment friendFields on User { ... }
+type Fragment struct {
+	Name       string       `json:"name"`
+	On         string       `json:"on"`
+	Directives []*Directive `json:"directives,omitempty"`
+	Selection  *Selection   `json:"selector,omitempty"`
+	Loc        Loc          `json:"loc"`
+}
+
+func (f *Fragment) addSelection() *Selection {
+	f.Selection = &Selection{}
+	return f.Selection
+}
+
+// InlineFragment holds an anonymous fragment within a selection set e.g. ... on Droid { ... }.
+// On is empty when the fragment has no type condition.
+type InlineFragment struct {
+	On         string       `json:"on,omitempty"`
+	Directives []*Directive `json:"directives,omitempty"`
+	Selection  *Selection   `json:"selector,omitempty"`
+	Loc        Loc          `json:"loc"`
+}
+
+func (f *InlineFragment) isSelector() {}
+
+func (f *InlineFragment) addSelection() *Selection {
+	f.Selection = &Selection{}
+	return f.Selection
+}
+
+// --[ Variables ]----------------------------------------------------
+
+// Type references a named, list or non-null type e.g. Int, [String!]!
+type Type struct {
+	Name    string `json:"name,omitempty"`
+	Elem    *Type  `json:"elem,omitempty"`
+	NonNull bool   `json:"nonNull,omitempty"`
+	Loc     Loc    `json:"loc"`
+}
+
+// IsList reports whether the type is a list of Elem
+func (t *Type) IsList() bool {
+	return t.Elem != nil
+}
+
+func (t *Type) String() string {
+	s := t.Name
+	if t.IsList() {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s = s + "!"
+	}
+	return s
+}
+
+// VariableDefinition declares an operation variable e.g. $id: Int = 1.  DefaultValue is nil
+// when the variable has no default.
+type VariableDefinition struct {
+	Name         string `json:"name"`
+	Type         *Type  `json:"type"`
+	DefaultValue Value  `json:"defaultValue,omitempty"`
+	Loc          Loc    `json:"loc"`
+}
+
 // --[ Operations ]---------------------------------------------------
 
 //go:generate go get github.com/campoy/jsonenums
@@ -97,35 +339,61 @@ const (
 	OpMutation
 )
 
+// Operation holds a query or mutation e.g. query hero($id: ID!) @cached { hero(id: $id) { name } }.
+// Name is empty for anonymous operations, including the query shorthand { ... }.
+//
+// Operations written in the legacy form, where the keyword is followed by a root field rather
+// than a selection set e.g. query city: GET(url: "...") { ... } or query user(id: 1) { ... },
+// are read as an operation named by the response key of the field with that field as the
+// only element of its SelectionSet.
 type Operation struct {
-	Type  OperationType `json:"type"`
-	Field *Field        `json:"field,omitempty"`
+	Type                OperationType         `json:"type"`
+	Name                string                `json:"name,omitempty"`
+	VariableDefinitions []*VariableDefinition `json:"variables,omitempty"`
+	Directives          []*Directive          `json:"directives,omitempty"`
+	SelectionSet        *Selection            `json:"selectionSet"`
+	Loc                 Loc                   `json:"loc"`
 }
 
-func newOperation(opType OperationType, alias, name string) *Operation {
-	return &Operation{
-		Type:  opType,
-		Field: newField(alias, name),
+// Variable returns the named variable definition or nil if the operation doesn't declare one
+func (o *Operation) Variable(name string) *VariableDefinition {
+	for _, v := range o.VariableDefinitions {
+		if v.Name == name {
+			return v
+		}
 	}
+	return nil
 }
 
 // --[ Document ]-----------------------------------------------------
 
 type Document struct {
 	Operations []*Operation `json:"operations"`
+	Fragments  []*Fragment  `json:"fragments,omitempty"`
 }
 
-func (d *Document) HasDefaultQueryOnly() bool {
-	return len(d.Operations) == 1 && d.Operations[0].Field.Name == ""
+// Fragment returns the named fragment or nil if the document doesn't define one
+func (d *Document) Fragment(name string) *Fragment {
+	for _, fragment := range d.Fragments {
+		if fragment.Name == name {
+			return fragment
+		}
+	}
+	return nil
 }
 
-func (d *Document) addOperation(opType OperationType, name string) *Operation {
-	op := &Operation{
-		Type:  opType,
-		Field: newField("", name),
+// Operation returns the named operation or nil if the document doesn't define one
+func (d *Document) Operation(name string) *Operation {
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op
+		}
 	}
-	d.Operations = append(d.Operations, op)
-	return op
+	return nil
+}
+
+func (d *Document) HasDefaultQueryOnly() bool {
+	return len(d.Operations) == 1 && d.Operations[0].Name == ""
 }
 
 type parseFn func(iter *iterator) parseFn
@@ -0,0 +1,63 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// --[ Syntax Errors ]------------------------------------------------
+
+// SyntaxError is returned by Parse and ParseSchema when a document can't be read.  Loc points
+// at the token where parsing failed and Excerpt holds the lines of source leading up to it
+// with a caret beneath the offending column e.g.
+//
+//	1 | {
+//	2 |   hero(id: )
+//	  |            ^
+type SyntaxError struct {
+	Message string
+	Loc     Loc
+	Excerpt string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error: %v (%v:%v)\n%v", e.Message, e.Loc.Line, e.Loc.Column, e.Excerpt)
+}
+
+// excerpt returns the line of input that loc falls on, along with the line before it, and a
+// caret marking the column
+func excerpt(input string, loc Loc) string {
+	lines := strings.Split(input, "\n")
+	if loc.Line < 1 || loc.Line > len(lines) {
+		return ""
+	}
+
+	first := loc.Line - 1
+	if first < 1 {
+		first = 1
+	}
+	width := len(fmt.Sprint(loc.Line))
+
+	buf := bytes.NewBuffer(nil)
+	for n := first; n <= loc.Line; n++ {
+		fmt.Fprintf(buf, "%*d | %s\n", width, n, strings.TrimRight(lines[n-1], "\r"))
+	}
+
+	// tabs are kept so the caret lines up however the excerpt is displayed
+	fmt.Fprintf(buf, "%*s | ", width, "")
+	line := lines[loc.Line-1]
+	for column := 1; column < loc.Column && line != ""; column++ {
+		r, size := utf8.DecodeRuneInString(line)
+		if r == '\t' {
+			buf.WriteRune('\t')
+		} else {
+			buf.WriteRune(' ')
+		}
+		line = line[size:]
+	}
+	buf.WriteString("^")
+
+	return buf.String()
+}
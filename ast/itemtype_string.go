@@ -4,9 +4,9 @@ package ast
 
 import "fmt"
 
-const _itemType_name = "itemErroritemEOFitemNameitemVariableitemLeftCurlyitemRightCurlyitemLeftParenitemRightParenitemLeftSquareitemRightSquareitemAtSignitemColonitemCommaitemDotitemNilitemEqualitemIntValueitemStringValueitemFloatValueitemKeyworditemQueryitemMutationitemFragmentitemEllipsesitemTrueitemFalseitemOnitemIntTypeitemFloatTypeitemBooleanTypeitemEnumTypeitemArrayTypeitemObjectType"
+const _itemType_name = "itemErroritemEOFitemNameitemVariableitemLeftCurlyitemRightCurlyitemLeftParenitemRightParenitemLeftSquareitemRightSquareitemAtSignitemColonitemCommaitemDotitemNilitemEqualitemBangitemPipeitemAmpitemIntValueitemStringValueitemFloatValueitemBlockStringitemKeyworditemQueryitemMutationitemFragmentitemEllipsesitemTrueitemFalseitemOnitemIntTypeitemFloatTypeitemBooleanTypeitemEnumTypeitemArrayTypeitemObjectType"
 
-var _itemType_index = [...]uint16{0, 9, 16, 24, 36, 49, 63, 76, 90, 104, 119, 129, 138, 147, 154, 161, 170, 182, 197, 211, 222, 231, 243, 255, 267, 275, 284, 290, 301, 314, 329, 341, 354, 368}
+var _itemType_index = [...]uint16{0, 9, 16, 24, 36, 49, 63, 76, 90, 104, 119, 129, 138, 147, 154, 161, 170, 178, 186, 193, 205, 220, 234, 249, 260, 269, 281, 293, 305, 313, 322, 328, 339, 352, 367, 379, 392, 406}
 
 func (i itemType) String() string {
 	if i < 0 || i+1 >= itemType(len(_itemType_index)) {
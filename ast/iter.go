@@ -1,23 +1,29 @@
 package ast
 
 import (
-	"errors"
 	"fmt"
 )
 
 type iterator struct {
-	l          *lexer
-	err        error
-	operations []*Operation
-	operation  *Operation
-	selectors  []*Selection
-	selection  *Selection
-	field      *Field
-	filter     *Filter
+	l              *lexer
+	err            error
+	operations     []*Operation
+	operation      *Operation
+	opType         OperationType
+	start          Loc // the location of the definition being parsed
+	fragments      []*Fragment
+	inlineFragment *InlineFragment
+	selectors      []*Selection
+	selection      *Selection
+	field          *Field
+	filter         *Filter
 
 	tokens    [16]item
 	tokensPos Pos
 	tokensEOF bool
+	last      item     // the EOF or error that ended the stream
+	prev      item     // the item most recently consumed by next
+	owners    [][]*Loc // the nodes that end with each open selection set
 }
 
 func newIterator(l *lexer) *iterator {
@@ -25,20 +31,37 @@ func newIterator(l *lexer) *iterator {
 		l:         l,
 		selectors: []*Selection{},
 	}
-	iter.pushSelector(&Selection{})
 
 	for i := 0; i < len(iter.tokens); i++ {
-		item := l.nextItem()
-		iter.tokens[i] = item
-		if item.typ == itemEOF {
-			iter.tokensEOF = true
-			break
-		}
+		iter.tokens[i] = iter.fetch()
 	}
 
 	return iter
 }
 
+// fetch reads the next item from the lexer.  The lexer stops after it emits EOF or an error
+// so from then on that item is repeated rather than waiting on a lexer that has finished.
+func (iter *iterator) fetch() item {
+	if iter.tokensEOF {
+		return iter.last
+	}
+
+	item := iter.l.nextItem()
+	if item.typ == itemEOF || item.typ == itemError {
+		iter.tokensEOF = true
+		iter.last = item
+	}
+	return item
+}
+
+// drain consumes whatever the lexer has yet to emit so that its goroutine can exit when the
+// parser stops early
+func (iter *iterator) drain() {
+	for !iter.tokensEOF {
+		iter.fetch()
+	}
+}
+
 func (iter *iterator) next() item {
 	if iter.tokensPos == eof {
 		panic("invalid call to next; past end of stream")
@@ -47,16 +70,13 @@ func (iter *iterator) next() item {
 	// retrieve the next value
 	item := iter.tokens[iter.tokensPos]
 
-	// fetch next element unless we've come to the end
-	if !iter.tokensEOF {
-		item := iter.l.nextItem()
-		iter.tokens[iter.tokensPos] = item
-		iter.tokensEOF = (item.typ == itemEOF)
-	}
+	// fetch the next element
+	iter.tokens[iter.tokensPos] = iter.fetch()
 
 	// advance the pointer
 	iter.tokensPos = (iter.tokensPos + 1) % Pos(len(iter.tokens))
 
+	iter.prev = item
 	return item
 }
 
@@ -82,18 +102,100 @@ func (iter *iterator) peekN(n Pos) item {
 	return iter.tokens[pos]
 }
 
-func (iter *iterator) errorf(format string, args ...interface{}) parseFn {
-	iter.err = errors.New(fmt.Sprintf(format, args...))
+// loc returns the location of an item within the source
+func (iter *iterator) loc(item item) Loc {
+	// the values of variables and strings exclude their $ and quotes
+	start, end := item.pos, item.end
+	switch item.typ {
+	case itemVariable:
+		start--
+	case itemStringValue:
+		start, end = start-1, end+1
+	case itemBlockString:
+		start, end = start-Pos(len(blockQuote)), end+Pos(len(blockQuote))
+	}
+
+	loc := iter.l.locate(start)
+	loc.Start = start
+	loc.End = end
+	return loc
+}
+
+// span returns the location of the node that began with from and ended with the item most
+// recently consumed
+func (iter *iterator) span(from item) Loc {
+	loc := iter.loc(from)
+	loc.End = iter.prev.end
+	return loc
+}
+
+// syntaxError returns an error located at item.  Errors from the lexer are reported as they
+// are, at the token that couldn't be read.
+func (iter *iterator) syntaxError(item item, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	if item.typ == itemError {
+		message = item.val
+	}
+
+	loc := iter.loc(item)
+	loc.End = loc.Start
+	return &SyntaxError{
+		Message: message,
+		Loc:     loc,
+		Excerpt: excerpt(iter.l.input, loc),
+	}
+}
+
+func (iter *iterator) errorf(item item, format string, args ...interface{}) parseFn {
+	return iter.fail(iter.syntaxError(item, format, args...))
+}
+
+// fail stops the parse with an error returned by one of the recursive parse functions
+func (iter *iterator) fail(err error) parseFn {
+	iter.err = err
 	return nil
 }
 
-func (iter *iterator) addQuery(alias, name string) *Operation {
-	iter.operation = newOperation(OpQuery, alias, name)
+// annotate prefixes the message of a syntax error with the context in which it occurred
+func annotate(err error, format string, args ...interface{}) error {
+	if v, ok := err.(*SyntaxError); ok {
+		v.Message = fmt.Sprintf(format, args...) + " => " + v.Message
+	}
+	return err
+}
+
+// addOperation adds an operation of the type most recently read e.g. query or mutation
+func (iter *iterator) addOperation(name string) *Operation {
+	iter.operation = &Operation{
+		Type: iter.opType,
+		Name: name,
+		Loc:  iter.start,
+	}
 	iter.operations = append(iter.operations, iter.operation)
-	iter.field = iter.operation.Field
+	iter.field = nil
 	return iter.operation
 }
 
+// addRootField adds the root field of a legacy operation once its name has been read.  The
+// field is the only element of the operation's selection set, which has no braces of its own
+// and so is located where the field is.
+func (iter *iterator) addRootField(alias, name string, start item) {
+	iter.operation.SelectionSet = &Selection{Loc: iter.span(start)}
+	iter.field = iter.operation.SelectionSet.addAlias(alias, name)
+	iter.field.Loc = iter.span(start)
+}
+
+func (iter *iterator) addFragment(name, on string) *Fragment {
+	fragment := &Fragment{
+		Name: name,
+		On:   on,
+		Loc:  iter.start,
+	}
+	iter.fragments = append(iter.fragments, fragment)
+	iter.field = nil
+	return fragment
+}
+
 func (iter *iterator) addAlias(alias, name string) {
 	iter.field = iter.selection.addAlias(alias, name)
 }
@@ -102,29 +204,63 @@ func (iter *iterator) addField(name string) {
 	iter.field = iter.selection.addField(name)
 }
 
-func (iter *iterator) addFieldArg(name, value string) {
+func (iter *iterator) addFragmentSpread(name string) *FragmentSpread {
+	iter.field = nil
+	return iter.selection.addFragmentSpread(name)
+}
+
+func (iter *iterator) addInlineFragment(on string) {
+	iter.inlineFragment = iter.selection.addInlineFragment(on)
+	iter.field = nil
+}
+
+func (iter *iterator) addFieldArgs(args []*Arg) {
 	if iter.field != nil {
-		iter.field.addArg(name, value)
+		iter.field.Args = append(iter.field.Args, args...)
 	}
 }
 
+func (iter *iterator) addVariable(name string, typ *Type) *VariableDefinition {
+	v := &VariableDefinition{
+		Name: name,
+		Type: typ,
+	}
+	iter.operation.VariableDefinitions = append(iter.operation.VariableDefinitions, v)
+	return v
+}
+
 func (iter *iterator) addSelection() *Selection {
 	iter.selection = iter.field.addSelection()
 	return iter.selection
 }
 
-func (iter *iterator) pushSelector(s *Selection) {
+// pushSelector opens a selection set once its left curly has been read.  owners are the
+// locations of the nodes that end when the selection set does.
+func (iter *iterator) pushSelector(s *Selection, owners ...*Loc) {
+	s.Loc = iter.loc(iter.prev)
 	iter.selectors = append(iter.selectors, s)
+	iter.owners = append(iter.owners, owners)
 	iter.selection = s
 	iter.field = nil
 }
 
+// popSelector closes the current selection set and makes the enclosing one current; once
+// the outermost selection set has been closed, selection is nil
 func (iter *iterator) popSelector() *Selection {
 	length := len(iter.selectors)
 	s := iter.selectors[length-1]
 	iter.selectors = iter.selectors[0 : length-1]
 
-	iter.selection = s
+	s.Loc.End = iter.prev.end
+	for _, owner := range iter.owners[length-1] {
+		owner.End = iter.prev.end
+	}
+	iter.owners = iter.owners[0 : length-1]
+
+	iter.selection = nil
+	if length > 1 {
+		iter.selection = iter.selectors[length-2]
+	}
 	iter.field = nil
 
 	return s
@@ -44,10 +44,14 @@ const (
 	itemDot         // the cursor, spelled '.'
 	itemNil         // the untyped nil constant, easiest to treat as a keyword
 	itemEqual       // equal sign
+	itemBang        // non-null type marker, '!'
+	itemPipe        // separates union members and directive locations, '|'
+	itemAmp         // separates implemented interfaces, '&'
 
 	itemIntValue    // integer
 	itemStringValue // string
 	itemFloatValue  // floating point number
+	itemBlockString // block string, """
 
 	// ONLY KEYWORDS BELOW THIS POINT
 	itemKeyword     // used only to delimit the keywords
@@ -67,6 +71,7 @@ const (
 )
 
 var keywords = map[itemType]string{
+	itemNil:         "null",
 	itemQuery:       "query",
 	itemMutation:    "mutation",
 	itemFragment:    "fragment",
@@ -110,9 +115,9 @@ func (l *lexer) acceptUnicode() bool {
 	return true
 }
 
-// run runs the state machine for the lexer.
+// run runs the state machine for the lexer from its initial state.
 func (l *lexer) run() {
-	for l.state = lexDocument; l.state != nil; {
+	for l.state != nil {
 		l.state = l.state(l)
 	}
 }
@@ -126,6 +131,9 @@ const (
 	plus        = '+'
 	minus       = '-'
 	equalSign   = '='
+	bang        = '!'
+	pipe        = '|'
+	amp         = '&'
 	doubleQuote = '"'
 	leftSquare  = '['
 	rightSquare = ']'
@@ -141,6 +149,7 @@ const (
 	whitespace       = ", \t\n\r"
 	lineTerminator   = "\n\r"
 	digits           = "0123456789"
+	blockQuote       = `"""`
 	escapeCharacters = `"\/bfnrt` // see - https://github.com/facebook/graphql/blob/master/Section%208%20--%20Grammar.md
 )
 
@@ -212,6 +221,13 @@ func lexField(l *lexer) stateFn {
 	case isAlpha(r):
 		return l.scanField(lexAfterField)
 
+	case r == leftCurly && l.depth == 0:
+		return lexSelectionSet
+
+	case (r == leftParen || r == atSign) && l.depth == 0:
+		// an anonymous operation with variable definitions or directives
+		return lexAfterField
+
 	default:
 		return l.errorf("expected character for operation name")
 	}
@@ -305,7 +321,7 @@ func lexArgument(l *lexer) stateFn {
 		return l.scanField(lexColon)
 
 	case r == dollar:
-		return l.scanVariable(lexColon)
+		return l.scanVariable(lexVariableColon)
 
 	case r == rightParen:
 		l.next()
@@ -317,6 +333,26 @@ func lexArgument(l *lexer) stateFn {
 	}
 }
 
+// lexVariableColon separates a variable definition from its type e.g. $id: Int
+func lexVariableColon(l *lexer) stateFn {
+	r := l.peek()
+	switch {
+	case isWhitespace(r):
+		return l.ignoreWhitespace(lexVariableColon)
+
+	case isComment(r):
+		return l.ignoreComment(lexVariableColon)
+
+	case r == colon:
+		l.next()
+		l.emit(itemColon)
+		return l.scanTypeRef(lexDefaultValue)
+
+	default:
+		return l.errorf("expected colon")
+	}
+}
+
 func lexColon(l *lexer) stateFn {
 	r := l.peek()
 	switch {
@@ -484,6 +520,59 @@ func lexAfterFragmentType(l *lexer) stateFn {
 	}
 }
 
+// punctuation holds the single character tokens of schema definition language
+var punctuation = map[rune]itemType{
+	leftCurly:   itemLeftCurly,
+	rightCurly:  itemRightCurly,
+	leftParen:   itemLeftParen,
+	rightParen:  itemRightParen,
+	leftSquare:  itemLeftSquare,
+	rightSquare: itemRightSquare,
+	colon:       itemColon,
+	bang:        itemBang,
+	equalSign:   itemEqual,
+	atSign:      itemAtSign,
+	pipe:        itemPipe,
+	amp:         itemAmp,
+}
+
+// lexDefinitions scans schema definition language.  Unlike queries, the shape of SDL doesn't
+// depend on what came before so every token is scanned from this one state.
+func lexDefinitions(l *lexer) stateFn {
+	r := l.peek()
+	switch {
+	case isWhitespace(r):
+		return l.ignoreWhitespace(lexDefinitions)
+
+	case isComment(r):
+		return l.ignoreComment(lexDefinitions)
+
+	case l.hasPrefix(blockQuote):
+		return l.scanBlockString(lexDefinitions)
+
+	case r == doubleQuote:
+		return l.scanString(lexDefinitions)
+
+	case r == plus || r == minus || isNumeric(r):
+		return l.scanNumber(lexDefinitions)
+
+	case isAlpha(r):
+		return l.scanName(lexDefinitions)
+
+	case r == eof:
+		l.emit(itemEOF)
+		return nil
+	}
+
+	if typ, ok := punctuation[r]; ok {
+		l.next()
+		l.emit(typ)
+		return lexDefinitions
+	}
+
+	return l.errorf("unexpected character in schema definition, %q", r)
+}
+
 func (l *lexer) hasPrefix(word string) bool {
 	return strings.HasPrefix(l.input[l.pos:], word)
 }
@@ -522,7 +611,12 @@ func (l *lexer) scanValue(fn stateFn) stateFn {
 	case r == leftSquare:
 		l.next()
 		l.emit(itemLeftSquare)
-		return l.scanArray(fn)
+		return l.scanList(fn)
+
+	case r == leftCurly:
+		l.next()
+		l.emit(itemLeftCurly)
+		return l.scanObject(fn)
 
 	case r == doubleQuote:
 		return l.scanString(fn)
@@ -533,46 +627,103 @@ func (l *lexer) scanValue(fn stateFn) stateFn {
 	case r == dollar:
 		return l.scanVariable(fn)
 
-	case strings.HasPrefix(l.input[l.pos:], keywords[itemTrue]):
-		l.acceptOrdered("true")
-		l.emit(itemTrue)
-		return fn
-
-	case strings.HasPrefix(l.input[l.pos:], keywords[itemFalse]):
-		l.acceptOrdered("false")
-		l.emit(itemFalse)
-		return fn
-
 	case isAlpha(r):
-		return l.scanType(fn)
+		return l.scanName(fn)
 
 	default:
 		return l.errorf("illegal value")
 	}
 }
 
-func (l *lexer) scanArray(fn stateFn) stateFn {
+// scanList scans the values of a list up to and including the closing ]
+func (l *lexer) scanList(fn stateFn) stateFn {
 	r := l.peek()
 	switch {
 	case isWhitespace(r):
 		l.acceptRun(whitespace)
 		l.ignore()
-		return l.scanValue(fn)
+		return l.scanList(fn)
 
 	case isComment(r):
 		l.next()
 		l.acceptFn(isNotLineTerminator)
 		l.ignore()
-		return l.scanValue(fn)
+		return l.scanList(fn)
 
 	case r == rightSquare:
+		l.next()
+		l.emit(itemRightSquare)
 		return fn
 
+	case r == eof:
+		return l.errorf("unterminated list value")
+
 	default:
-		return l.scanArray(fn)
+		return l.scanValue(func(l *lexer) stateFn {
+			return l.scanList(fn)
+		})
 	}
 }
 
+// scanObject scans the name: value pairs of an input object up to and including the closing }
+func (l *lexer) scanObject(fn stateFn) stateFn {
+	r := l.peek()
+	switch {
+	case isWhitespace(r):
+		l.acceptRun(whitespace)
+		l.ignore()
+		return l.scanObject(fn)
+
+	case isComment(r):
+		l.next()
+		l.acceptFn(isNotLineTerminator)
+		l.ignore()
+		return l.scanObject(fn)
+
+	case r == rightCurly:
+		l.next()
+		l.emit(itemRightCurly)
+		return fn
+
+	case isAlpha(r):
+		l.acceptFn(isAlphaNumeric)
+		l.emit(itemName)
+
+		l.acceptRun(whitespace)
+		l.ignore()
+		if r := l.peek(); r != colon {
+			return l.errorf("expected colon after object field name")
+		}
+		l.next()
+		l.emit(itemColon)
+
+		return l.scanValue(func(l *lexer) stateFn {
+			return l.scanObject(fn)
+		})
+
+	default:
+		return l.errorf("unexpected element in object value")
+	}
+}
+
+// scanName scans an unquoted value; true, false and null are emitted as keywords and
+// anything else as a name e.g. an enum value
+func (l *lexer) scanName(fn stateFn) stateFn {
+	l.acceptFn(isAlphaNumeric)
+
+	switch l.input[l.start:l.pos] {
+	case keywords[itemTrue]:
+		l.emit(itemTrue)
+	case keywords[itemFalse]:
+		l.emit(itemFalse)
+	case keywords[itemNil]:
+		l.emit(itemNil)
+	default:
+		l.emit(itemName)
+	}
+	return fn
+}
+
 func (l *lexer) scanField(fn stateFn) stateFn {
 	if r := l.peek(); !isAlpha(r) {
 		return l.errorf("invalid field; fields must start with an alpha character")
@@ -637,15 +788,82 @@ func (l *lexer) scanString(fn stateFn) stateFn {
 	}
 }
 
-func (l *lexer) scanType(fn stateFn) stateFn {
-	for _, typ := range allTypes {
-		if l.acceptOrdered(keywords[typ]) {
-			l.emit(typ)
+// scanBlockString scans a """ delimited string; the raw contents are emitted and \""" is the
+// only escape sequence recognized
+func (l *lexer) scanBlockString(fn stateFn) stateFn {
+	l.pos += Pos(len(blockQuote))
+	l.ignore()
+
+	for {
+		switch {
+		case l.hasPrefix(`\` + blockQuote):
+			l.pos += Pos(len(blockQuote) + 1)
+
+		case l.hasPrefix(blockQuote):
+			l.emit(itemBlockString)
+			l.pos += Pos(len(blockQuote))
+			l.ignore()
 			return fn
+
+		case l.next() == eof:
+			return l.errorf("unterminated block string")
 		}
 	}
+}
+
+// scanTypeRef scans a type reference such as Int, User!, or [String!]; built in types are
+// emitted as their type keyword, all others as names
+func (l *lexer) scanTypeRef(fn stateFn) stateFn {
+	r := l.peek()
+	switch {
+	case isWhitespace(r):
+		l.acceptRun(whitespace)
+		l.ignore()
+		return l.scanTypeRef(fn)
+
+	case isComment(r):
+		l.next()
+		l.acceptFn(isNotLineTerminator)
+		l.ignore()
+		return l.scanTypeRef(fn)
+
+	case r == leftSquare:
+		l.next()
+		l.emit(itemLeftSquare)
+		return l.scanTypeRef(func(l *lexer) stateFn {
+			l.acceptRun(whitespace)
+			l.ignore()
+			if r := l.peek(); r != rightSquare {
+				return l.errorf("expected ] to close list type")
+			}
+			l.next()
+			l.emit(itemRightSquare)
+			return l.scanNonNull(fn)
+		})
 
-	return l.errorf("unexpected type")
+	case isAlpha(r):
+		l.acceptFn(isAlphaNumeric)
+		typ := itemName
+		for _, t := range allTypes {
+			if l.input[l.start:l.pos] == keywords[t] {
+				typ = t
+			}
+		}
+		l.emit(typ)
+		return l.scanNonNull(fn)
+
+	default:
+		return l.errorf("expected type")
+	}
+}
+
+// scanNonNull consumes the optional ! that follows a type reference
+func (l *lexer) scanNonNull(fn stateFn) stateFn {
+	if r := l.peek(); r == bang {
+		l.next()
+		l.emit(itemBang)
+	}
+	return fn
 }
 
 func (l *lexer) scanNumber(fn stateFn) stateFn {
@@ -665,6 +883,15 @@ func (l *lexer) scanNumber(fn stateFn) stateFn {
 		return l.errorf("digits must be at least 0")
 	}
 
+	// optional exponent
+	if l.accept("eE") {
+		typ = itemFloatValue
+		l.accept("+-")
+		if l.acceptRun(digits) == 0 {
+			return l.errorf("exponent must contain at least one digit")
+		}
+	}
+
 	// Next thing mustn't be alphanumeric.
 	if r := l.peek(); isAlpha(r) {
 		return l.errorf("numbers may not immediately be followed by alphas")
@@ -226,6 +226,78 @@ func TestLexVariables(t *testing.T) {
 	})
 }
 
+func TestLexVariableTypes(t *testing.T) {
+	Convey("Verify #lex on variables with list, non-null and named types", t, func() {
+		l := lex("variable types", `
+			query sample($ids: [ID!]!, $name: String = "bob") {
+				user
+			}`)
+
+		wants := []item{
+			{typ: itemQuery},
+			{typ: itemName, val: "sample"},
+			{typ: itemLeftParen},
+			{typ: itemVariable, val: "ids"},
+			{typ: itemColon},
+			{typ: itemLeftSquare},
+			{typ: itemName, val: "ID"},
+			{typ: itemBang},
+			{typ: itemRightSquare},
+			{typ: itemBang},
+			{typ: itemVariable, val: "name"},
+			{typ: itemColon},
+			{typ: itemName, val: "String"},
+			{typ: itemEqual},
+			{typ: itemStringValue, val: "bob"},
+			{typ: itemRightParen},
+			{typ: itemLeftCurly},
+			{typ: itemName, val: "user"},
+			{typ: itemRightCurly},
+			{typ: itemEOF},
+		}
+
+		VerifyWants(l, wants)
+	})
+}
+
+func TestLexInputValues(t *testing.T) {
+	Convey("Verify #lex on list, object, null and enum values", t, func() {
+		l := lex("input values", `{
+			users(tags: ["a", 1e3], filter: {after: null, order: DESC}) { name }
+		}`)
+
+		wants := []item{
+			{typ: itemLeftCurly},
+			{typ: itemName, val: "users"},
+			{typ: itemLeftParen},
+			{typ: itemName, val: "tags"},
+			{typ: itemColon},
+			{typ: itemLeftSquare},
+			{typ: itemStringValue, val: "a"},
+			{typ: itemFloatValue, val: "1e3"},
+			{typ: itemRightSquare},
+			{typ: itemName, val: "filter"},
+			{typ: itemColon},
+			{typ: itemLeftCurly},
+			{typ: itemName, val: "after"},
+			{typ: itemColon},
+			{typ: itemNil},
+			{typ: itemName, val: "order"},
+			{typ: itemColon},
+			{typ: itemName, val: "DESC"},
+			{typ: itemRightCurly},
+			{typ: itemRightParen},
+			{typ: itemLeftCurly},
+			{typ: itemName, val: "name"},
+			{typ: itemRightCurly},
+			{typ: itemRightCurly},
+			{typ: itemEOF},
+		}
+
+		VerifyWants(l, wants)
+	})
+}
+
 func TestLexDirective(t *testing.T) {
 	Convey("Verify #lex on grammar with fragments and conditionals", t, func() {
 		l := lex("variables", `
@@ -600,3 +672,47 @@ func VerifyWants(l *lexer, wants []item) {
 		}
 	}
 }
+
+func TestLexDefinitions(t *testing.T) {
+	Convey("Verify #lexDefinitions on schema definition language", t, func() {
+		l := lexFrom("sdl", `"""doc"""
+		type Human implements A & B { friends(first: Int = 10): [Character!]! }
+		union U = | A | B`, lexDefinitions)
+
+		wants := []item{
+			{typ: itemBlockString, val: "doc"},
+			{typ: itemName, val: "type"},
+			{typ: itemName, val: "Human"},
+			{typ: itemName, val: "implements"},
+			{typ: itemName, val: "A"},
+			{typ: itemAmp},
+			{typ: itemName, val: "B"},
+			{typ: itemLeftCurly},
+			{typ: itemName, val: "friends"},
+			{typ: itemLeftParen},
+			{typ: itemName, val: "first"},
+			{typ: itemColon},
+			{typ: itemName, val: "Int"},
+			{typ: itemEqual},
+			{typ: itemIntValue, val: "10"},
+			{typ: itemRightParen},
+			{typ: itemColon},
+			{typ: itemLeftSquare},
+			{typ: itemName, val: "Character"},
+			{typ: itemBang},
+			{typ: itemRightSquare},
+			{typ: itemBang},
+			{typ: itemRightCurly},
+			{typ: itemName, val: "union"},
+			{typ: itemName, val: "U"},
+			{typ: itemEqual},
+			{typ: itemPipe},
+			{typ: itemName, val: "A"},
+			{typ: itemPipe},
+			{typ: itemName, val: "B"},
+			{typ: itemEOF},
+		}
+
+		VerifyWants(l, wants)
+	})
+}
@@ -126,6 +126,15 @@ func (l *lexer) lineNumber() int {
 	return 1 + strings.Count(l.input[:l.lastPos], "\n")
 }
 
+// locate returns the line and column of a position within the input
+func (l *lexer) locate(pos Pos) Loc {
+	before := l.input[:pos]
+	return Loc{
+		Line:   1 + strings.Count(before, "\n"),
+		Column: 1 + utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]),
+	}
+}
+
 // errorf returns an error token and terminates the scan by passing
 // back a nil pointer that will be the next state, terminating l.nextItem.
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
@@ -142,9 +151,15 @@ func (l *lexer) nextItem() item {
 
 // lex creates a new scanner for the input string.
 func lex(name, input string) *lexer {
+	return lexFrom(name, input, lexDocument)
+}
+
+// lexFrom creates a new scanner for the input string that begins in the given state
+func lexFrom(name, input string, state stateFn) *lexer {
 	l := &lexer{
 		name:  name,
 		input: input,
+		state: state,
 		items: make(chan item),
 	}
 	go l.run()
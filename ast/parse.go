@@ -1,15 +1,24 @@
 package ast
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 func Parse(q string) (*Document, error) {
 	l := lex("graph", q)
 	iter := newIterator(l)
+	defer iter.drain()
 
 	parse(iter)
 	if iter.err != nil {
 		return nil, iter.err
 	}
 
-	return &Document{Operations: iter.operations}, nil
+	return &Document{Operations: iter.operations, Fragments: iter.fragments}, nil
 }
 
 func parse(iter *iterator) {
@@ -21,23 +30,44 @@ func parse(iter *iterator) {
 
 func parseRoot(iter *iterator) parseFn {
 	item := iter.peek()
+	iter.start = iter.loc(item)
+
 	switch {
 	case item.typ == itemLeftCurly:
 		iter.next()
-		iter.addQuery("", "")
-		iter.addSelection()
+		iter.opType = OpQuery
+		op := iter.addOperation("")
+		op.SelectionSet = &Selection{}
+		iter.pushSelector(op.SelectionSet, &op.Loc)
 		return parseSelector
 
 	case item.typ == itemQuery:
 		iter.next()
-		return parseQuery
+		iter.opType = OpQuery
+		return parseOperation
+
+	case item.typ == itemMutation:
+		iter.next()
+		iter.opType = OpMutation
+		return parseOperation
+
+	case item.typ == itemFragment:
+		iter.next()
+		return parseFragment
+
+	case item.typ == itemEOF:
+		return nil
 
 	default:
-		return iter.errorf("unexpected element in root => %s", item.typ)
+		return iter.errorf(item, "unexpected element in root => %s", item)
 	}
 }
 
-func parseQuery(iter *iterator) parseFn {
+// parseOperation parses the remainder of a query or mutation once the keyword has been read.
+// A name following the keyword always names the operation.  Operations in the legacy form
+// are recognized by what follows the name, an alias or arguments, neither of which may
+// follow the name of a standard operation.
+func parseOperation(iter *iterator) parseFn {
 	item := iter.peek()
 	item1 := iter.peek1()
 	item2 := iter.peek2()
@@ -48,17 +78,54 @@ func parseQuery(iter *iterator) parseFn {
 		iter.next()          // colon
 		name := iter.next()  // name
 
-		iter.addQuery(alias.val, name.val)
+		iter.addOperation(alias.val)
+		iter.addRootField(alias.val, name.val, alias)
 		return parseField
 
-	case item.typ == itemName:
+	case item.typ == itemName && item1.typ == itemLeftParen && item2.typ != itemVariable && item2.typ != itemRightParen:
 		name := iter.next() // name
 
-		iter.addQuery("", name.val)
+		iter.addOperation(name.val)
+		iter.addRootField("", name.val, name)
 		return parseField
 
+	case item.typ == itemName:
+		iter.next() // name
+		iter.addOperation(item.val)
+		return parseOperationBody
+
 	default:
-		return iter.errorf("unexpected element after query => %s", item.typ)
+		iter.addOperation("")
+		return parseOperationBody
+	}
+}
+
+// parseOperationBody parses the variable definitions, directives and selection set of a
+// standard operation
+func parseOperationBody(iter *iterator) parseFn {
+	item := iter.peek()
+
+	switch {
+	case item.typ == itemLeftParen && len(iter.operation.VariableDefinitions) == 0:
+		iter.next()
+		return parseVariableDefinition
+
+	case item.typ == itemAtSign:
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return iter.fail(err)
+		}
+		iter.operation.Directives = append(iter.operation.Directives, directives...)
+		return parseOperationBody
+
+	case item.typ == itemLeftCurly:
+		iter.next()
+		iter.operation.SelectionSet = &Selection{}
+		iter.pushSelector(iter.operation.SelectionSet, &iter.operation.Loc)
+		return parseSelector
+
+	default:
+		return iter.errorf(item, "unexpected element in operation definition => %s", item)
 	}
 }
 
@@ -74,24 +141,61 @@ func parseSelector(iter *iterator) parseFn {
 		name := iter.next()  // name
 
 		iter.addAlias(alias.val, name.val)
+		iter.field.Loc = iter.span(alias)
 		return parseField
 
 	case item.typ == itemName:
 		iter.next()
 		iter.addField(item.val)
+		iter.field.Loc = iter.span(item)
 		return parseField
 
+	case item.typ == itemEllipses && item1.typ == itemName && item1.val == keywords[itemOn] && item2.typ == itemName:
+		iter.next()       // ellipses
+		iter.next()       // on
+		on := iter.next() // type condition
+
+		iter.addInlineFragment(on.val)
+		iter.inlineFragment.Loc = iter.loc(item)
+		return parseInlineFragment
+
+	case item.typ == itemEllipses && (item1.typ == itemLeftCurly || item1.typ == itemAtSign):
+		iter.next() // ellipses
+
+		iter.addInlineFragment("")
+		iter.inlineFragment.Loc = iter.loc(item)
+		return parseInlineFragment
+
+	case item.typ == itemEllipses && item1.typ == itemName:
+		iter.next()         // ellipses
+		name := iter.next() // fragment name
+
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return iter.fail(err)
+		}
+
+		spread := iter.addFragmentSpread(name.val)
+		spread.Directives = directives
+		spread.Loc = iter.span(item)
+		return parseSelector
+
 	case item.typ == itemRightCurly:
 		iter.next()
 		iter.popSelector()
-		return parseSelector
-
-	case item.typ == itemEOF:
-		return nil
+		return parseAfterSelector
 
 	default:
-		return iter.errorf("unexpected element after query => %s", item.typ)
+		return iter.errorf(item, "unexpected element after query => %s", item)
+	}
+}
+
+// parseAfterSelector decides where to go once a selection set has been closed
+func parseAfterSelector(iter *iterator) parseFn {
+	if iter.selection == nil {
+		return parseRoot
 	}
+	return parseSelector
 }
 
 func parseField(iter *iterator) parseFn {
@@ -99,70 +203,379 @@ func parseField(iter *iterator) parseFn {
 	item1 := iter.peek1()
 	item2 := iter.peek2()
 
+	// the field extends to whatever was read since it began e.g. its arguments and directives,
+	// as does the legacy operation it may be the root field of
+	iter.field.Loc.End = iter.prev.end
+	if iter.selection == nil {
+		iter.operation.Loc.End = iter.prev.end
+		iter.operation.SelectionSet.Loc.End = iter.prev.end
+	}
+
 	switch {
 	case item.typ == itemLeftParen:
 		iter.next()
 		return parseFieldArg
 
+	case item.typ == itemAtSign:
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return iter.fail(err)
+		}
+		iter.field.Directives = append(iter.field.Directives, directives...)
+		return parseField
+
 	case item.typ == itemLeftCurly:
 		iter.next()
-		s := iter.addSelection()
-		iter.pushSelector(s)
+		owners := []*Loc{&iter.field.Loc}
+		if iter.selection == nil {
+			owners = append(owners, &iter.operation.Loc, &iter.operation.SelectionSet.Loc)
+		}
+		iter.pushSelector(iter.addSelection(), owners...)
 		return parseSelector
 
-	case item.typ == itemRightCurly:
-		iter.next()
-		iter.popSelector()
+	case iter.selection == nil:
+		return parseRoot
+
+	case item.typ == itemRightCurly,
+		item.typ == itemEllipses,
+		item.typ == itemName && item1.typ == itemColon && item2.typ == itemName,
+		item.typ == itemName:
 		return parseSelector
 
-	case item.typ == itemName && item1.typ == itemColon && item2.typ == itemName:
-		alias := iter.next() // alias
-		iter.next()          // colon
-		name := iter.next()  // name
+	default:
+		if Debug {
+			iter.dumpTokens()
+		}
+		return iter.errorf(item, "unexpected element after name => %s", item)
+	}
+}
 
-		iter.addAlias(alias.val, name.val)
-		return parseField
+func parseFragment(iter *iterator) parseFn {
+	item := iter.peek()
+	item1 := iter.peek1()
+	item2 := iter.peek2()
 
-	case item.typ == itemName:
+	switch {
+	case item.typ == itemName && item1.typ == itemOn && item2.typ == itemName:
+		name := iter.next() // name
+		iter.next()         // on
+		on := iter.next()   // type condition
+
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return iter.fail(err)
+		}
+
+		if item := iter.next(); item.typ != itemLeftCurly {
+			return iter.errorf(item, "expected selection in fragment definition => %s", item)
+		}
+
+		fragment := iter.addFragment(name.val, on.val)
+		fragment.Directives = directives
+		iter.pushSelector(fragment.addSelection(), &fragment.Loc)
+		return parseSelector
+
+	default:
+		return iter.errorf(item, "unexpected element in fragment definition => %s", item)
+	}
+}
+
+func parseInlineFragment(iter *iterator) parseFn {
+	item := iter.peek()
+
+	switch {
+	case item.typ == itemAtSign:
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return iter.fail(err)
+		}
+		iter.inlineFragment.Directives = append(iter.inlineFragment.Directives, directives...)
+		return parseInlineFragment
+
+	case item.typ == itemLeftCurly:
 		iter.next()
-		iter.addField(item.val)
-		return parseField
+		iter.pushSelector(iter.inlineFragment.addSelection(), &iter.inlineFragment.Loc)
+		return parseSelector
 
 	default:
-		iter.dumpTokens()
-		return iter.errorf("unexpected element after name => %s", item.typ)
+		return iter.errorf(item, "expected selection after inline fragment => %s", item)
 	}
 }
 
-func parseFieldArg(iter *iterator) parseFn {
+func parseVariableDefinition(iter *iterator) parseFn {
 	item := iter.peek()
 	item1 := iter.peek1()
-	item2 := iter.peek2()
 
 	switch {
-	case item.typ == itemName && item1.typ == itemColon && isValue(item2):
-		name := iter.next()  // name
-		iter.next()          // colon
-		value := iter.next() // value
+	case item.typ == itemVariable && item1.typ == itemColon:
+		name := iter.next() // variable
+		iter.next()         // colon
+
+		typ, err := iter.parseType()
+		if err != nil {
+			return iter.fail(annotate(err, "invalid type for variable $%v", name.val))
+		}
+		v := iter.addVariable(name.val, typ)
+
+		if iter.peek().typ == itemEqual {
+			iter.next() // equal
+			value, err := iter.parseValue(true)
+			if err != nil {
+				return iter.fail(annotate(err, "invalid default value for variable $%v", name.val))
+			}
+			v.DefaultValue = value
+		}
+		v.Loc = iter.span(name)
+		return parseVariableDefinition
 
-		iter.addFieldArg(name.val, value.val)
-		return parseFieldArg
+	case item.typ == itemRightParen:
+		iter.next()
+		return parseOperationBody
 
-	case isValue(item):
-		value := iter.next() // value
+	default:
+		return iter.errorf(item, "unexpected variable definition element => %s", item)
+	}
+}
 
-		iter.addFieldArg("", value.val)
-		return parseFieldArg
+// parseType consumes a type reference e.g. Int, [String!]!
+func (iter *iterator) parseType() (*Type, error) {
+	typ := &Type{}
 
-	case item.typ == itemRightParen:
+	item := iter.next()
+	switch {
+	case item.typ == itemName, isTypeKeyword(item.typ):
+		typ.Name = item.val
+
+	case item.typ == itemLeftSquare:
+		elem, err := iter.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if item := iter.next(); item.typ != itemRightSquare {
+			return nil, iter.syntaxError(item, "expected ] to close list type, got %s", item)
+		}
+		typ.Elem = elem
+
+	default:
+		return nil, iter.syntaxError(item, "unexpected element in type => %s", item)
+	}
+
+	if iter.peek().typ == itemBang {
 		iter.next()
-		return parseField
+		typ.NonNull = true
+	}
+	typ.Loc = iter.span(item)
+
+	return typ, nil
+}
+
+func isTypeKeyword(typ itemType) bool {
+	for _, t := range allTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFieldArg(iter *iterator) parseFn {
+	args, err := iter.parseArgs()
+	if err != nil {
+		return iter.fail(err)
+	}
+
+	iter.addFieldArgs(args)
+	return parseField
+}
+
+// parseArgs consumes arguments up to and including the closing paren; the opening paren
+// must already have been read
+func (iter *iterator) parseArgs() ([]*Arg, error) {
+	args := []*Arg{}
+	for {
+		item := iter.peek()
+		item1 := iter.peek1()
+
+		switch {
+		case item.typ == itemName && item1.typ == itemColon:
+			name := iter.next() // name
+			iter.next()         // colon
+
+			value, err := iter.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, &Arg{Name: name.val, Value: value, Loc: iter.span(name)})
+
+		case isValueStart(item):
+			value, err := iter.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, &Arg{Value: value, Loc: value.Location()})
+
+		case item.typ == itemRightParen:
+			iter.next()
+			return args, nil
+
+		default:
+			return nil, iter.syntaxError(item, "unexpected argument element => %s", item)
+		}
+	}
+}
+
+// parseValue consumes an input value; constant values may not reference variables
+func (iter *iterator) parseValue(constant bool) (Value, error) {
+	item := iter.next()
+	switch item.typ {
+	case itemIntValue:
+		return &IntValue{Value: item.val, Loc: iter.loc(item)}, nil
+
+	case itemFloatValue:
+		return &FloatValue{Value: item.val, Loc: iter.loc(item)}, nil
+
+	case itemStringValue:
+		s, err := unescape(item.val)
+		if err != nil {
+			return nil, iter.syntaxError(item, "%v", err)
+		}
+		return &StringValue{Value: s, Loc: iter.loc(item)}, nil
+
+	case itemTrue, itemFalse:
+		return &BooleanValue{Value: item.typ == itemTrue, Loc: iter.loc(item)}, nil
+
+	case itemNil:
+		return &NullValue{Loc: iter.loc(item)}, nil
+
+	case itemName:
+		return &EnumValue{Value: item.val, Loc: iter.loc(item)}, nil
+
+	case itemVariable:
+		if constant {
+			return nil, iter.syntaxError(item, "variable $%v may not be used in a constant value", item.val)
+		}
+		return &Variable{Name: item.val, Loc: iter.loc(item)}, nil
+
+	case itemLeftSquare:
+		list := &ListValue{Values: []Value{}}
+		for iter.peek().typ != itemRightSquare {
+			value, err := iter.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list.Values = append(list.Values, value)
+		}
+		iter.next() // right square
+		list.Loc = iter.span(item)
+		return list, nil
+
+	case itemLeftCurly:
+		object := &ObjectValue{Fields: []*ObjectField{}}
+		for iter.peek().typ != itemRightCurly {
+			name := iter.next()
+			if name.typ != itemName {
+				return nil, iter.syntaxError(name, "expected object field name => %s", name)
+			}
+			if item := iter.next(); item.typ != itemColon {
+				return nil, iter.syntaxError(item, "expected colon after object field name => %s", item)
+			}
+			value, err := iter.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			object.Fields = append(object.Fields, &ObjectField{Name: name.val, Value: value, Loc: iter.span(name)})
+		}
+		iter.next() // right curly
+		object.Loc = iter.span(item)
+		return object, nil
 
 	default:
-		return iter.errorf("unexpected field argument element => %s", item.typ)
+		return nil, iter.syntaxError(item, "unexpected value => %s", item)
 	}
 }
 
-func isValue(item item) bool {
-	return item.typ == itemIntValue || item.typ == itemStringValue
+// unescape decodes the escape sequences within a string literal
+func unescape(s string) (string, error) {
+	if !strings.ContainsRune(s, escape) {
+		return s, nil
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(s)))
+	for i := 0; i < len(s); i++ {
+		if s[i] != escape {
+			buf.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if i >= len(s) {
+			return "", errors.New("invalid escape sequence")
+		}
+		switch s[i] {
+		case '"', '\\', '/':
+			buf.WriteByte(s[i])
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errors.New("invalid unicode escape sequence")
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", errors.New("invalid unicode escape sequence")
+			}
+			buf.WriteRune(rune(r))
+			i += 4
+		default:
+			return "", fmt.Errorf("invalid escape sequence, \\%c", s[i])
+		}
+	}
+	return buf.String(), nil
+}
+
+// parseDirectives consumes any directives that follow e.g. @include(if: $withFriends)
+func (iter *iterator) parseDirectives() ([]*Directive, error) {
+	var directives []*Directive
+	for iter.peek().typ == itemAtSign {
+		at := iter.next() // at sign
+
+		name := iter.next()
+		if name.typ != itemName {
+			return nil, iter.syntaxError(name, "expected directive name => %s", name)
+		}
+		directive := &Directive{Name: name.val}
+
+		if iter.peek().typ == itemLeftParen {
+			iter.next() // left paren
+
+			args, err := iter.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			directive.Args = args
+		}
+		directive.Loc = iter.span(at)
+
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// isValueStart reports whether item may begin an input value
+func isValueStart(item item) bool {
+	switch item.typ {
+	case itemIntValue, itemFloatValue, itemStringValue, itemTrue, itemFalse, itemNil,
+		itemVariable, itemLeftSquare, itemLeftCurly:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,516 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSchema reads schema definition language, such as the contents of a .graphql file,
+// into a SchemaDocument
+func ParseSchema(s string) (*SchemaDocument, error) {
+	l := lexFrom("schema", s, lexDefinitions)
+	iter := newIterator(l)
+	defer iter.drain()
+
+	doc := &SchemaDocument{}
+	for iter.peek().typ != itemEOF {
+		definition, err := iter.parseDefinition()
+		if err != nil {
+			return nil, err
+		}
+		doc.Definitions = append(doc.Definitions, definition)
+	}
+
+	return doc, nil
+}
+
+// parseDefinition consumes a single top level definition along with its description
+func (iter *iterator) parseDefinition() (Definition, error) {
+	start := iter.peek()
+	description, err := iter.parseDescription()
+	if err != nil {
+		return nil, err
+	}
+
+	keyword, err := iter.expect(itemName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyword.val {
+	case "extend":
+		if description != "" {
+			return nil, iter.syntaxError(start, "extensions may not have a description")
+		}
+		extended := iter.peek()
+		definition, err := iter.parseDefinition()
+		if err != nil {
+			return nil, err
+		}
+		switch definition.(type) {
+		case *DirectiveDefinition, *Extension:
+			return nil, iter.syntaxError(extended, "only schemas and types may be extended")
+		}
+		return &Extension{Definition: definition, Loc: iter.span(start)}, nil
+
+	case "schema":
+		return iter.parseSchemaDefinition(start, description)
+
+	case "scalar":
+		name, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		return &ScalarDefinition{Description: description, Name: name, Directives: directives, Loc: iter.span(start)}, nil
+
+	case "type":
+		name, interfaces, directives, fields, err := iter.parseObjectDefinition()
+		if err != nil {
+			return nil, err
+		}
+		return &ObjectDefinition{
+			Description: description,
+			Name:        name,
+			Interfaces:  interfaces,
+			Directives:  directives,
+			Fields:      fields,
+			Loc:         iter.span(start),
+		}, nil
+
+	case "interface":
+		name, interfaces, directives, fields, err := iter.parseObjectDefinition()
+		if err != nil {
+			return nil, err
+		}
+		return &InterfaceDefinition{
+			Description: description,
+			Name:        name,
+			Interfaces:  interfaces,
+			Directives:  directives,
+			Fields:      fields,
+			Loc:         iter.span(start),
+		}, nil
+
+	case "union":
+		return iter.parseUnionDefinition(start, description)
+
+	case "enum":
+		return iter.parseEnumDefinition(start, description)
+
+	case "input":
+		name, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		var fields []*InputValueDefinition
+		if iter.peek().typ == itemLeftCurly {
+			iter.next() // left curly
+			if fields, err = iter.parseInputValueDefinitions(itemRightCurly); err != nil {
+				return nil, err
+			}
+		}
+		return &InputObjectDefinition{Description: description, Name: name, Directives: directives, Fields: fields, Loc: iter.span(start)}, nil
+
+	case "directive":
+		return iter.parseDirectiveDefinition(start, description)
+
+	default:
+		return nil, iter.syntaxError(keyword, "unexpected definition => %v", keyword.val)
+	}
+}
+
+// parseSchemaDefinition consumes the directives and root operation types following the
+// schema keyword
+func (iter *iterator) parseSchemaDefinition(start item, description string) (*SchemaDefinition, error) {
+	directives, err := iter.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+	schema := &SchemaDefinition{Description: description, Directives: directives}
+
+	if iter.peek().typ != itemLeftCurly {
+		schema.Loc = iter.span(start)
+		return schema, nil
+	}
+	iter.next() // left curly
+
+	for iter.peek().typ != itemRightCurly {
+		item := iter.peek()
+		operation, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := iter.expect(itemColon); err != nil {
+			return nil, err
+		}
+		name, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+
+		switch operation {
+		case "query":
+			schema.Query = name
+		case "mutation":
+			schema.Mutation = name
+		case "subscription":
+			schema.Subscription = name
+		default:
+			return nil, iter.syntaxError(item, "unknown operation type => %v", operation)
+		}
+	}
+	iter.next() // right curly
+	schema.Loc = iter.span(start)
+
+	return schema, nil
+}
+
+// parseObjectDefinition consumes the elements shared by object and interface definitions
+func (iter *iterator) parseObjectDefinition() (string, []string, []*Directive, []*FieldDefinition, error) {
+	name, err := iter.parseName()
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
+	var interfaces []string
+	if item := iter.peek(); item.typ == itemName && item.val == "implements" {
+		iter.next() // implements
+		if iter.peek().typ == itemAmp {
+			iter.next()
+		}
+		for {
+			name, err := iter.parseName()
+			if err != nil {
+				return "", nil, nil, nil, err
+			}
+			interfaces = append(interfaces, name)
+
+			if iter.peek().typ != itemAmp {
+				break
+			}
+			iter.next() // amp
+		}
+	}
+
+	directives, err := iter.parseDirectives()
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
+	var fields []*FieldDefinition
+	if iter.peek().typ == itemLeftCurly {
+		iter.next() // left curly
+		for iter.peek().typ != itemRightCurly {
+			field, err := iter.parseFieldDefinition()
+			if err != nil {
+				return "", nil, nil, nil, err
+			}
+			fields = append(fields, field)
+		}
+		iter.next() // right curly
+	}
+
+	return name, interfaces, directives, fields, nil
+}
+
+// parseFieldDefinition consumes a field of an object or interface e.g. user(id: ID!): User
+func (iter *iterator) parseFieldDefinition() (*FieldDefinition, error) {
+	start := iter.peek()
+	description, err := iter.parseDescription()
+	if err != nil {
+		return nil, err
+	}
+	name, err := iter.parseName()
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldDefinition{Description: description, Name: name}
+
+	if iter.peek().typ == itemLeftParen {
+		iter.next() // left paren
+		if field.Args, err = iter.parseInputValueDefinitions(itemRightParen); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := iter.expect(itemColon); err != nil {
+		return nil, err
+	}
+	if field.Type, err = iter.parseType(); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = iter.parseDirectives(); err != nil {
+		return nil, err
+	}
+	field.Loc = iter.span(start)
+
+	return field, nil
+}
+
+// parseInputValueDefinitions consumes arguments or input fields up to and including the
+// closing token; the opening token must already have been read
+func (iter *iterator) parseInputValueDefinitions(closing itemType) ([]*InputValueDefinition, error) {
+	var values []*InputValueDefinition
+	for iter.peek().typ != closing {
+		start := iter.peek()
+		description, err := iter.parseDescription()
+		if err != nil {
+			return nil, err
+		}
+		name, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := iter.expect(itemColon); err != nil {
+			return nil, err
+		}
+		value := &InputValueDefinition{Description: description, Name: name}
+
+		if value.Type, err = iter.parseType(); err != nil {
+			return nil, err
+		}
+		if iter.peek().typ == itemEqual {
+			iter.next() // equal
+			if value.DefaultValue, err = iter.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		if value.Directives, err = iter.parseDirectives(); err != nil {
+			return nil, err
+		}
+		value.Loc = iter.span(start)
+
+		values = append(values, value)
+	}
+	iter.next() // closing
+
+	return values, nil
+}
+
+// parseUnionDefinition consumes a union e.g. union SearchResult = Human | Droid
+func (iter *iterator) parseUnionDefinition(start item, description string) (*UnionDefinition, error) {
+	name, err := iter.parseName()
+	if err != nil {
+		return nil, err
+	}
+	directives, err := iter.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+	union := &UnionDefinition{Description: description, Name: name, Directives: directives}
+
+	if iter.peek().typ != itemEqual {
+		union.Loc = iter.span(start)
+		return union, nil
+	}
+	iter.next() // equal
+
+	union.Types, err = iter.parseNames(itemPipe)
+	if err != nil {
+		return nil, err
+	}
+	union.Loc = iter.span(start)
+	return union, nil
+}
+
+// parseEnumDefinition consumes an enum e.g. enum Episode { NEWHOPE EMPIRE JEDI }
+func (iter *iterator) parseEnumDefinition(start item, description string) (*EnumDefinition, error) {
+	name, err := iter.parseName()
+	if err != nil {
+		return nil, err
+	}
+	directives, err := iter.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+	enum := &EnumDefinition{Description: description, Name: name, Directives: directives}
+
+	if iter.peek().typ != itemLeftCurly {
+		enum.Loc = iter.span(start)
+		return enum, nil
+	}
+	iter.next() // left curly
+
+	for iter.peek().typ != itemRightCurly {
+		value := iter.peek()
+		description, err := iter.parseDescription()
+		if err != nil {
+			return nil, err
+		}
+		name, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+		directives, err := iter.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		enum.Values = append(enum.Values, &EnumValueDefinition{
+			Description: description,
+			Name:        name,
+			Directives:  directives,
+			Loc:         iter.span(value),
+		})
+	}
+	iter.next() // right curly
+	enum.Loc = iter.span(start)
+
+	return enum, nil
+}
+
+// parseDirectiveDefinition consumes a directive e.g. directive @skip(if: Boolean!) on FIELD
+func (iter *iterator) parseDirectiveDefinition(start item, description string) (*DirectiveDefinition, error) {
+	if _, err := iter.expect(itemAtSign); err != nil {
+		return nil, err
+	}
+	name, err := iter.parseName()
+	if err != nil {
+		return nil, err
+	}
+	directive := &DirectiveDefinition{Description: description, Name: name}
+
+	if iter.peek().typ == itemLeftParen {
+		iter.next() // left paren
+		if directive.Args, err = iter.parseInputValueDefinitions(itemRightParen); err != nil {
+			return nil, err
+		}
+	}
+
+	if item := iter.peek(); item.typ == itemName && item.val == "repeatable" {
+		iter.next()
+		directive.Repeatable = true
+	}
+
+	if item := iter.next(); item.typ != itemName || item.val != "on" {
+		return nil, iter.syntaxError(item, "expected on keyword => %s", item)
+	}
+
+	directive.Locations, err = iter.parseNames(itemPipe)
+	if err != nil {
+		return nil, err
+	}
+	directive.Loc = iter.span(start)
+	return directive, nil
+}
+
+// parseNames consumes a list of names separated by sep, which may also lead the list
+func (iter *iterator) parseNames(sep itemType) ([]string, error) {
+	if iter.peek().typ == sep {
+		iter.next()
+	}
+
+	var names []string
+	for {
+		name, err := iter.parseName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+
+		if iter.peek().typ != sep {
+			return names, nil
+		}
+		iter.next() // sep
+	}
+}
+
+// parseDescription consumes the optional string that documents a definition
+func (iter *iterator) parseDescription() (string, error) {
+	switch item := iter.peek(); item.typ {
+	case itemStringValue:
+		iter.next()
+		s, err := unescape(item.val)
+		if err != nil {
+			return "", iter.syntaxError(item, "%v", err)
+		}
+		return s, nil
+
+	case itemBlockString:
+		iter.next()
+		return blockString(item.val), nil
+
+	default:
+		return "", nil
+	}
+}
+
+// describe returns how an item type is written for use in error messages
+func describe(typ itemType) string {
+	for r, t := range punctuation {
+		if t == typ {
+			return fmt.Sprintf("%q", r)
+		}
+	}
+
+	switch typ {
+	case itemName:
+		return "name"
+	case itemEOF:
+		return "end of input"
+	default:
+		return typ.String()
+	}
+}
+
+func (iter *iterator) parseName() (string, error) {
+	item, err := iter.expect(itemName)
+	if err != nil {
+		return "", err
+	}
+	return item.val, nil
+}
+
+// expect consumes the next item, returning an error unless it has the expected type
+func (iter *iterator) expect(typ itemType) (item, error) {
+	item := iter.next()
+	if item.typ != typ {
+		return item, iter.syntaxError(item, "expected %s => %s", describe(typ), item)
+	}
+	return item, nil
+}
+
+// blockString returns the value of a block string; the common indentation and any blank
+// leading and trailing lines are removed
+func blockString(raw string) string {
+	lines := strings.Split(strings.Replace(raw, `\"""`, `"""`, -1), "\n")
+	for index, line := range lines {
+		lines[index] = strings.TrimSuffix(line, "\r")
+	}
+
+	common := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); common == -1 || n < common {
+			common = n
+		}
+	}
+	if common > 0 {
+		for index := 1; index < len(lines); index++ {
+			if len(lines[index]) >= common {
+				lines[index] = lines[index][common:]
+			} else {
+				lines[index] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
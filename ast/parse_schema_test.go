@@ -0,0 +1,224 @@
+package ast
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const starWars = `# the schema shared with the frontend
+schema {
+  query: Query
+  mutation: Mutation
+}
+
+"""
+A character from the
+  Star Wars universe
+"""
+interface Character {
+  id: ID!
+  name: String
+  friends: [Character]
+}
+
+type Human implements Character & Node @key(fields: "id") {
+  id: ID!
+  name: String
+  friends: [Character]
+  "height in the given unit"
+  height(unit: LengthUnit = METER): Float
+}
+
+union SearchResult = Human | Droid
+
+enum LengthUnit {
+  METER
+  FOOT @deprecated(reason: "use METER")
+}
+
+input ReviewInput {
+  stars: Int!
+  tags: [String!] = ["new"]
+}
+
+scalar Time
+
+directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+
+extend type Query {
+  hero(episode: Episode): Character
+}
+`
+
+func TestParseSchema(t *testing.T) {
+	Convey("Given a schema written in SDL", t, func() {
+		doc, err := ParseSchema(starWars)
+		So(err, ShouldBeNil)
+		So(doc.Definitions, ShouldHaveLength, 9)
+
+		Convey("Then the schema definition names the root types", func() {
+			So(clearLocs(doc.Schema()), ShouldResemble, &SchemaDefinition{Query: "Query", Mutation: "Mutation"})
+		})
+
+		Convey("Then block string descriptions are dedented", func() {
+			character := doc.Type("Character").(*InterfaceDefinition)
+			So(character.Description, ShouldEqual, "A character from the\n  Star Wars universe")
+			So(character.Field("friends").Type.String(), ShouldEqual, "[Character]")
+		})
+
+		Convey("Then objects record interfaces, directives, arguments and defaults", func() {
+			human := doc.Type("Human").(*ObjectDefinition)
+			So(human.Interfaces, ShouldResemble, []string{"Character", "Node"})
+			So(human.Directives[0].Name, ShouldEqual, "key")
+			So(clearLocs(human.Directives[0].Arg("fields").Value), ShouldResemble, &StringValue{Value: "id"})
+
+			height := human.Field("height")
+			So(height.Description, ShouldEqual, "height in the given unit")
+			So(height.Arg("unit").Type.String(), ShouldEqual, "LengthUnit")
+			So(clearLocs(height.Arg("unit").DefaultValue), ShouldResemble, &EnumValue{Value: "METER"})
+		})
+
+		Convey("Then unions, enums, inputs and scalars are read", func() {
+			So(doc.Type("SearchResult").(*UnionDefinition).Types, ShouldResemble, []string{"Human", "Droid"})
+
+			unit := doc.Type("LengthUnit").(*EnumDefinition)
+			So(unit.Values, ShouldHaveLength, 2)
+			So(unit.Values[1].Directives[0].Name, ShouldEqual, "deprecated")
+
+			review := doc.Type("ReviewInput").(*InputObjectDefinition)
+			So(review.Fields[0].Type.String(), ShouldEqual, "Int!")
+			So(review.Fields[1].DefaultValue.String(), ShouldEqual, `["new"]`)
+
+			So(clearLocs(doc.Type("Time")), ShouldResemble, &ScalarDefinition{Name: "Time"})
+		})
+
+		Convey("Then definitions record where they begin, including their description", func() {
+			So(doc.Schema().Loc.Line, ShouldEqual, 2)
+			So(doc.Type("Character").(*InterfaceDefinition).Loc.Line, ShouldEqual, 7)
+
+			human := doc.Type("Human").(*ObjectDefinition)
+			So(human.Loc.Line, ShouldEqual, 17)
+			height := human.Field("height").Loc
+			So(height.Line, ShouldEqual, 21)
+			So(height.Column, ShouldEqual, 3)
+			So(starWars[height.Start:height.End], ShouldEqual, `"height in the given unit"
+  height(unit: LengthUnit = METER): Float`)
+			So(doc.Type("LengthUnit").(*EnumDefinition).Values[1].Loc.Line, ShouldEqual, 29)
+		})
+
+		Convey("Then directive definitions record their locations", func() {
+			key := doc.Directive("key")
+			So(key.Repeatable, ShouldBeTrue)
+			So(key.Locations, ShouldResemble, []string{"OBJECT", "INTERFACE"})
+		})
+
+		Convey("Then extensions wrap the definition being extended", func() {
+			extension := doc.Definitions[8].(*Extension)
+			So(extension.Definition.(*ObjectDefinition).Name, ShouldEqual, "Query")
+			So(doc.Type("Query"), ShouldBeNil)
+		})
+	})
+
+	Convey("Given invalid SDL", t, func() {
+		for _, s := range []string{
+			`type Query { hero Character }`,
+			`type Query { hero: }`,
+			`enum Episode { NEWHOPE`,
+			`directive @key(fields: String!) OBJECT`,
+			`widget Query`,
+			`type Query { name: String } %`,
+			`"""unterminated`,
+		} {
+			_, err := ParseSchema(s)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestPrintSchema(t *testing.T) {
+	Convey("Given a parsed schema", t, func() {
+		doc, err := ParseSchema(starWars)
+		So(err, ShouldBeNil)
+
+		Convey("Then String prints canonical SDL", func() {
+			So(doc.String(), ShouldEqual, `schema {
+  query: Query
+  mutation: Mutation
+}
+
+"""
+A character from the
+  Star Wars universe
+"""
+interface Character {
+  id: ID!
+  name: String
+  friends: [Character]
+}
+
+type Human implements Character & Node @key(fields: "id") {
+  id: ID!
+  name: String
+  friends: [Character]
+  """height in the given unit"""
+  height(unit: LengthUnit = METER): Float
+}
+
+union SearchResult = Human | Droid
+
+enum LengthUnit {
+  METER
+  FOOT @deprecated(reason: "use METER")
+}
+
+input ReviewInput {
+  stars: Int!
+  tags: [String!] = ["new"]
+}
+
+scalar Time
+
+directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+
+extend type Query {
+  hero(episode: Episode): Character
+}
+`)
+		})
+
+		Convey("Then the printed SDL parses to the same document", func() {
+			reparsed, err := ParseSchema(doc.String())
+			So(err, ShouldBeNil)
+			So(clearLocs(reparsed), ShouldResemble, clearLocs(doc))
+		})
+	})
+
+	Convey("Given arguments with descriptions", t, func() {
+		doc, err := ParseSchema(`type Query { user("the id" id: ID!, name: String): User }`)
+		So(err, ShouldBeNil)
+
+		Convey("Then each argument is printed on its own line", func() {
+			So(doc.String(), ShouldEqual, `type Query {
+  user(
+    """the id"""
+    id: ID!
+    name: String
+  ): User
+}
+`)
+		})
+	})
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	Convey("Given SDL with a missing colon", t, func() {
+		_, err := ParseSchema(`type Query { hero Character }`)
+
+		Convey("Then the error describes the tokens involved and where they were found", func() {
+			So(err, ShouldHaveSameTypeAs, &SyntaxError{})
+			So(err.(*SyntaxError).Message, ShouldEqual, `expected ':' => "Character"`)
+			So(err.(*SyntaxError).Loc, ShouldResemble, Loc{Start: 18, End: 18, Line: 1, Column: 19})
+		})
+	})
+}
@@ -1,11 +1,42 @@
 package ast
 
 import (
+	"reflect"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// clearLocs zeroes the location of each node reachable from v so that tests concerned with
+// structure alone may compare nodes with ShouldResemble; v is returned for convenience
+func clearLocs(v interface{}) interface{} {
+	var clear func(value reflect.Value)
+	clear = func(value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if !value.IsNil() {
+				clear(value.Elem())
+			}
+		case reflect.Slice:
+			for i := 0; i < value.Len(); i++ {
+				clear(value.Index(i))
+			}
+		case reflect.Struct:
+			if value.Type() == reflect.TypeOf(Loc{}) {
+				if value.CanSet() {
+					value.Set(reflect.ValueOf(Loc{}))
+				}
+				return
+			}
+			for i := 0; i < value.NumField(); i++ {
+				clear(value.Field(i))
+			}
+		}
+	}
+	clear(reflect.ValueOf(v))
+	return v
+}
+
 func TestParseSimple(t *testing.T) {
 	Convey("Given a simple graphql statement", t, func() {
 		q := `query user(id:123) { close_friends: friends(max: 5, distance: 1) { picture } }`
@@ -24,6 +55,73 @@ func TestParseSimple2(t *testing.T) {
 	})
 }
 
+func TestParseOperationNames(t *testing.T) {
+	Convey("Verify #parse reads several named operations", t, func() {
+		q := `query A { a } mutation B @log { b } query c: GET(url: "/c") { c }`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+		So(doc.Operations, ShouldHaveLength, 3)
+
+		Convey("Then the name of a standard operation is separate from its selection", func() {
+			a := doc.Operation("A")
+			So(a.Type, ShouldEqual, OpQuery)
+			So(a.SelectionSet.Selectors, ShouldHaveLength, 1)
+			So(a.SelectionSet.Selectors[0].(*Field).Name, ShouldEqual, "a")
+			So(doc.Operation("B").Type, ShouldEqual, OpMutation)
+			So(doc.Operation("B").Directives[0].Name, ShouldEqual, "log")
+		})
+
+		Convey("Then a legacy operation is named by the response key of its root field", func() {
+			c := doc.Operation("c")
+			So(c.SelectionSet.Selectors, ShouldHaveLength, 1)
+
+			get := c.SelectionSet.Selectors[0].(*Field)
+			So(get.Alias, ShouldEqual, "c")
+			So(get.Name, ShouldEqual, "GET")
+			So(get.Args[0].Name, ShouldEqual, "url")
+			So(get.Selection.Selectors[0].(*Field).Name, ShouldEqual, "c")
+			So(c.SelectionSet.Loc, ShouldResemble, get.Loc)
+		})
+
+		Convey("Then operations that aren't defined aren't found", func() {
+			So(doc.Operation("GET"), ShouldBeNil)
+			So(doc.Operation(""), ShouldBeNil)
+		})
+	})
+}
+
+func TestParseOperationDefinitions(t *testing.T) {
+	Convey("Verify #parse on operations with variables and directives but no name", t, func() {
+		doc, err := Parse(`query ($id: ID) @cached(ttl: 5) { user(id: $id) { name } }`)
+		So(err, ShouldBeNil)
+
+		op := doc.Operations[0]
+		So(op.Name, ShouldEqual, "")
+		So(op.VariableDefinitions[0].Name, ShouldEqual, "id")
+		So(op.Directives[0].Name, ShouldEqual, "cached")
+		So(op.SelectionSet.Selectors[0].(*Field).Name, ShouldEqual, "user")
+	})
+
+	Convey("Verify #parse on a named operation whose root field shares its name", t, func() {
+		doc, err := Parse(`query user { user { name } }`)
+		So(err, ShouldBeNil)
+
+		op := doc.Operation("user")
+		So(op.SelectionSet.Selectors, ShouldHaveLength, 1)
+		So(op.SelectionSet.Selectors[0].(*Field).Selection.Selectors[0].(*Field).Name, ShouldEqual, "name")
+	})
+
+	Convey("Verify #parse on a legacy operation whose root field takes arguments", t, func() {
+		doc, err := Parse(`mutation like(id: 1) { likes }`)
+		So(err, ShouldBeNil)
+
+		op := doc.Operation("like")
+		So(op.Type, ShouldEqual, OpMutation)
+		So(op.SelectionSet.Selectors[0].(*Field).Args[0].Name, ShouldEqual, "id")
+		So(op.Loc, ShouldResemble, Loc{Start: 0, End: 30, Line: 1, Column: 1})
+	})
+}
+
 func TestParseString(t *testing.T) {
 	Convey("Verify #parse on nested grammar with string", t, func() {
 		q := `query city: GET(url:"http://api.openweathermap.org/data/2.5/weather?lat=35&lon=139") {
@@ -46,3 +144,266 @@ func TestParseHello(t *testing.T) {
 		So(doc, ShouldNotBeNil)
 	})
 }
+
+func TestParseNested(t *testing.T) {
+	Convey("Verify #parse returns to the enclosing selection after a nested selection", t, func() {
+		q := `{ a { b } c }`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		selectors := doc.Operations[0].SelectionSet.Selectors
+		So(len(selectors), ShouldEqual, 2)
+		So(selectors[1].(*Field).Name, ShouldEqual, "c")
+	})
+}
+
+func TestParseFragment(t *testing.T) {
+	Convey("Verify #parse on named fragments and fragment spreads", t, func() {
+		q := `query withFragments {
+			user(id: 4) {
+				friends(first: 10) {
+					...friendFields
+				}
+			}
+		}
+
+		fragment friendFields on User {
+			id
+			name
+			...standardProfilePic
+		}
+
+		fragment standardProfilePic on User {
+			profilePic(size: 50)
+		}`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+		So(len(doc.Operations), ShouldEqual, 1)
+		So(len(doc.Fragments), ShouldEqual, 2)
+
+		fragment := doc.Fragment("friendFields")
+		So(fragment, ShouldNotBeNil)
+		So(fragment.On, ShouldEqual, "User")
+		So(len(fragment.Selection.Selectors), ShouldEqual, 3)
+		So(fragment.Selection.Selectors[2].(*FragmentSpread).Loc.Line, ShouldEqual, 12)
+		So(clearLocs(fragment.Selection.Selectors[2]), ShouldResemble, &FragmentSpread{Name: "standardProfilePic"})
+
+		So(doc.Fragment("standardProfilePic"), ShouldNotBeNil)
+		So(doc.Fragment("unknown"), ShouldBeNil)
+	})
+}
+
+func TestParseInlineFragment(t *testing.T) {
+	Convey("Verify #parse on inline fragments", t, func() {
+		q := `{
+			hero {
+				name
+				... on Droid {
+					primaryFunction
+				}
+				... {
+					id
+				}
+			}
+		}`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		hero := doc.Operations[0].SelectionSet.Selectors[0].(*Field)
+		So(len(hero.Selection.Selectors), ShouldEqual, 3)
+
+		droid := hero.Selection.Selectors[1].(*InlineFragment)
+		So(droid.On, ShouldEqual, "Droid")
+		So(droid.Selection.Selectors[0].(*Field).Name, ShouldEqual, "primaryFunction")
+
+		untyped := hero.Selection.Selectors[2].(*InlineFragment)
+		So(untyped.On, ShouldEqual, "")
+		So(untyped.Selection.Selectors[0].(*Field).Name, ShouldEqual, "id")
+	})
+}
+
+func TestParseVariables(t *testing.T) {
+	Convey("Verify #parse on variable definitions", t, func() {
+		q := `query user($id: Int = 1, $tags: [String!]!) { user(id: $id, tags: $tags) { name } }`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		op := doc.Operations[0]
+		So(op.Name, ShouldEqual, "user")
+		So(len(op.VariableDefinitions), ShouldEqual, 2)
+
+		id := op.Variable("id")
+		So(id.Type.String(), ShouldEqual, "Int")
+		So(clearLocs(id.DefaultValue), ShouldResemble, &IntValue{Value: "1"})
+
+		tags := op.Variable("tags")
+		So(tags.Type.String(), ShouldEqual, "[String!]!")
+		So(tags.DefaultValue, ShouldBeNil)
+
+		user := op.SelectionSet.Selectors[0].(*Field)
+		So(clearLocs(user.Args), ShouldResemble, []*Arg{
+			{Name: "id", Value: &Variable{Name: "id"}},
+			{Name: "tags", Value: &Variable{Name: "tags"}},
+		})
+	})
+}
+
+func TestParseMutation(t *testing.T) {
+	Convey("Verify #parse on mutations", t, func() {
+		q := `mutation { like(id: 4) { likeCount } }`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+		So(doc.Operations[0].Type, ShouldEqual, OpMutation)
+		So(doc.Operations[0].SelectionSet.Selectors[0].(*Field).Name, ShouldEqual, "like")
+	})
+}
+
+func TestParseDirectives(t *testing.T) {
+	Convey("Verify #parse on directives", t, func() {
+		q := `query hasConditionalFragment($condition: Boolean) {
+			me @include(if: $condition) { name }
+			...maybeFragment @skip(if: true) @solo
+		}
+		fragment maybeFragment on Query @include(if: $condition) {
+			me { name }
+		}`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		selectors := doc.Operations[0].SelectionSet.Selectors
+		So(clearLocs(selectors[0].(*Field).Directives), ShouldResemble, []*Directive{
+			{Name: "include", Args: []*Arg{{Name: "if", Value: &Variable{Name: "condition"}}}},
+		})
+		So(clearLocs(selectors[1].(*FragmentSpread).Directives), ShouldResemble, []*Directive{
+			{Name: "skip", Args: []*Arg{{Name: "if", Value: &BooleanValue{Value: true}}}},
+			{Name: "solo"},
+		})
+		So(doc.Fragment("maybeFragment").Directives[0].Name, ShouldEqual, "include")
+	})
+}
+
+func TestParseValues(t *testing.T) {
+	Convey("Verify #parse on each kind of input value", t, func() {
+		q := `{
+			users(limit: 1.5e2, active: true, deleted: false, after: null, order: DESC,
+				tags: ["a", "b\n\u0041"], filter: {age: 3, name: {in: [$name]}}, id: -12) {
+				name
+			}
+		}`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		users := doc.Operations[0].SelectionSet.Selectors[0].(*Field)
+		So(clearLocs(users.Args), ShouldResemble, []*Arg{
+			{Name: "limit", Value: &FloatValue{Value: "1.5e2"}},
+			{Name: "active", Value: &BooleanValue{Value: true}},
+			{Name: "deleted", Value: &BooleanValue{Value: false}},
+			{Name: "after", Value: &NullValue{}},
+			{Name: "order", Value: &EnumValue{Value: "DESC"}},
+			{Name: "tags", Value: &ListValue{Values: []Value{
+				&StringValue{Value: "a"},
+				&StringValue{Value: "b\nA"},
+			}}},
+			{Name: "filter", Value: &ObjectValue{Fields: []*ObjectField{
+				{Name: "age", Value: &IntValue{Value: "3"}},
+				{Name: "name", Value: &ObjectValue{Fields: []*ObjectField{
+					{Name: "in", Value: &ListValue{Values: []Value{&Variable{Name: "name"}}}},
+				}}},
+			}}},
+			{Name: "id", Value: &IntValue{Value: "-12"}},
+		})
+		So(users.Args[6].Value.String(), ShouldEqual, `{age: 3, name: {in: [$name]}}`)
+	})
+
+	Convey("Verify #parse rejects variables in default values", t, func() {
+		_, err := Parse(`query q($a: Int = $b) { a }`)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestParseMalformed(t *testing.T) {
+	Convey("Verify #parse returns an error for malformed input", t, func() {
+		queries := []string{
+			`{ a { b(x: [1 2 3) } }`,
+			`{ a { b(x: {a: 1) } }`,
+			`{ a { b(x: {a 1}) } }`,
+			`{ a { b(x: "unterminated) } }`,
+			`{ a { b(x: 1) } `,
+			`query ($id: ) { a }`,
+		}
+
+		for _, q := range queries {
+			_, err := Parse(q)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestParseLocations(t *testing.T) {
+	Convey("Verify #parse records the location of each definition and selection", t, func() {
+		q := `query user($id: Int) {
+  user(id: $id) { ...f ... on User { n: name } }
+}
+fragment f on User { id }`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		op := doc.Operations[0]
+		So(op.Loc, ShouldResemble, Loc{Start: 0, End: 73, Line: 1, Column: 1})
+		So(op.VariableDefinitions[0].Loc, ShouldResemble, Loc{Start: 11, End: 19, Line: 1, Column: 12})
+		So(op.VariableDefinitions[0].Type.Loc, ShouldResemble, Loc{Start: 16, End: 19, Line: 1, Column: 17})
+
+		user := op.SelectionSet.Selectors[0].(*Field)
+		So(user.Loc, ShouldResemble, Loc{Start: 25, End: 71, Line: 2, Column: 3})
+		So(user.Args[0].Loc, ShouldResemble, Loc{Start: 30, End: 37, Line: 2, Column: 8})
+		So(user.Args[0].Value.Location(), ShouldResemble, Loc{Start: 34, End: 37, Line: 2, Column: 12})
+		So(user.Selection.Loc, ShouldResemble, Loc{Start: 39, End: 71, Line: 2, Column: 17})
+		So(user.Selection.Selectors[0].(*FragmentSpread).Loc, ShouldResemble, Loc{Start: 41, End: 45, Line: 2, Column: 19})
+
+		inline := user.Selection.Selectors[1].(*InlineFragment)
+		So(inline.Loc, ShouldResemble, Loc{Start: 46, End: 69, Line: 2, Column: 24})
+		So(inline.Selection.Selectors[0].(*Field).Loc, ShouldResemble, Loc{Start: 60, End: 67, Line: 2, Column: 38})
+
+		So(doc.Fragment("f").Loc, ShouldResemble, Loc{Start: 74, End: 99, Line: 4, Column: 1})
+	})
+
+	Convey("Verify #parse records the span of values and directives", t, func() {
+		q := `{ a(list: [1, "two"], object: {b: 1}) @skip(if: false) }`
+		doc, err := Parse(q)
+		So(err, ShouldBeNil)
+
+		a := doc.Operations[0].SelectionSet.Selectors[0].(*Field)
+		So(a.Loc, ShouldResemble, Loc{Start: 2, End: 54, Line: 1, Column: 3})
+		So(a.Args[0].Value.Location(), ShouldResemble, Loc{Start: 10, End: 20, Line: 1, Column: 11})
+		So(a.Args[0].Value.(*ListValue).Values[1].Location(), ShouldResemble, Loc{Start: 14, End: 19, Line: 1, Column: 15})
+		So(a.Args[1].Value.(*ObjectValue).Fields[0].Loc, ShouldResemble, Loc{Start: 31, End: 35, Line: 1, Column: 32})
+		So(a.Directives[0].Loc, ShouldResemble, Loc{Start: 38, End: 54, Line: 1, Column: 39})
+	})
+}
+
+func TestSyntaxError(t *testing.T) {
+	Convey("Given a query that can't be parsed", t, func() {
+		_, err := Parse("{\n  hero(id: ) {\n    name\n  }\n}")
+		So(err, ShouldHaveSameTypeAs, &SyntaxError{})
+
+		Convey("Then the error is located at the offending token", func() {
+			e := err.(*SyntaxError)
+			So(e.Message, ShouldEqual, "illegal value")
+			So(e.Loc, ShouldResemble, Loc{Start: 13, End: 13, Line: 2, Column: 12})
+		})
+
+		Convey("Then the excerpt marks the column with a caret", func() {
+			So(err.Error(), ShouldEqual, `syntax error: illegal value (2:12)
+1 | {
+2 |   hero(id: ) {
+  |            ^`)
+		})
+	})
+
+	Convey("Given a query the lexer rejects", t, func() {
+		_, err := Parse("{ a(x: \"unterminated) }")
+		So(err, ShouldHaveSameTypeAs, &SyntaxError{})
+		So(err.(*SyntaxError).Message, ShouldEqual, "unmatched double quotes")
+		So(err.(*SyntaxError).Loc.Column, ShouldEqual, 9)
+	})
+}
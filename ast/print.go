@@ -0,0 +1,221 @@
+package ast
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+const indent = "  "
+
+// String prints the document as canonical schema definition language; definitions are
+// written in order separated by a blank line
+func (d *SchemaDocument) String() string {
+	buf := &bytes.Buffer{}
+	for index, definition := range d.Definitions {
+		if index > 0 {
+			buf.WriteString("\n")
+		}
+		printDefinition(buf, definition)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func printDefinition(buf *bytes.Buffer, definition Definition) {
+	switch v := definition.(type) {
+	case *Extension:
+		buf.WriteString("extend ")
+		printDefinition(buf, v.Definition)
+
+	case *SchemaDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("schema")
+		printDirectives(buf, v.Directives)
+		if v.Query == "" && v.Mutation == "" && v.Subscription == "" {
+			return
+		}
+		buf.WriteString(" {\n")
+		for _, op := range [][2]string{{"query", v.Query}, {"mutation", v.Mutation}, {"subscription", v.Subscription}} {
+			if op[1] != "" {
+				buf.WriteString(indent + op[0] + ": " + op[1] + "\n")
+			}
+		}
+		buf.WriteString("}")
+
+	case *ScalarDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("scalar " + v.Name)
+		printDirectives(buf, v.Directives)
+
+	case *ObjectDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("type " + v.Name)
+		printImplements(buf, v.Interfaces)
+		printDirectives(buf, v.Directives)
+		printFieldDefinitions(buf, v.Fields)
+
+	case *InterfaceDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("interface " + v.Name)
+		printImplements(buf, v.Interfaces)
+		printDirectives(buf, v.Directives)
+		printFieldDefinitions(buf, v.Fields)
+
+	case *UnionDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("union " + v.Name)
+		printDirectives(buf, v.Directives)
+		if len(v.Types) > 0 {
+			buf.WriteString(" = " + strings.Join(v.Types, " | "))
+		}
+
+	case *EnumDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("enum " + v.Name)
+		printDirectives(buf, v.Directives)
+		if len(v.Values) == 0 {
+			return
+		}
+		buf.WriteString(" {\n")
+		for _, value := range v.Values {
+			printDescription(buf, value.Description, indent)
+			buf.WriteString(indent + value.Name)
+			printDirectives(buf, value.Directives)
+			buf.WriteString("\n")
+		}
+		buf.WriteString("}")
+
+	case *InputObjectDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("input " + v.Name)
+		printDirectives(buf, v.Directives)
+		if len(v.Fields) == 0 {
+			return
+		}
+		buf.WriteString(" {\n")
+		for _, field := range v.Fields {
+			printDescription(buf, field.Description, indent)
+			buf.WriteString(indent)
+			printInputValueDefinition(buf, field)
+			buf.WriteString("\n")
+		}
+		buf.WriteString("}")
+
+	case *DirectiveDefinition:
+		printDescription(buf, v.Description, "")
+		buf.WriteString("directive @" + v.Name)
+		printArgDefinitions(buf, v.Args, "")
+		if v.Repeatable {
+			buf.WriteString(" repeatable")
+		}
+		buf.WriteString(" on " + strings.Join(v.Locations, " | "))
+	}
+}
+
+func printImplements(buf *bytes.Buffer, interfaces []string) {
+	if len(interfaces) > 0 {
+		buf.WriteString(" implements " + strings.Join(interfaces, " & "))
+	}
+}
+
+func printFieldDefinitions(buf *bytes.Buffer, fields []*FieldDefinition) {
+	if len(fields) == 0 {
+		return
+	}
+
+	buf.WriteString(" {\n")
+	for _, field := range fields {
+		printDescription(buf, field.Description, indent)
+		buf.WriteString(indent + field.Name)
+		printArgDefinitions(buf, field.Args, indent)
+		buf.WriteString(": " + field.Type.String())
+		printDirectives(buf, field.Directives)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+}
+
+// printArgDefinitions writes arguments on a single line unless one of them has a
+// description in which case each is written on its own line
+func printArgDefinitions(buf *bytes.Buffer, args []*InputValueDefinition, prefix string) {
+	if len(args) == 0 {
+		return
+	}
+
+	multiline := false
+	for _, arg := range args {
+		if arg.Description != "" {
+			multiline = true
+		}
+	}
+
+	buf.WriteString("(")
+	for index, arg := range args {
+		if multiline {
+			buf.WriteString("\n")
+			printDescription(buf, arg.Description, prefix+indent)
+			buf.WriteString(prefix + indent)
+		} else if index > 0 {
+			buf.WriteString(", ")
+		}
+		printInputValueDefinition(buf, arg)
+	}
+	if multiline {
+		buf.WriteString("\n" + prefix)
+	}
+	buf.WriteString(")")
+}
+
+func printInputValueDefinition(buf *bytes.Buffer, value *InputValueDefinition) {
+	buf.WriteString(value.Name + ": " + value.Type.String())
+	if value.DefaultValue != nil {
+		buf.WriteString(" = " + value.DefaultValue.String())
+	}
+	printDirectives(buf, value.Directives)
+}
+
+func printDirectives(buf *bytes.Buffer, directives []*Directive) {
+	for _, directive := range directives {
+		buf.WriteString(" @" + directive.Name)
+		if len(directive.Args) == 0 {
+			continue
+		}
+
+		args := make([]string, len(directive.Args))
+		for index, arg := range directive.Args {
+			args[index] = arg.Name + ": " + arg.Value.String()
+		}
+		buf.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+}
+
+// printDescription writes a description as a block string or, if it can't be represented
+// as one, a quoted string
+func printDescription(buf *bytes.Buffer, description, prefix string) {
+	if description == "" {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(description, " ") || strings.HasSuffix(description, `"`) || strings.HasSuffix(description, `\`):
+		buf.WriteString(prefix + strconv.Quote(description) + "\n")
+
+	case strings.Contains(description, "\n"):
+		buf.WriteString(prefix + `"""` + "\n")
+		for _, line := range strings.Split(escapeBlockString(description), "\n") {
+			if line != "" {
+				buf.WriteString(prefix + line)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(prefix + `"""` + "\n")
+
+	default:
+		buf.WriteString(prefix + `"""` + escapeBlockString(description) + `"""` + "\n")
+	}
+}
+
+func escapeBlockString(s string) string {
+	return strings.Replace(s, `"""`, `\"""`, -1)
+}
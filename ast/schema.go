@@ -0,0 +1,203 @@
+package ast
+
+// --[ Schema Document ]----------------------------------------------
+
+// SchemaDocument holds the definitions read from schema definition language in the order
+// they were written
+type SchemaDocument struct {
+	Definitions []Definition `json:"definitions"`
+}
+
+// Schema returns the schema definition or nil if the document doesn't contain one
+func (d *SchemaDocument) Schema() *SchemaDefinition {
+	for _, definition := range d.Definitions {
+		if v, ok := definition.(*SchemaDefinition); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// Type returns the named type definition or nil if the document doesn't define it;
+// extensions are not considered
+func (d *SchemaDocument) Type(name string) TypeDefinition {
+	for _, definition := range d.Definitions {
+		if v, ok := definition.(TypeDefinition); ok && v.TypeName() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Directive returns the named directive definition or nil if the document doesn't define it
+func (d *SchemaDocument) Directive(name string) *DirectiveDefinition {
+	for _, definition := range d.Definitions {
+		if v, ok := definition.(*DirectiveDefinition); ok && v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Definition is implemented by each top level element of a schema document
+type Definition interface {
+	isDefinition()
+}
+
+// TypeDefinition is implemented by the definitions that declare a named type
+type TypeDefinition interface {
+	Definition
+	TypeName() string
+}
+
+// --[ Definitions ]--------------------------------------------------
+
+// SchemaDefinition names the root operation types e.g. schema { query: Query }
+type SchemaDefinition struct {
+	Description  string       `json:"description,omitempty"`
+	Directives   []*Directive `json:"directives,omitempty"`
+	Query        string       `json:"query,omitempty"`
+	Mutation     string       `json:"mutation,omitempty"`
+	Subscription string       `json:"subscription,omitempty"`
+	Loc          Loc          `json:"loc"`
+}
+
+type ScalarDefinition struct {
+	Description string       `json:"description,omitempty"`
+	Name        string       `json:"name"`
+	Directives  []*Directive `json:"directives,omitempty"`
+	Loc         Loc          `json:"loc"`
+}
+
+type ObjectDefinition struct {
+	Description string             `json:"description,omitempty"`
+	Name        string             `json:"name"`
+	Interfaces  []string           `json:"interfaces,omitempty"`
+	Directives  []*Directive       `json:"directives,omitempty"`
+	Fields      []*FieldDefinition `json:"fields,omitempty"`
+	Loc         Loc                `json:"loc"`
+}
+
+type InterfaceDefinition struct {
+	Description string             `json:"description,omitempty"`
+	Name        string             `json:"name"`
+	Interfaces  []string           `json:"interfaces,omitempty"`
+	Directives  []*Directive       `json:"directives,omitempty"`
+	Fields      []*FieldDefinition `json:"fields,omitempty"`
+	Loc         Loc                `json:"loc"`
+}
+
+type UnionDefinition struct {
+	Description string       `json:"description,omitempty"`
+	Name        string       `json:"name"`
+	Directives  []*Directive `json:"directives,omitempty"`
+	Types       []string     `json:"types,omitempty"`
+	Loc         Loc          `json:"loc"`
+}
+
+type EnumDefinition struct {
+	Description string                 `json:"description,omitempty"`
+	Name        string                 `json:"name"`
+	Directives  []*Directive           `json:"directives,omitempty"`
+	Values      []*EnumValueDefinition `json:"values,omitempty"`
+	Loc         Loc                    `json:"loc"`
+}
+
+type EnumValueDefinition struct {
+	Description string       `json:"description,omitempty"`
+	Name        string       `json:"name"`
+	Directives  []*Directive `json:"directives,omitempty"`
+	Loc         Loc          `json:"loc"`
+}
+
+type InputObjectDefinition struct {
+	Description string                  `json:"description,omitempty"`
+	Name        string                  `json:"name"`
+	Directives  []*Directive            `json:"directives,omitempty"`
+	Fields      []*InputValueDefinition `json:"fields,omitempty"`
+	Loc         Loc                     `json:"loc"`
+}
+
+type FieldDefinition struct {
+	Description string                  `json:"description,omitempty"`
+	Name        string                  `json:"name"`
+	Args        []*InputValueDefinition `json:"args,omitempty"`
+	Type        *Type                   `json:"type"`
+	Directives  []*Directive            `json:"directives,omitempty"`
+	Loc         Loc                     `json:"loc"`
+}
+
+// InputValueDefinition declares an argument or input object field.  DefaultValue is nil
+// when no default was given.
+type InputValueDefinition struct {
+	Description  string       `json:"description,omitempty"`
+	Name         string       `json:"name"`
+	Type         *Type        `json:"type"`
+	DefaultValue Value        `json:"default,omitempty"`
+	Directives   []*Directive `json:"directives,omitempty"`
+	Loc          Loc          `json:"loc"`
+}
+
+// DirectiveDefinition declares a directive and the locations it may be used e.g.
+// directive @deprecated(reason: String) on FIELD_DEFINITION | ENUM_VALUE
+type DirectiveDefinition struct {
+	Description string                  `json:"description,omitempty"`
+	Name        string                  `json:"name"`
+	Args        []*InputValueDefinition `json:"args,omitempty"`
+	Repeatable  bool                    `json:"repeatable,omitempty"`
+	Locations   []string                `json:"locations"`
+	Loc         Loc                     `json:"loc"`
+}
+
+// Extension adds to a previously defined schema or type e.g. extend type Query { ... }.
+// Definition holds only the elements being added.
+type Extension struct {
+	Definition Definition `json:"definition"`
+	Loc        Loc        `json:"loc"`
+}
+
+// Field returns the named field or nil if the type doesn't define it
+func (d *ObjectDefinition) Field(name string) *FieldDefinition {
+	return findFieldDefinition(d.Fields, name)
+}
+
+// Field returns the named field or nil if the interface doesn't define it
+func (d *InterfaceDefinition) Field(name string) *FieldDefinition {
+	return findFieldDefinition(d.Fields, name)
+}
+
+// Arg returns the named argument or nil if the field doesn't accept it
+func (d *FieldDefinition) Arg(name string) *InputValueDefinition {
+	for _, arg := range d.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func findFieldDefinition(fields []*FieldDefinition, name string) *FieldDefinition {
+	for _, field := range fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func (d *ScalarDefinition) TypeName() string      { return d.Name }
+func (d *ObjectDefinition) TypeName() string      { return d.Name }
+func (d *InterfaceDefinition) TypeName() string   { return d.Name }
+func (d *UnionDefinition) TypeName() string       { return d.Name }
+func (d *EnumDefinition) TypeName() string        { return d.Name }
+func (d *InputObjectDefinition) TypeName() string { return d.Name }
+
+func (d *SchemaDefinition) isDefinition()      {}
+func (d *ScalarDefinition) isDefinition()      {}
+func (d *ObjectDefinition) isDefinition()      {}
+func (d *InterfaceDefinition) isDefinition()   {}
+func (d *UnionDefinition) isDefinition()       {}
+func (d *EnumDefinition) isDefinition()        {}
+func (d *InputObjectDefinition) isDefinition() {}
+func (d *DirectiveDefinition) isDefinition()   {}
+func (d *Extension) isDefinition()             {}
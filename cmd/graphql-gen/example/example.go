@@ -0,0 +1,5 @@
+// Package example serves the Star Wars schema with the code graphql-gen generates for it.
+// The resolvers are implemented in the package's tests.
+package example
+
+//go:generate go run github.com/savaki/graphql/cmd/graphql-gen starwars.graphql
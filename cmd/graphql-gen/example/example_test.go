@@ -0,0 +1,158 @@
+package example
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/savaki/graphql"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// starWars implements the resolvers of each type
+type starWars struct {
+	humans  []*Human
+	droids  []*Droid
+	heights map[string]float64 // in meters
+	reviews []*Review
+	now     time.Time
+}
+
+func (s *starWars) Hero(ctx context.Context, args QueryHeroArgs) (Character, error) {
+	if args.Episode != nil && *args.Episode == EpisodeEmpire {
+		return s.humans[0], nil
+	}
+	return s.droids[0], nil
+}
+
+func (s *starWars) Human(ctx context.Context, args QueryHumanArgs) (*Human, error) {
+	for _, human := range s.humans {
+		if human.ID == args.ID {
+			return human, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *starWars) Search(ctx context.Context, args QuerySearchArgs) ([]SearchResult, error) {
+	results := []SearchResult{}
+	for _, human := range s.humans {
+		if strings.Contains(strings.ToLower(human.Name), strings.ToLower(args.Text)) {
+			results = append(results, human)
+		}
+	}
+	for _, droid := range s.droids {
+		if strings.Contains(strings.ToLower(droid.Name), strings.ToLower(args.Text)) {
+			results = append(results, droid)
+		}
+	}
+	return results, nil
+}
+
+func (s *starWars) Reviews(ctx context.Context, args QueryReviewsArgs) ([]*Review, error) {
+	reviews := []*Review{}
+	for _, review := range s.reviews {
+		if review.Episode == args.Episode && len(reviews) < *args.First {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (s *starWars) Height(ctx context.Context, obj *Human, args HumanHeightArgs) (*float64, error) {
+	height, ok := s.heights[obj.ID]
+	if !ok {
+		return nil, nil
+	}
+	if *args.Unit == LengthUnitFoot {
+		height = float64(int(height*3.28084*100)) / 100
+	}
+	return &height, nil
+}
+
+func (s *starWars) CreateReview(ctx context.Context, args MutationCreateReviewArgs) (*Review, error) {
+	review := &Review{
+		Episode:    args.Episode,
+		Stars:      args.Review.Stars,
+		Commentary: args.Review.Commentary,
+		CreatedAt:  s.now,
+	}
+	s.reviews = append(s.reviews, review)
+	return review, nil
+}
+
+func newStarWars() *starWars {
+	luke := &Human{ID: "1000", Name: "Luke Skywalker", AppearsIn: []Episode{EpisodeNewhope, EpisodeEmpire, EpisodeJedi}}
+	function := "Astromech"
+	r2 := &Droid{ID: "2001", Name: "R2-D2", AppearsIn: []Episode{EpisodeNewhope}, PrimaryFunction: &function}
+	threepio := &Droid{ID: "2000", Name: "C-3PO", AppearsIn: []Episode{EpisodeNewhope}}
+	luke.Friends = []Character{r2, threepio}
+	r2.Friends = []Character{luke}
+
+	return &starWars{
+		humans:  []*Human{luke},
+		droids:  []*Droid{r2, threepio},
+		heights: map[string]float64{"1000": 1.72},
+		now:     time.Date(1977, time.May, 25, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	Convey("Given a store generated from the Star Wars schema", t, func() {
+		sw := newStarWars()
+		executor := graphql.Executor{
+			Store: NewStore(&Resolvers{Query: sw, Mutation: sw, Human: sw}),
+		}
+		execute := func(query string, variables map[string]interface{}) string {
+			w := &bytes.Buffer{}
+			executor.HandleRequest(query, "", variables, w)
+			return w.String()
+		}
+
+		Convey("Then the fields of models and interfaces should be resolved", func() {
+			So(execute(`{ hero { __typename name friends { name ... on Droid { primaryFunction } } } }`, nil), ShouldEqual,
+				`{"data":{"hero":{"__typename":"Droid","name":"R2-D2","friends":[{"name":"Luke Skywalker"}]}}}`)
+			So(execute(`{ hero(episode: EMPIRE) { id appearsIn friends { name ... on Droid { primaryFunction } } } }`, nil), ShouldEqual,
+				`{"data":{"hero":{"id":"1000","appearsIn":["NEWHOPE","EMPIRE","JEDI"],"friends":[{"name":"R2-D2","primaryFunction":"Astromech"},{"name":"C-3PO","primaryFunction":null}]}}}`)
+		})
+
+		Convey("Then arguments should be decoded, with their defaults, for the type's resolver", func() {
+			So(execute(`query ($id: ID!) { human(id: $id) { name height meters: height(unit: METER) feet: height(unit: FOOT) } }`, map[string]interface{}{"id": 1000}), ShouldEqual,
+				`{"data":{"human":{"name":"Luke Skywalker","height":1.72,"meters":1.72,"feet":5.64}}}`)
+			So(execute(`{ human(id: "1") { name } }`, nil), ShouldEqual, `{"data":{"human":null}}`)
+		})
+
+		Convey("Then the members of unions should be resolved", func() {
+			So(execute(`{ search(text: "r") { __typename ... on Human { name } ... on Droid { id } } }`, nil), ShouldEqual,
+				`{"data":{"search":[{"__typename":"Human","name":"Luke Skywalker"},{"__typename":"Droid","id":"2001"}]}}`)
+		})
+
+		Convey("Then mutations should decode input objects", func() {
+			So(execute(`mutation { createReview(episode: JEDI, review: { stars: 5, commentary: "Great!" }) { episode stars commentary createdAt } }`, nil), ShouldEqual,
+				`{"data":{"createReview":{"episode":"JEDI","stars":5,"commentary":"Great!","createdAt":"1977-05-25T00:00:00Z"}}}`)
+			So(execute(`{ reviews(episode: JEDI) { stars } none: reviews(episode: EMPIRE) { stars } }`, nil), ShouldEqual,
+				`{"data":{"reviews":[{"stars":5}],"none":[]}}`)
+		})
+
+		Convey("Then arguments that don't fit the schema should be reported", func() {
+			So(execute(`{ hero(episode: CLONES) { name } }`, nil), ShouldEqual,
+				`{"data":{"hero":null},"errors":[{"message":"invalid value for episode =\u003e CLONES is not a valid Episode","locations":[{"line":1,"column":3}],"path":["hero"]}]}`)
+			So(execute(`mutation { createReview(episode: JEDI, review: { commentary: "Meh" }) { stars } }`, nil), ShouldEqual,
+				`{"data":{"createReview":null},"errors":[{"message":"invalid value for review =\u003e stars is required","locations":[{"line":1,"column":12}],"path":["createReview"]}]}`)
+		})
+	})
+
+	Convey("Given a store without the resolver of a type", t, func() {
+		sw := newStarWars()
+		executor := graphql.Executor{Store: NewStore(&Resolvers{Query: sw})}
+
+		Convey("Then the fields it resolves should fail", func() {
+			w := &bytes.Buffer{}
+			executor.Handle(`{ human(id: "1000") { name height } }`, w)
+			So(w.String(), ShouldEqual,
+				`{"data":{"human":{"name":"Luke Skywalker","height":null}},"errors":[{"message":"no resolver was given for Human","locations":[{"line":1,"column":28}],"path":["human","height"]}]}`)
+		})
+	})
+}
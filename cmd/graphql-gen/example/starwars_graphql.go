@@ -0,0 +1,909 @@
+// generated by graphql-gen starwars.graphql; DO NOT EDIT
+
+package example
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/savaki/graphql"
+)
+
+// Resolvers resolves the fields of the root types, and the fields of other types that take
+// arguments; the values of other fields are read from the models returned
+type Resolvers struct {
+	Human    HumanResolver
+	Query    QueryResolver
+	Mutation MutationResolver
+}
+
+// NewStore returns a Store that resolves operations with r
+func NewStore(r *Resolvers) graphql.Store {
+	return &store{querySelection{r: r}}
+}
+
+// store resolves queries as the query root type does
+type store struct {
+	querySelection
+}
+
+func (s *store) Mutate(c *graphql.Context) (graphql.Field, error) {
+	return s.MutateContext(context.Background(), c)
+}
+
+func (s *store) MutateContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	return (&mutationSelection{r: s.r}).QueryContext(ctx, c)
+}
+
+// The episodes of the original trilogy
+type Episode string
+
+const (
+	EpisodeNewhope Episode = "NEWHOPE"
+	EpisodeEmpire  Episode = "EMPIRE"
+	EpisodeJedi    Episode = "JEDI"
+)
+
+// LengthUnit enumerates the values of the LengthUnit enum
+type LengthUnit string
+
+const (
+	LengthUnitMeter LengthUnit = "METER"
+	LengthUnitFoot  LengthUnit = "FOOT"
+)
+
+// Character is implemented by the models of the types that implement Character
+type Character interface {
+	isCharacter()
+}
+
+// Human is the model of the Human type
+type Human struct {
+	ID        string
+	Name      string
+	Friends   []Character
+	AppearsIn []Episode
+}
+
+func (*Human) isCharacter() {}
+
+func (*Human) isSearchResult() {}
+
+// HumanResolver resolves the fields of Human that take arguments
+type HumanResolver interface {
+	Height(ctx context.Context, obj *Human, args HumanHeightArgs) (*float64, error)
+}
+
+// HumanHeightArgs holds the arguments of Human.height
+type HumanHeightArgs struct {
+	Unit *LengthUnit
+}
+
+func readHumanHeightArgs(values map[string]interface{}) (v HumanHeightArgs, err error) {
+	if value, ok := lookup(values, "unit", "METER"); ok {
+		if v.Unit, err = decodeOptLengthUnit(value); err != nil {
+			return v, fmt.Errorf("invalid value for unit => %v", err)
+		}
+	}
+	return v, nil
+}
+
+// humanSelection resolves the fields of Human
+type humanSelection struct {
+	r   *Resolvers
+	obj *Human
+}
+
+func (s *humanSelection) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *humanSelection) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	switch c.Name {
+	case "id":
+		return fieldOfID(s.r, s.obj.ID), nil
+	case "name":
+		return fieldOfString(s.r, s.obj.Name), nil
+	case "friends":
+		return fieldOfOptListOfOptCharacter(s.r, s.obj.Friends), nil
+	case "appearsIn":
+		return fieldOfListOfEpisode(s.r, s.obj.AppearsIn), nil
+	case "height":
+		if s.r.Human == nil {
+			return nil, noResolver("Human")
+		}
+		args, err := readHumanHeightArgs(argMap(c.Args))
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.r.Human.Height(ctx, s.obj, args)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOfOptFloat(s.r, v), nil
+	}
+	return nil, graphql.ErrFieldNotFound
+}
+
+func (s *humanSelection) TypeName() string {
+	return "Human"
+}
+
+func (s *humanSelection) Satisfies(typeCondition string) bool {
+	switch typeCondition {
+	case "Human", "Character", "SearchResult":
+		return true
+	}
+	return false
+}
+
+func (s *humanSelection) NonNullField(name string) bool {
+	switch name {
+	case "id", "name", "appearsIn":
+		return true
+	}
+	return false
+}
+
+func (s *humanSelection) ScalarField(name string) string {
+	switch name {
+	case "height":
+		return "Float"
+	case "id":
+		return "ID"
+	case "name":
+		return "String"
+	}
+	return ""
+}
+
+func (s *humanSelection) ScalarArg(field, arg string) string {
+	return ""
+}
+
+// Droid is the model of the Droid type
+type Droid struct {
+	ID        string
+	Name      string
+	Friends   []Character
+	AppearsIn []Episode
+	// This droid's primary function
+	PrimaryFunction *string
+}
+
+func (*Droid) isCharacter() {}
+
+func (*Droid) isSearchResult() {}
+
+// droidSelection resolves the fields of Droid
+type droidSelection struct {
+	r   *Resolvers
+	obj *Droid
+}
+
+func (s *droidSelection) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *droidSelection) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	switch c.Name {
+	case "id":
+		return fieldOfID(s.r, s.obj.ID), nil
+	case "name":
+		return fieldOfString(s.r, s.obj.Name), nil
+	case "friends":
+		return fieldOfOptListOfOptCharacter(s.r, s.obj.Friends), nil
+	case "appearsIn":
+		return fieldOfListOfEpisode(s.r, s.obj.AppearsIn), nil
+	case "primaryFunction":
+		return fieldOfOptString(s.r, s.obj.PrimaryFunction), nil
+	}
+	return nil, graphql.ErrFieldNotFound
+}
+
+func (s *droidSelection) TypeName() string {
+	return "Droid"
+}
+
+func (s *droidSelection) Satisfies(typeCondition string) bool {
+	switch typeCondition {
+	case "Droid", "Character", "SearchResult":
+		return true
+	}
+	return false
+}
+
+func (s *droidSelection) NonNullField(name string) bool {
+	switch name {
+	case "id", "name", "appearsIn":
+		return true
+	}
+	return false
+}
+
+func (s *droidSelection) ScalarField(name string) string {
+	switch name {
+	case "id":
+		return "ID"
+	case "name", "primaryFunction":
+		return "String"
+	}
+	return ""
+}
+
+func (s *droidSelection) ScalarArg(field, arg string) string {
+	return ""
+}
+
+// SearchResult is implemented by the models of the types that are members of SearchResult
+type SearchResult interface {
+	isSearchResult()
+}
+
+// Review is the model of the Review type
+type Review struct {
+	Episode    Episode
+	Stars      int
+	Commentary *string
+	CreatedAt  graphql.Value
+}
+
+// reviewSelection resolves the fields of Review
+type reviewSelection struct {
+	r   *Resolvers
+	obj *Review
+}
+
+func (s *reviewSelection) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *reviewSelection) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	switch c.Name {
+	case "episode":
+		return fieldOfEpisode(s.r, s.obj.Episode), nil
+	case "stars":
+		return fieldOfInt(s.r, s.obj.Stars), nil
+	case "commentary":
+		return fieldOfOptString(s.r, s.obj.Commentary), nil
+	case "createdAt":
+		return fieldOfOptTime(s.r, s.obj.CreatedAt), nil
+	}
+	return nil, graphql.ErrFieldNotFound
+}
+
+func (s *reviewSelection) TypeName() string {
+	return "Review"
+}
+
+func (s *reviewSelection) Satisfies(typeCondition string) bool {
+	switch typeCondition {
+	case "Review":
+		return true
+	}
+	return false
+}
+
+func (s *reviewSelection) NonNullField(name string) bool {
+	switch name {
+	case "episode", "stars":
+		return true
+	}
+	return false
+}
+
+func (s *reviewSelection) ScalarField(name string) string {
+	switch name {
+	case "stars":
+		return "Int"
+	case "commentary":
+		return "String"
+	case "createdAt":
+		return "Time"
+	}
+	return ""
+}
+
+func (s *reviewSelection) ScalarArg(field, arg string) string {
+	return ""
+}
+
+// ReviewInput holds the fields of the ReviewInput input object
+type ReviewInput struct {
+	Stars      int
+	Commentary *string
+}
+
+func readReviewInput(values map[string]interface{}) (v ReviewInput, err error) {
+	if value, ok := values["stars"]; ok {
+		if v.Stars, err = decodeInt(value); err != nil {
+			return v, fmt.Errorf("invalid value for stars => %v", err)
+		}
+	} else {
+		return v, fmt.Errorf("stars is required")
+	}
+	if value, ok := values["commentary"]; ok {
+		if v.Commentary, err = decodeOptString(value); err != nil {
+			return v, fmt.Errorf("invalid value for commentary => %v", err)
+		}
+	}
+	return v, nil
+}
+
+// QueryResolver resolves the fields of Query
+type QueryResolver interface {
+	Hero(ctx context.Context, args QueryHeroArgs) (Character, error)
+	Human(ctx context.Context, args QueryHumanArgs) (*Human, error)
+	Search(ctx context.Context, args QuerySearchArgs) ([]SearchResult, error)
+	Reviews(ctx context.Context, args QueryReviewsArgs) ([]*Review, error)
+}
+
+// QueryHeroArgs holds the arguments of Query.hero
+type QueryHeroArgs struct {
+	Episode *Episode
+}
+
+func readQueryHeroArgs(values map[string]interface{}) (v QueryHeroArgs, err error) {
+	if value, ok := values["episode"]; ok {
+		if v.Episode, err = decodeOptEpisode(value); err != nil {
+			return v, fmt.Errorf("invalid value for episode => %v", err)
+		}
+	}
+	return v, nil
+}
+
+// QueryHumanArgs holds the arguments of Query.human
+type QueryHumanArgs struct {
+	ID string
+}
+
+func readQueryHumanArgs(values map[string]interface{}) (v QueryHumanArgs, err error) {
+	if value, ok := values["id"]; ok {
+		if v.ID, err = decodeID(value); err != nil {
+			return v, fmt.Errorf("invalid value for id => %v", err)
+		}
+	} else {
+		return v, fmt.Errorf("id is required")
+	}
+	return v, nil
+}
+
+// QuerySearchArgs holds the arguments of Query.search
+type QuerySearchArgs struct {
+	Text string
+}
+
+func readQuerySearchArgs(values map[string]interface{}) (v QuerySearchArgs, err error) {
+	if value, ok := values["text"]; ok {
+		if v.Text, err = decodeString(value); err != nil {
+			return v, fmt.Errorf("invalid value for text => %v", err)
+		}
+	} else {
+		return v, fmt.Errorf("text is required")
+	}
+	return v, nil
+}
+
+// QueryReviewsArgs holds the arguments of Query.reviews
+type QueryReviewsArgs struct {
+	Episode Episode
+	First   *int
+}
+
+func readQueryReviewsArgs(values map[string]interface{}) (v QueryReviewsArgs, err error) {
+	if value, ok := values["episode"]; ok {
+		if v.Episode, err = decodeEpisode(value); err != nil {
+			return v, fmt.Errorf("invalid value for episode => %v", err)
+		}
+	} else {
+		return v, fmt.Errorf("episode is required")
+	}
+	if value, ok := lookup(values, "first", int64(10)); ok {
+		if v.First, err = decodeOptInt(value); err != nil {
+			return v, fmt.Errorf("invalid value for first => %v", err)
+		}
+	}
+	return v, nil
+}
+
+// querySelection resolves the fields of Query
+type querySelection struct {
+	r *Resolvers
+}
+
+func (s *querySelection) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *querySelection) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	switch c.Name {
+	case "hero":
+		if s.r.Query == nil {
+			return nil, noResolver("Query")
+		}
+		args, err := readQueryHeroArgs(argMap(c.Args))
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.r.Query.Hero(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOfOptCharacter(s.r, v), nil
+	case "human":
+		if s.r.Query == nil {
+			return nil, noResolver("Query")
+		}
+		args, err := readQueryHumanArgs(argMap(c.Args))
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.r.Query.Human(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOfOptHuman(s.r, v), nil
+	case "search":
+		if s.r.Query == nil {
+			return nil, noResolver("Query")
+		}
+		args, err := readQuerySearchArgs(argMap(c.Args))
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.r.Query.Search(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOfListOfSearchResult(s.r, v), nil
+	case "reviews":
+		if s.r.Query == nil {
+			return nil, noResolver("Query")
+		}
+		args, err := readQueryReviewsArgs(argMap(c.Args))
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.r.Query.Reviews(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOfListOfReview(s.r, v), nil
+	}
+	return nil, graphql.ErrFieldNotFound
+}
+
+func (s *querySelection) TypeName() string {
+	return "Query"
+}
+
+func (s *querySelection) Satisfies(typeCondition string) bool {
+	switch typeCondition {
+	case "Query":
+		return true
+	}
+	return false
+}
+
+func (s *querySelection) NonNullField(name string) bool {
+	switch name {
+	case "search", "reviews":
+		return true
+	}
+	return false
+}
+
+func (s *querySelection) ScalarField(name string) string {
+	return ""
+}
+
+func (s *querySelection) ScalarArg(field, arg string) string {
+	switch field + "." + arg {
+	case "human.id":
+		return "ID"
+	case "reviews.first":
+		return "Int"
+	case "search.text":
+		return "String"
+	}
+	return ""
+}
+
+// MutationResolver resolves the fields of Mutation
+type MutationResolver interface {
+	CreateReview(ctx context.Context, args MutationCreateReviewArgs) (*Review, error)
+}
+
+// MutationCreateReviewArgs holds the arguments of Mutation.createReview
+type MutationCreateReviewArgs struct {
+	Episode Episode
+	Review  ReviewInput
+}
+
+func readMutationCreateReviewArgs(values map[string]interface{}) (v MutationCreateReviewArgs, err error) {
+	if value, ok := values["episode"]; ok {
+		if v.Episode, err = decodeEpisode(value); err != nil {
+			return v, fmt.Errorf("invalid value for episode => %v", err)
+		}
+	} else {
+		return v, fmt.Errorf("episode is required")
+	}
+	if value, ok := values["review"]; ok {
+		if v.Review, err = decodeReviewInput(value); err != nil {
+			return v, fmt.Errorf("invalid value for review => %v", err)
+		}
+	} else {
+		return v, fmt.Errorf("review is required")
+	}
+	return v, nil
+}
+
+// mutationSelection resolves the fields of Mutation
+type mutationSelection struct {
+	r *Resolvers
+}
+
+func (s *mutationSelection) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *mutationSelection) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	switch c.Name {
+	case "createReview":
+		if s.r.Mutation == nil {
+			return nil, noResolver("Mutation")
+		}
+		args, err := readMutationCreateReviewArgs(argMap(c.Args))
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.r.Mutation.CreateReview(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOfOptReview(s.r, v), nil
+	}
+	return nil, graphql.ErrFieldNotFound
+}
+
+func (s *mutationSelection) TypeName() string {
+	return "Mutation"
+}
+
+func (s *mutationSelection) Satisfies(typeCondition string) bool {
+	switch typeCondition {
+	case "Mutation":
+		return true
+	}
+	return false
+}
+
+func (s *mutationSelection) NonNullField(name string) bool {
+	return false
+}
+
+func (s *mutationSelection) ScalarField(name string) string {
+	return ""
+}
+
+func (s *mutationSelection) ScalarArg(field, arg string) string {
+	return ""
+}
+
+func decodeEpisode(value graphql.Value) (v Episode, err error) {
+	if value == nil {
+		return v, errNull
+	}
+	switch s, _ := value.(string); s {
+	case "NEWHOPE", "EMPIRE", "JEDI":
+		return Episode(s), nil
+	}
+	return v, fmt.Errorf("%v is not a valid Episode", value)
+}
+
+func decodeID(value graphql.Value) (v string, err error) {
+	if value == nil {
+		return v, errNull
+	}
+	id, err := graphql.CoerceID(value)
+	if err != nil {
+		return v, err
+	}
+	return id.(string), nil
+}
+
+func decodeInt(value graphql.Value) (v int, err error) {
+	if value == nil {
+		return v, errNull
+	}
+	n, err := graphql.CoerceInt(value)
+	if err != nil {
+		return v, err
+	}
+	return int(n.(int64)), nil
+}
+
+func decodeLengthUnit(value graphql.Value) (v LengthUnit, err error) {
+	if value == nil {
+		return v, errNull
+	}
+	switch s, _ := value.(string); s {
+	case "METER", "FOOT":
+		return LengthUnit(s), nil
+	}
+	return v, fmt.Errorf("%v is not a valid LengthUnit", value)
+}
+
+func decodeOptEpisode(value graphql.Value) (v *Episode, err error) {
+	if value == nil {
+		return nil, nil
+	}
+	x, err := decodeEpisode(value)
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+func decodeOptInt(value graphql.Value) (v *int, err error) {
+	if value == nil {
+		return nil, nil
+	}
+	x, err := decodeInt(value)
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+func decodeOptLengthUnit(value graphql.Value) (v *LengthUnit, err error) {
+	if value == nil {
+		return nil, nil
+	}
+	x, err := decodeLengthUnit(value)
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+func decodeOptString(value graphql.Value) (v *string, err error) {
+	if value == nil {
+		return nil, nil
+	}
+	x, err := decodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+func decodeReviewInput(value graphql.Value) (v ReviewInput, err error) {
+	if value == nil {
+		return v, errNull
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return v, fmt.Errorf("%v is not a valid ReviewInput", value)
+	}
+	return readReviewInput(fields)
+}
+
+func decodeString(value graphql.Value) (v string, err error) {
+	if value == nil {
+		return v, errNull
+	}
+	s, err := graphql.ParseString(value)
+	if err != nil {
+		return v, err
+	}
+	return s.(string), nil
+}
+
+func fieldOfEpisode(r *Resolvers, v Episode) graphql.Field {
+	return valueField{v}
+}
+
+func fieldOfID(r *Resolvers, v string) graphql.Field {
+	return valueField{v}
+}
+
+func fieldOfInt(r *Resolvers, v int) graphql.Field {
+	return valueField{v}
+}
+
+func fieldOfListOfEpisode(r *Resolvers, v []Episode) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	elements := make([]graphql.Field, len(v))
+	for index, element := range v {
+		elements[index] = fieldOfEpisode(r, element)
+	}
+	return &listField{elements: elements, nonNull: true}
+}
+
+func fieldOfListOfReview(r *Resolvers, v []*Review) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	elements := make([]graphql.Field, len(v))
+	for index, element := range v {
+		elements[index] = fieldOfReview(r, element)
+	}
+	return &listField{elements: elements, nonNull: true}
+}
+
+func fieldOfListOfSearchResult(r *Resolvers, v []SearchResult) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	elements := make([]graphql.Field, len(v))
+	for index, element := range v {
+		elements[index] = fieldOfSearchResult(r, element)
+	}
+	return &listField{elements: elements, nonNull: true}
+}
+
+func fieldOfOptCharacter(r *Resolvers, v Character) graphql.Field {
+	switch v := v.(type) {
+	case *Human:
+		return fieldOfOptHuman(r, v)
+	case *Droid:
+		return fieldOfOptDroid(r, v)
+	}
+	return nil
+}
+
+func fieldOfOptDroid(r *Resolvers, v *Droid) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	return objectField{&droidSelection{r: r, obj: v}}
+}
+
+func fieldOfOptFloat(r *Resolvers, v *float64) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	return valueField{*v}
+}
+
+func fieldOfOptHuman(r *Resolvers, v *Human) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	return objectField{&humanSelection{r: r, obj: v}}
+}
+
+func fieldOfOptListOfOptCharacter(r *Resolvers, v []Character) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	elements := make([]graphql.Field, len(v))
+	for index, element := range v {
+		elements[index] = fieldOfOptCharacter(r, element)
+	}
+	return &listField{elements: elements, nonNull: false}
+}
+
+func fieldOfOptReview(r *Resolvers, v *Review) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	return objectField{&reviewSelection{r: r, obj: v}}
+}
+
+func fieldOfOptString(r *Resolvers, v *string) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	return valueField{*v}
+}
+
+func fieldOfOptTime(r *Resolvers, v graphql.Value) graphql.Field {
+	return valueField{v}
+}
+
+func fieldOfReview(r *Resolvers, v *Review) graphql.Field {
+	if v == nil {
+		return nil
+	}
+	return objectField{&reviewSelection{r: r, obj: v}}
+}
+
+func fieldOfSearchResult(r *Resolvers, v SearchResult) graphql.Field {
+	switch v := v.(type) {
+	case *Human:
+		return fieldOfOptHuman(r, v)
+	case *Droid:
+		return fieldOfOptDroid(r, v)
+	}
+	return nil
+}
+
+func fieldOfString(r *Resolvers, v string) graphql.Field {
+	return valueField{v}
+}
+
+// valueField holds the value of a scalar or enum
+type valueField struct {
+	value graphql.Value
+}
+
+func (f valueField) Selection() (graphql.Selection, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+func (f valueField) Value() (graphql.Value, error) {
+	return f.value, nil
+}
+
+// objectField holds an object
+type objectField struct {
+	selection graphql.Selection
+}
+
+func (f objectField) Selection() (graphql.Selection, error) {
+	return f.selection, nil
+}
+
+func (f objectField) Value() (graphql.Value, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+// listField holds a list; its value is the list of the values of its elements
+type listField struct {
+	elements []graphql.Field
+	nonNull  bool
+}
+
+func (f *listField) Selection() (graphql.Selection, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+func (f *listField) Value() (graphql.Value, error) {
+	values := make([]interface{}, len(f.elements))
+	for index, element := range f.elements {
+		if element == nil {
+			continue
+		}
+		value, err := element.Value()
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
+	}
+	return values, nil
+}
+
+func (f *listField) Elements() ([]graphql.Field, error) {
+	return f.elements, nil
+}
+
+func (f *listField) NonNullElements() bool {
+	return f.nonNull
+}
+
+var errNull = fmt.Errorf("expected a non-null value")
+
+func noResolver(typeName string) error {
+	return fmt.Errorf("no resolver was given for %v", typeName)
+}
+
+// argMap returns the arguments of a field by name
+func argMap(args []graphql.Arg) map[string]interface{} {
+	values := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		values[arg.Name] = arg.Value
+	}
+	return values
+}
+
+// lookup returns the named value, or the default value if there isn't one
+func lookup(values map[string]interface{}, name string, defaultValue interface{}) (interface{}, bool) {
+	if value, ok := values[name]; ok {
+		return value, true
+	}
+	return defaultValue, true
+}
@@ -0,0 +1,1066 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/savaki/graphql/ast"
+)
+
+// builtins maps the spec's scalars to the Go types that hold their values
+var builtins = map[string]string{
+	"Int":     "int",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "string",
+}
+
+// decodeBuiltin converts an input value with the graphql package's coercion of a scalar
+var decodeBuiltin = map[string]string{
+	"Int":     "n, err := graphql.CoerceInt(value)\nif err != nil {\nreturn v, err\n}\nreturn int(n.(int64)), nil\n",
+	"Float":   "f, err := graphql.CoerceFloat(value)\nif err != nil {\nreturn v, err\n}\nreturn f.(float64), nil\n",
+	"String":  "s, err := graphql.ParseString(value)\nif err != nil {\nreturn v, err\n}\nreturn s.(string), nil\n",
+	"Boolean": "b, err := graphql.ParseBoolean(value)\nif err != nil {\nreturn v, err\n}\nreturn b.(bool), nil\n",
+	"ID":      "id, err := graphql.CoerceID(value)\nif err != nil {\nreturn v, err\n}\nreturn id.(string), nil\n",
+}
+
+// initialisms are written in capitals when they make up a word of a Go name, as golint asks
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// --[ Generator ]----------------------------------------------------
+
+// generator writes the Go source for a schema document
+type generator struct {
+	types    map[string]ast.TypeDefinition
+	order    []ast.TypeDefinition // the types in the order they were defined
+	query    *ast.ObjectDefinition
+	mutation *ast.ObjectDefinition
+	members  map[string][]*ast.ObjectDefinition // the objects implementing an interface or in a union
+	names    map[string]string                  // the Go names declared and what declared them
+
+	buf      *bytes.Buffer
+	fields   map[string]*ast.Type // the field constructors called, by name
+	decoders map[string]*ast.Type // the decoders called, by name
+}
+
+// generate returns the formatted Go source of package pkg for the schema the document
+// describes; header is written as the first line
+func generate(pkg, header string, doc *ast.SchemaDocument) ([]byte, error) {
+	g := &generator{
+		buf:      &bytes.Buffer{},
+		types:    map[string]ast.TypeDefinition{},
+		members:  map[string][]*ast.ObjectDefinition{},
+		names:    map[string]string{},
+		fields:   map[string]*ast.Type{},
+		decoders: map[string]*ast.Type{},
+	}
+	if err := g.load(doc); err != nil {
+		return nil, err
+	}
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+	if err := g.claimNames(); err != nil {
+		return nil, err
+	}
+
+	g.p("%v\n\npackage %v\n\n", header, pkg)
+	g.p("import (\n\"context\"\n\"fmt\"\n\n\"github.com/savaki/graphql\"\n)\n\n")
+	g.writeStore()
+	for _, def := range g.order {
+		switch v := def.(type) {
+		case *ast.EnumDefinition:
+			g.writeEnum(v)
+		case *ast.InputObjectDefinition:
+			name := goName(v.Name)
+			comment := fmt.Sprintf("%v holds the fields of the %v input object", name, v.Name)
+			if text := strings.TrimSpace(v.Description); text != "" {
+				comment = strings.Replace(text, "\n", "\n// ", -1)
+			}
+			g.writeStruct(name, comment, v.Fields)
+		case *ast.InterfaceDefinition:
+			g.writeInterface(v.Name, v.Description, "implement")
+		case *ast.UnionDefinition:
+			g.writeInterface(v.Name, v.Description, "are members of")
+		case *ast.ObjectDefinition:
+			g.writeObject(v)
+		}
+	}
+	g.writeConversions()
+	g.p("%v", helpers)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code => %v", err)
+	}
+	return src, nil
+}
+
+// p writes formatted code; format.Source indents it afterwards
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+// --[ Schema ]-------------------------------------------------------
+
+// load indexes the types of the document, applying its extensions, and finds the root types
+func (g *generator) load(doc *ast.SchemaDocument) error {
+	schema := &ast.SchemaDefinition{}
+	var extensions []*ast.Extension
+
+	for _, definition := range doc.Definitions {
+		switch v := definition.(type) {
+		case *ast.SchemaDefinition:
+			schema = v
+		case *ast.Extension:
+			extensions = append(extensions, v)
+		case *ast.DirectiveDefinition:
+			// directives are applied by the executor, not the store
+		case ast.TypeDefinition:
+			if _, ok := g.types[v.TypeName()]; ok || builtins[v.TypeName()] != "" {
+				return fmt.Errorf("type %v is defined more than once", v.TypeName())
+			}
+			g.types[v.TypeName()] = v
+			g.order = append(g.order, v)
+		}
+	}
+
+	for _, e := range extensions {
+		if err := g.extend(schema, e); err != nil {
+			return err
+		}
+	}
+
+	if schema.Subscription != "" {
+		return fmt.Errorf("subscriptions are not supported")
+	}
+	query, mutation := schema.Query, schema.Mutation
+	if query == "" {
+		query = "Query"
+		if _, ok := g.types["Mutation"]; ok && mutation == "" {
+			mutation = "Mutation"
+		}
+	}
+
+	var ok bool
+	if g.query, ok = g.types[query].(*ast.ObjectDefinition); !ok {
+		return fmt.Errorf("the query root type, %v, is not defined as an object", query)
+	}
+	if mutation != "" {
+		if g.mutation, ok = g.types[mutation].(*ast.ObjectDefinition); !ok {
+			return fmt.Errorf("the mutation root type, %v, is not defined as an object", mutation)
+		}
+	}
+
+	for _, def := range g.order {
+		if o, ok := def.(*ast.ObjectDefinition); ok {
+			for _, name := range o.Interfaces {
+				g.members[name] = append(g.members[name], o)
+			}
+		}
+		if u, ok := def.(*ast.UnionDefinition); ok {
+			for _, name := range u.Types {
+				if o, ok := g.types[name].(*ast.ObjectDefinition); ok {
+					g.members[u.Name] = append(g.members[u.Name], o)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// extend adds the elements of an extension to the schema or type it extends
+func (g *generator) extend(schema *ast.SchemaDefinition, e *ast.Extension) error {
+	if v, ok := e.Definition.(*ast.SchemaDefinition); ok {
+		if v.Query != "" {
+			schema.Query = v.Query
+		}
+		if v.Mutation != "" {
+			schema.Mutation = v.Mutation
+		}
+		if v.Subscription != "" {
+			schema.Subscription = v.Subscription
+		}
+		return nil
+	}
+
+	def, _ := e.Definition.(ast.TypeDefinition)
+	if def == nil {
+		return fmt.Errorf("unable to apply extension at line %v", e.Loc.Line)
+	}
+	extended := g.types[def.TypeName()]
+
+	switch v := def.(type) {
+	case *ast.ScalarDefinition:
+		if _, ok := extended.(*ast.ScalarDefinition); ok {
+			return nil
+		}
+	case *ast.ObjectDefinition:
+		if t, ok := extended.(*ast.ObjectDefinition); ok {
+			t.Interfaces = append(t.Interfaces, v.Interfaces...)
+			t.Fields = append(t.Fields, v.Fields...)
+			return nil
+		}
+	case *ast.InterfaceDefinition:
+		if t, ok := extended.(*ast.InterfaceDefinition); ok {
+			t.Interfaces = append(t.Interfaces, v.Interfaces...)
+			t.Fields = append(t.Fields, v.Fields...)
+			return nil
+		}
+	case *ast.UnionDefinition:
+		if t, ok := extended.(*ast.UnionDefinition); ok {
+			t.Types = append(t.Types, v.Types...)
+			return nil
+		}
+	case *ast.EnumDefinition:
+		if t, ok := extended.(*ast.EnumDefinition); ok {
+			t.Values = append(t.Values, v.Values...)
+			return nil
+		}
+	case *ast.InputObjectDefinition:
+		if t, ok := extended.(*ast.InputObjectDefinition); ok {
+			t.Fields = append(t.Fields, v.Fields...)
+			return nil
+		}
+	}
+	return fmt.Errorf("extension of %v does not extend a type of the same kind", def.TypeName())
+}
+
+// check verifies that each type referred to is defined and may be used where it's referred to
+func (g *generator) check() error {
+	for _, def := range g.order {
+		switch v := def.(type) {
+		case *ast.ObjectDefinition:
+			if err := g.checkFields(v.Name, v.Fields); err != nil {
+				return err
+			}
+			for _, name := range v.Interfaces {
+				if _, ok := g.types[name].(*ast.InterfaceDefinition); !ok {
+					return fmt.Errorf("%v implements %v, which is not an interface", v.Name, name)
+				}
+			}
+
+		case *ast.InterfaceDefinition:
+			if err := g.checkFields(v.Name, v.Fields); err != nil {
+				return err
+			}
+
+		case *ast.UnionDefinition:
+			for _, name := range v.Types {
+				if _, ok := g.types[name].(*ast.ObjectDefinition); !ok {
+					return fmt.Errorf("union %v includes %v, which is not an object", v.Name, name)
+				}
+			}
+
+		case *ast.InputObjectDefinition:
+			if err := g.checkInputValues(v.Name, v.Fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) checkFields(typeName string, fields []*ast.FieldDefinition) error {
+	for _, f := range fields {
+		name := named(f.Type).Name
+		switch t := g.types[name].(type) {
+		case nil:
+			if builtins[name] == "" {
+				return fmt.Errorf("%v.%v is of undefined type, %v", typeName, f.Name, name)
+			}
+		case *ast.InputObjectDefinition:
+			return fmt.Errorf("%v.%v is of input type %v; fields must be of an output type", typeName, f.Name, name)
+		case *ast.ObjectDefinition:
+			if g.isRoot(t) {
+				return fmt.Errorf("%v.%v is of root type %v; root types may not be the type of a field", typeName, f.Name, name)
+			}
+		}
+		if err := g.checkInputValues(typeName+"."+f.Name, f.Args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) checkInputValues(owner string, values []*ast.InputValueDefinition) error {
+	for _, v := range values {
+		name := named(v.Type).Name
+		switch g.types[name].(type) {
+		case nil:
+			if builtins[name] == "" {
+				return fmt.Errorf("%v(%v) is of undefined type, %v", owner, v.Name, name)
+			}
+		case *ast.ObjectDefinition, *ast.InterfaceDefinition, *ast.UnionDefinition:
+			return fmt.Errorf("%v(%v) is of output type %v; arguments and input fields must be of an input type", owner, v.Name, name)
+		}
+	}
+	return nil
+}
+
+// claimNames reserves the exported Go names the generated code declares, reporting any that
+// would be declared twice
+func (g *generator) claimNames() error {
+	claim := func(name, by string) error {
+		if other, ok := g.names[name]; ok {
+			return fmt.Errorf("%v and %v would both be declared as %v", other, by, name)
+		}
+		g.names[name] = by
+		return nil
+	}
+
+	for _, name := range []string{"Resolvers", "NewStore"} {
+		if err := claim(name, "graphql-gen"); err != nil {
+			return err
+		}
+	}
+	for _, def := range g.order {
+		name := def.TypeName()
+		if builtins[name] != "" {
+			continue
+		}
+		if o, ok := def.(*ast.ObjectDefinition); ok && g.isRoot(o) {
+			if err := claim(resolverName(o), name); err != nil {
+				return err
+			}
+		} else if err := claim(goName(name), name); err != nil {
+			return err
+		}
+
+		switch v := def.(type) {
+		case *ast.EnumDefinition:
+			for _, value := range v.Values {
+				if err := claim(enumName(v, value), name+"."+value.Name); err != nil {
+					return err
+				}
+			}
+		case *ast.ObjectDefinition:
+			if !g.isRoot(v) && len(resolvedFields(v)) > 0 {
+				if err := claim(resolverName(v), name); err != nil {
+					return err
+				}
+			}
+			for _, f := range v.Fields {
+				if len(f.Args) > 0 {
+					if err := claim(argsName(v, f), name+"."+f.Name); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) isRoot(o *ast.ObjectDefinition) bool {
+	return o == g.query || o == g.mutation
+}
+
+// resolvedFields returns the fields of an object that are resolved by its resolver; all of
+// those of a root type and those of other types that take arguments
+func (g *generator) resolvedFieldsOf(o *ast.ObjectDefinition) []*ast.FieldDefinition {
+	if g.isRoot(o) {
+		return o.Fields
+	}
+	return resolvedFields(o)
+}
+
+// resolvedFields returns the fields of an object, other than a root type, that take arguments
+func resolvedFields(o *ast.ObjectDefinition) []*ast.FieldDefinition {
+	var fields []*ast.FieldDefinition
+	for _, f := range o.Fields {
+		if len(f.Args) > 0 {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// named returns the named type at the core of a list or non-null type
+func named(t *ast.Type) *ast.Type {
+	for t.IsList() {
+		t = t.Elem
+	}
+	return t
+}
+
+// --[ Declarations ]-------------------------------------------------
+
+// writeStore writes the Resolvers the generated store is built from and the store itself
+func (g *generator) writeStore() {
+	g.p("// Resolvers resolves the fields of the root types, and the fields of other types that take\n")
+	g.p("// arguments; the values of other fields are read from the models returned\n")
+	g.p("type Resolvers struct {\n")
+	for _, def := range g.order {
+		if o, ok := def.(*ast.ObjectDefinition); ok && len(g.resolvedFieldsOf(o)) > 0 {
+			g.p("%v %v\n", goName(o.Name), resolverName(o))
+		}
+	}
+	g.p("}\n\n")
+
+	query := selectionName(g.query)
+	g.p("// NewStore returns a Store that resolves operations with r\n")
+	g.p("func NewStore(r *Resolvers) graphql.Store {\n")
+	g.p("return &store{%v{r: r}}\n}\n\n", query)
+	g.p("// store resolves queries as the query root type does\n")
+	g.p("type store struct {\n%v\n}\n\n", query)
+	g.p("func (s *store) Mutate(c *graphql.Context) (graphql.Field, error) {\n")
+	g.p("return s.MutateContext(context.Background(), c)\n}\n\n")
+	g.p("func (s *store) MutateContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {\n")
+	if g.mutation == nil {
+		g.p("return nil, graphql.ErrNotImplemented\n}\n\n")
+	} else {
+		g.p("return (&%v{r: s.r}).QueryContext(ctx, c)\n}\n\n", selectionName(g.mutation))
+	}
+}
+
+func (g *generator) writeEnum(e *ast.EnumDefinition) {
+	name := goName(e.Name)
+	g.doc(nil, e.Description, "%v enumerates the values of the %v enum", name, e.Name)
+	g.p("type %v string\n\n", name)
+	g.p("const (\n")
+	for _, value := range e.Values {
+		g.doc(value.Directives, value.Description, "")
+		g.p("%v %v = %q\n", enumName(e, value), name, value.Name)
+	}
+	g.p(")\n\n")
+}
+
+// writeInterface writes the Go interface satisfied by the models of the types that implement
+// an interface or are members of a union
+func (g *generator) writeInterface(typeName, description, relation string) {
+	name := goName(typeName)
+	g.doc(nil, description, "%v is implemented by the models of the types that %v %v", name, relation, typeName)
+	g.p("type %v interface {\n%v()\n}\n\n", name, markerName(typeName))
+}
+
+// writeStruct writes the struct an input object, or the arguments of a field, are decoded to
+// and the function that reads it from the values of the fields or arguments
+func (g *generator) writeStruct(name, comment string, fields []*ast.InputValueDefinition) {
+	g.p("// %v\n", comment)
+	g.p("type %v struct {\n", name)
+	for _, f := range fields {
+		g.doc(f.Directives, f.Description, "")
+		g.p("%v %v\n", goName(f.Name), g.goType(f.Type))
+	}
+	g.p("}\n\n")
+
+	g.p("func read%v(values map[string]interface{}) (v %v, err error) {\n", name, name)
+	for _, f := range fields {
+		if f.DefaultValue != nil {
+			g.p("if value, ok := lookup(values, %q, %v); ok {\n", f.Name, literal(f.DefaultValue))
+		} else {
+			g.p("if value, ok := values[%q]; ok {\n", f.Name)
+		}
+		g.p("if v.%v, err = %v(value); err != nil {\n", goName(f.Name), g.decoder(f.Type))
+		g.p("return v, fmt.Errorf(\"invalid value for %v => %%v\", err)\n}\n", f.Name)
+		if f.Type.NonNull && f.DefaultValue == nil {
+			g.p("} else {\nreturn v, fmt.Errorf(\"%v is required\")\n", f.Name)
+		}
+		g.p("}\n")
+	}
+	g.p("return v, nil\n}\n\n")
+}
+
+// writeObject writes the model, resolver interface, argument structs and selection of an
+// object type
+func (g *generator) writeObject(o *ast.ObjectDefinition) {
+	name := goName(o.Name)
+	resolved := g.resolvedFieldsOf(o)
+
+	if !g.isRoot(o) {
+		g.doc(nil, o.Description, "%v is the model of the %v type", name, o.Name)
+		g.p("type %v struct {\n", name)
+		for _, f := range o.Fields {
+			if len(f.Args) == 0 {
+				g.doc(f.Directives, f.Description, "")
+				g.p("%v %v\n", goName(f.Name), g.goType(f.Type))
+			}
+		}
+		g.p("}\n\n")
+
+		for _, i := range o.Interfaces {
+			g.p("func (*%v) %v() {}\n\n", name, markerName(i))
+		}
+		for _, def := range g.order {
+			if u, ok := def.(*ast.UnionDefinition); ok && contains(u.Types, o.Name) {
+				g.p("func (*%v) %v() {}\n\n", name, markerName(u.Name))
+			}
+		}
+	}
+
+	if len(resolved) > 0 {
+		if g.isRoot(o) {
+			g.p("// %v resolves the fields of %v\n", resolverName(o), o.Name)
+		} else {
+			g.p("// %v resolves the fields of %v that take arguments\n", resolverName(o), o.Name)
+		}
+		g.p("type %v interface {\n", resolverName(o))
+		for _, f := range resolved {
+			g.doc(f.Directives, f.Description, "")
+			g.p("%v(ctx context.Context", goName(f.Name))
+			if !g.isRoot(o) {
+				g.p(", obj *%v", name)
+			}
+			if len(f.Args) > 0 {
+				g.p(", args %v", argsName(o, f))
+			}
+			g.p(") (%v, error)\n", g.goType(f.Type))
+		}
+		g.p("}\n\n")
+
+		for _, f := range resolved {
+			if len(f.Args) > 0 {
+				name := argsName(o, f)
+				g.writeStruct(name, fmt.Sprintf("%v holds the arguments of %v.%v", name, o.Name, f.Name), f.Args)
+			}
+		}
+	}
+
+	g.writeSelection(o)
+}
+
+// writeSelection writes the graphql.Selection that resolves the fields of an object
+func (g *generator) writeSelection(o *ast.ObjectDefinition) {
+	name := selectionName(o)
+	root := g.isRoot(o)
+
+	g.p("// %v resolves the fields of %v\n", name, o.Name)
+	g.p("type %v struct {\nr *Resolvers\n", name)
+	if !root {
+		g.p("obj *%v\n", goName(o.Name))
+	}
+	g.p("}\n\n")
+
+	g.p("func (s *%v) Query(c *graphql.Context) (graphql.Field, error) {\n", name)
+	g.p("return s.QueryContext(context.Background(), c)\n}\n\n")
+
+	g.p("func (s *%v) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {\n", name)
+	g.p("switch c.Name {\n")
+	for _, f := range o.Fields {
+		g.p("case %q:\n", f.Name)
+		if !root && len(f.Args) == 0 {
+			g.p("return %v(s.r, s.obj.%v), nil\n", g.field(f.Type), goName(f.Name))
+			continue
+		}
+
+		g.p("if s.r.%v == nil {\nreturn nil, noResolver(%q)\n}\n", goName(o.Name), o.Name)
+		args := []string{"ctx"}
+		if !root {
+			args = append(args, "s.obj")
+		}
+		if len(f.Args) > 0 {
+			g.p("args, err := read%v(argMap(c.Args))\nif err != nil {\nreturn nil, err\n}\n", argsName(o, f))
+			args = append(args, "args")
+		}
+		g.p("v, err := s.r.%v.%v(%v)\n", goName(o.Name), goName(f.Name), strings.Join(args, ", "))
+		g.p("if err != nil {\nreturn nil, err\n}\n")
+		g.p("return %v(s.r, v), nil\n", g.field(f.Type))
+	}
+	g.p("}\nreturn nil, graphql.ErrFieldNotFound\n}\n\n")
+
+	g.p("func (s *%v) TypeName() string {\nreturn %q\n}\n\n", name, o.Name)
+
+	satisfies := append([]string{o.Name}, o.Interfaces...)
+	for _, def := range g.order {
+		if u, ok := def.(*ast.UnionDefinition); ok && contains(u.Types, o.Name) {
+			satisfies = append(satisfies, u.Name)
+		}
+	}
+	g.p("func (s *%v) Satisfies(typeCondition string) bool {\n", name)
+	g.p("switch typeCondition {\ncase %v:\nreturn true\n}\nreturn false\n}\n\n", quote(satisfies))
+
+	var nonNull []string
+	scalars := map[string][]string{}
+	args := map[string][]string{}
+	for _, f := range o.Fields {
+		if f.Type.NonNull {
+			nonNull = append(nonNull, f.Name)
+		}
+		if scalar := g.scalarName(f.Type); scalar != "" {
+			scalars[scalar] = append(scalars[scalar], f.Name)
+		}
+		for _, arg := range f.Args {
+			if scalar := g.scalarName(arg.Type); scalar != "" {
+				args[scalar] = append(args[scalar], f.Name+"."+arg.Name)
+			}
+		}
+	}
+
+	g.p("func (s *%v) NonNullField(name string) bool {\n", name)
+	if len(nonNull) > 0 {
+		g.p("switch name {\ncase %v:\nreturn true\n}\n", quote(nonNull))
+	}
+	g.p("return false\n}\n\n")
+
+	g.p("func (s *%v) ScalarField(name string) string {\n", name)
+	g.writeScalarSwitch("name", scalars)
+	g.p("func (s *%v) ScalarArg(field, arg string) string {\n", name)
+	g.writeScalarSwitch("field + \".\" + arg", args)
+}
+
+// writeScalarSwitch completes a function returning the scalar type named by key
+func (g *generator) writeScalarSwitch(key string, names map[string][]string) {
+	if len(names) > 0 {
+		g.p("switch %v {\n", key)
+		for _, scalar := range sortedKeys(names) {
+			g.p("case %v:\nreturn %q\n", quote(names[scalar]), scalar)
+		}
+		g.p("}\n")
+	}
+	g.p("return \"\"\n}\n\n")
+}
+
+// doc writes a comment holding the description or, if there is none, the default formatted
+// from args.  Elements marked @deprecated are noted as deprecated.
+func (g *generator) doc(directives []*ast.Directive, description, format string, args ...interface{}) {
+	var lines []string
+	if text := strings.TrimSpace(description); text != "" {
+		lines = strings.Split(text, "\n")
+	} else if format != "" {
+		lines = []string{fmt.Sprintf(format, args...)}
+	}
+
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		reason := "No longer supported"
+		for _, arg := range d.Args {
+			if v, ok := arg.Value.(*ast.StringValue); ok && arg.Name == "reason" {
+				reason = v.Value
+			}
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "Deprecated: "+reason)
+	}
+
+	for _, line := range lines {
+		g.p("// %v\n", strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+}
+
+// --[ Conversions ]--------------------------------------------------
+
+// goType returns the Go type that holds values of t.  Nullable values are held by pointers,
+// other than lists, which are nil, and custom scalars, interfaces and unions, which are held
+// by interfaces.  Objects are always held by pointers.
+func (g *generator) goType(t *ast.Type) string {
+	if t.IsList() {
+		return "[]" + g.goType(t.Elem)
+	}
+
+	var typ string
+	switch g.types[t.Name].(type) {
+	case nil:
+		typ = builtins[t.Name]
+	case *ast.ScalarDefinition:
+		return "graphql.Value"
+	case *ast.ObjectDefinition:
+		return "*" + goName(t.Name)
+	case *ast.InterfaceDefinition, *ast.UnionDefinition:
+		return goName(t.Name)
+	default:
+		typ = goName(t.Name)
+	}
+
+	if !t.NonNull {
+		return "*" + typ
+	}
+	return typ
+}
+
+// scalarName returns the name of the scalar type at the core of t; "" if it's not a scalar
+func (g *generator) scalarName(t *ast.Type) string {
+	name := named(t).Name
+	switch g.types[name].(type) {
+	case nil, *ast.ScalarDefinition:
+		return name
+	}
+	return ""
+}
+
+// field returns the name of the function that converts a value of type t to a graphql.Field
+func (g *generator) field(t *ast.Type) string {
+	name := "fieldOf" + mangle(t)
+	g.fields[name] = t
+	return name
+}
+
+// decoder returns the name of the function that converts an input value to type t
+func (g *generator) decoder(t *ast.Type) string {
+	name := "decode" + mangle(t)
+	g.decoders[name] = t
+	return name
+}
+
+// writeConversions writes the conversions called so far, and those they call in turn, in
+// order of name
+func (g *generator) writeConversions() {
+	body := g.buf
+
+	written := map[string][]byte{}
+	for {
+		var pending []string
+		for name := range g.fields {
+			if _, ok := written[name]; !ok {
+				pending = append(pending, name)
+			}
+		}
+		for name := range g.decoders {
+			if _, ok := written[name]; !ok {
+				pending = append(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		for _, name := range pending {
+			g.buf = &bytes.Buffer{}
+			if t, ok := g.fields[name]; ok {
+				g.writeField(name, t)
+			} else {
+				g.writeDecoder(name, g.decoders[name])
+			}
+			written[name] = g.buf.Bytes()
+		}
+	}
+
+	names := make([]string, 0, len(written))
+	for name := range written {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g.buf = body
+	for _, name := range names {
+		g.buf.Write(written[name])
+	}
+}
+
+func (g *generator) writeField(name string, t *ast.Type) {
+	g.p("func %v(r *Resolvers, v %v) graphql.Field {\n", name, g.goType(t))
+	if t.IsList() {
+		g.p("if v == nil {\nreturn nil\n}\n")
+		g.p("elements := make([]graphql.Field, len(v))\n")
+		g.p("for index, element := range v {\nelements[index] = %v(r, element)\n}\n", g.field(t.Elem))
+		g.p("return &listField{elements: elements, nonNull: %v}\n}\n\n", t.Elem.NonNull)
+		return
+	}
+
+	switch def := g.types[t.Name].(type) {
+	case *ast.ScalarDefinition:
+		g.p("return valueField{v}\n")
+
+	case *ast.ObjectDefinition:
+		g.p("if v == nil {\nreturn nil\n}\n")
+		g.p("return objectField{&%v{r: r, obj: v}}\n", selectionName(def))
+
+	case *ast.InterfaceDefinition, *ast.UnionDefinition:
+		if members := g.members[t.Name]; len(members) > 0 {
+			g.p("switch v := v.(type) {\n")
+			for _, o := range members {
+				g.p("case *%v:\nreturn %v(r, v)\n", goName(o.Name), g.field(&ast.Type{Name: o.Name}))
+			}
+			g.p("}\n")
+		}
+		g.p("return nil\n")
+
+	default:
+		if t.NonNull {
+			g.p("return valueField{v}\n")
+		} else {
+			g.p("if v == nil {\nreturn nil\n}\nreturn valueField{*v}\n")
+		}
+	}
+	g.p("}\n\n")
+}
+
+func (g *generator) writeDecoder(name string, t *ast.Type) {
+	typ := g.goType(t)
+	g.p("func %v(value graphql.Value) (v %v, err error) {\n", name, typ)
+	if t.NonNull {
+		g.p("if value == nil {\nreturn v, errNull\n}\n")
+	} else {
+		g.p("if value == nil {\nreturn nil, nil\n}\n")
+		if !t.IsList() && strings.HasPrefix(typ, "*") {
+			// the value is decoded as the non-null type and its address taken
+			g.p("x, err := %v(value)\nif err != nil {\nreturn nil, err\n}\nreturn &x, nil\n}\n\n",
+				g.decoder(&ast.Type{Name: t.Name, NonNull: true}))
+			return
+		}
+	}
+
+	if t.IsList() {
+		g.p("items, ok := value.([]interface{})\nif !ok {\nitems = []interface{}{value}\n}\n")
+		g.p("v = make(%v, len(items))\n", typ)
+		g.p("for index, item := range items {\nif v[index], err = %v(item); err != nil {\nreturn nil, err\n}\n}\n", g.decoder(t.Elem))
+		g.p("return v, nil\n}\n\n")
+		return
+	}
+
+	switch def := g.types[t.Name].(type) {
+	case nil:
+		g.p("%v", decodeBuiltin[t.Name])
+
+	case *ast.ScalarDefinition:
+		g.p("return value, nil\n")
+
+	case *ast.EnumDefinition:
+		names := make([]string, len(def.Values))
+		for index, value := range def.Values {
+			names[index] = value.Name
+		}
+		g.p("switch s, _ := value.(string); s {\ncase %v:\nreturn %v(s), nil\n}\n", quote(names), goName(def.Name))
+		g.p("return v, fmt.Errorf(\"%v is not a valid %v\", value)\n", "%v", def.Name)
+
+	case *ast.InputObjectDefinition:
+		g.p("fields, ok := value.(map[string]interface{})\nif !ok {\n")
+		g.p("return v, fmt.Errorf(\"%v is not a valid %v\", value)\n}\n", "%v", def.Name)
+		g.p("return read%v(fields)\n", goName(def.Name))
+	}
+	g.p("}\n\n")
+}
+
+// mangle names a type for use in the name of a conversion e.g. [Episode!] is OptListOfEpisode
+func mangle(t *ast.Type) string {
+	s := t.Name
+	if t.IsList() {
+		s = "ListOf" + mangle(t.Elem)
+	}
+	if !t.NonNull {
+		s = "Opt" + s
+	}
+	return s
+}
+
+// literal writes a default value as the Go value the executor would pass for it
+func literal(value ast.Value) string {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		return "int64(" + v.Value + ")"
+	case *ast.FloatValue:
+		return "float64(" + v.Value + ")"
+	case *ast.StringValue:
+		return strconv.Quote(v.Value)
+	case *ast.BooleanValue:
+		return strconv.FormatBool(v.Value)
+	case *ast.EnumValue:
+		return strconv.Quote(v.Value)
+	case *ast.ListValue:
+		values := make([]string, len(v.Values))
+		for index, item := range v.Values {
+			values[index] = literal(item)
+		}
+		return "[]interface{}{" + strings.Join(values, ", ") + "}"
+	case *ast.ObjectValue:
+		fields := make([]string, len(v.Fields))
+		for index, f := range v.Fields {
+			fields[index] = strconv.Quote(f.Name) + ": " + literal(f.Value)
+		}
+		return "map[string]interface{}{" + strings.Join(fields, ", ") + "}"
+	default:
+		return "nil"
+	}
+}
+
+// --[ Names ]--------------------------------------------------------
+
+// goName returns the exported Go name for a graphql name; the first letter of each word is
+// capitalized and initialisms are written in capitals, so appearsIn becomes AppearsIn and
+// userId becomes UserID
+func goName(name string) string {
+	var words []string
+	word := []rune{}
+	for _, r := range name {
+		switch {
+		case r == '_':
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = []rune{}
+			continue
+		case unicode.IsUpper(r) && len(word) > 0 && unicode.IsLower(word[len(word)-1]):
+			words = append(words, string(word))
+			word = []rune{}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	for index, w := range words {
+		if initialisms[strings.ToUpper(w)] {
+			words[index] = strings.ToUpper(w)
+			continue
+		}
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[index] = string(runes)
+	}
+	return strings.Join(words, "")
+}
+
+// enumName returns the name of the constant for an enum value e.g. EpisodeNewHope for
+// Episode.NEW_HOPE
+func enumName(e *ast.EnumDefinition, value *ast.EnumValueDefinition) string {
+	name := value.Name
+	if strings.ToUpper(name) == name {
+		name = strings.ToLower(name)
+	}
+	return goName(e.Name) + goName(name)
+}
+
+func resolverName(o *ast.ObjectDefinition) string {
+	return goName(o.Name) + "Resolver"
+}
+
+func argsName(o *ast.ObjectDefinition, f *ast.FieldDefinition) string {
+	return goName(o.Name) + goName(f.Name) + "Args"
+}
+
+func selectionName(o *ast.ObjectDefinition) string {
+	return unexported(goName(o.Name)) + "Selection"
+}
+
+// markerName returns the method that marks a model as a member of an interface or union
+func markerName(name string) string {
+	return "is" + goName(name)
+}
+
+func unexported(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// quote returns the names as a list of case expressions
+func quote(names []string) string {
+	quoted := make([]string, len(names))
+	for index, name := range names {
+		quoted[index] = strconv.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// --[ Helpers ]------------------------------------------------------
+
+// helpers are written at the end of each generated file
+const helpers = `// valueField holds the value of a scalar or enum
+type valueField struct {
+	value graphql.Value
+}
+
+func (f valueField) Selection() (graphql.Selection, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+func (f valueField) Value() (graphql.Value, error) {
+	return f.value, nil
+}
+
+// objectField holds an object
+type objectField struct {
+	selection graphql.Selection
+}
+
+func (f objectField) Selection() (graphql.Selection, error) {
+	return f.selection, nil
+}
+
+func (f objectField) Value() (graphql.Value, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+// listField holds a list; its value is the list of the values of its elements
+type listField struct {
+	elements []graphql.Field
+	nonNull  bool
+}
+
+func (f *listField) Selection() (graphql.Selection, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+func (f *listField) Value() (graphql.Value, error) {
+	values := make([]interface{}, len(f.elements))
+	for index, element := range f.elements {
+		if element == nil {
+			continue
+		}
+		value, err := element.Value()
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
+	}
+	return values, nil
+}
+
+func (f *listField) Elements() ([]graphql.Field, error) {
+	return f.elements, nil
+}
+
+func (f *listField) NonNullElements() bool {
+	return f.nonNull
+}
+
+var errNull = fmt.Errorf("expected a non-null value")
+
+func noResolver(typeName string) error {
+	return fmt.Errorf("no resolver was given for %v", typeName)
+}
+
+// argMap returns the arguments of a field by name
+func argMap(args []graphql.Arg) map[string]interface{} {
+	values := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		values[arg.Name] = arg.Value
+	}
+	return values
+}
+
+// lookup returns the named value, or the default value if there isn't one
+func lookup(values map[string]interface{}, name string, defaultValue interface{}) (interface{}, bool) {
+	if value, ok := values[name]; ok {
+		return value, true
+	}
+	return defaultValue, true
+}
+`
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/savaki/graphql/ast"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func generateSchema(sdl string) (string, error) {
+	doc, err := ast.ParseSchema(sdl)
+	if err != nil {
+		return "", err
+	}
+	src, err := generate("example", "// generated by graphql-gen starwars.graphql; DO NOT EDIT", doc)
+	return string(src), err
+}
+
+func TestGenerate(t *testing.T) {
+	Convey("Given the schema of the example", t, func() {
+		sdl, err := ioutil.ReadFile("example/starwars.graphql")
+		So(err, ShouldBeNil)
+
+		Convey("When I generate code for it", func() {
+			src, err := generateSchema(string(sdl))
+			So(err, ShouldBeNil)
+
+			Convey("Then it should match the code go generate wrote", func() {
+				expected, err := ioutil.ReadFile("example/starwars_graphql.go")
+				So(err, ShouldBeNil)
+				So(src, ShouldEqual, string(expected))
+			})
+		})
+	})
+
+	Convey("Given a schema with extensions and deprecated fields", t, func() {
+		src, err := generateSchema(`
+schema { query: Root }
+type Root { user(id: ID!): User }
+type User {
+  id: ID!
+  login: String @deprecated(reason: "Use name.")
+}
+extend type User { name: String! avatarUrl(size: Int = 64): String }
+`)
+		So(err, ShouldBeNil)
+
+		Convey("Then the root should be named by the schema definition", func() {
+			So(src, ShouldContainSubstring, "type RootResolver interface {\n\tUser(ctx context.Context, args RootUserArgs) (*User, error)\n}")
+			So(src, ShouldContainSubstring, "return &store{rootSelection{r: r}}")
+			So(src, ShouldContainSubstring, "return nil, graphql.ErrNotImplemented")
+		})
+
+		Convey("Then extensions should be applied to the type they extend", func() {
+			So(src, ShouldContainSubstring, "type User struct {\n\tID string\n\t// Deprecated: Use name.\n\tLogin *string\n\tName  string\n}")
+			So(src, ShouldContainSubstring, "AvatarURL(ctx context.Context, obj *User, args UserAvatarURLArgs) (*string, error)")
+			So(src, ShouldContainSubstring, `lookup(values, "size", int64(64))`)
+		})
+	})
+
+	Convey("Given schemas that can't be generated", t, func() {
+		testCases := map[string]string{
+			"no query root":        `type Root { a: Int }`,
+			"undefined type":       `type Query { a: Missing }`,
+			"input as output":      `input In { a: Int } type Query { a: In }`,
+			"output as input":      `type Query { a(b: Query): Int }`,
+			"root as field type":   `type Query { a: Query }`,
+			"non interface":        `type Other { a: Int } type Query implements Other { a: Int }`,
+			"union of non objects": `enum E { A } union U = E type Query { a: U }`,
+			"subscriptions":        `schema { query: Query subscription: Query } type Query { a: Int }`,
+			"duplicate types":      `type Query { a: Int } type Query { b: Int }`,
+			"name collision":       `enum Color { RED } type ColorRed { a: Int } type Query { a: Color }`,
+			"extension mismatch":   `type Query { a: Int } extend input Query { b: Int }`,
+		}
+
+		for label, sdl := range testCases {
+			Convey("Then an error should be reported for "+label, func() {
+				_, err := generateSchema(sdl)
+				So(err, ShouldNotBeNil)
+			})
+		}
+	})
+}
+
+func TestGoName(t *testing.T) {
+	Convey("Given graphql names", t, func() {
+		testCases := map[string]string{
+			"id":          "ID",
+			"name":        "Name",
+			"appearsIn":   "AppearsIn",
+			"userId":      "UserID",
+			"avatarUrl":   "AvatarURL",
+			"HTTPRequest": "HTTPRequest",
+			"new_hope":    "NewHope",
+			"Episode":     "Episode",
+		}
+
+		for name, expected := range testCases {
+			Convey("Then "+name+" should become "+expected, func() {
+				So(goName(name), ShouldEqual, expected)
+			})
+		}
+	})
+
+	Convey("Given enum values", t, func() {
+		e := &ast.EnumDefinition{Name: "Episode"}
+
+		Convey("Then their constants should be named after the enum", func() {
+			So(enumName(e, &ast.EnumValueDefinition{Name: "NEWHOPE"}), ShouldEqual, "EpisodeNewhope")
+			So(enumName(e, &ast.EnumValueDefinition{Name: "NEW_HOPE"}), ShouldEqual, "EpisodeNewHope")
+			So(enumName(e, &ast.EnumValueDefinition{Name: "newHope"}), ShouldEqual, "EpisodeNewHope")
+		})
+	})
+}
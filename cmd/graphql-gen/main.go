@@ -0,0 +1,106 @@
+// Command graphql-gen generates the Go code that serves a schema, written in schema
+// definition language, through the graphql package.  It's intended to be run by go generate
+// e.g.
+//
+//	//go:generate go run github.com/savaki/graphql/cmd/graphql-gen starwars.graphql
+//
+// writes starwars_graphql.go into the package, which is named by $GOPACKAGE as go generate
+// sets it or by the -package flag.  Several schema files may be given; they're read as one
+// schema.  For a schema whose root types are Query and Mutation the generated code holds
+//
+//   - a model struct for each object type, other than the root types, with a field for each
+//     of the type's fields that takes no arguments
+//   - a type for each enum, a string type with a constant for each value, and a struct for
+//     each input object
+//   - an interface for each interface and union, satisfied by the models of its members
+//   - QueryResolver and MutationResolver, which resolve the root fields, and a resolver
+//     interface for each other object type whose fields take arguments e.g. HumanResolver
+//   - a struct holding the arguments of each field that takes them e.g. QueryHeroArgs
+//   - Resolvers, which holds the resolver of each type, and NewStore, which returns a
+//     graphql.Store that resolves operations with them
+//
+// Values are held by Go types as follows; nullable values are held by pointers, other than
+// lists, which are nil slices, and the values held by interfaces.  Objects are always held
+// by pointers.
+//
+//	Int                 int
+//	Float               float64
+//	String, ID          string
+//	Boolean             bool
+//	custom scalars      graphql.Value
+//	enums               the enum's string type
+//	objects             a pointer to the object's model
+//	interfaces, unions  the interface the models of their members satisfy
+//	input objects       the input object's struct
+//	lists               a slice of the element type
+//
+// The store reports the types of its fields through graphql.NonNullFields and
+// graphql.ScalarTypes, so values are checked and coerced as the schema declares them.
+// Arguments are decoded into their structs, with their default values applied, before the
+// resolver is called.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/savaki/graphql/ast"
+)
+
+func main() {
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "name of the package to generate; defaults to $GOPACKAGE")
+	output := flag.String("o", "", "file to write; defaults to the name of the first schema file with _graphql.go in place of its extension")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: graphql-gen [flags] schema.graphql...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*pkg, *output, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "graphql-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the schema files and writes the code generated for them to output
+func run(pkg, output string, filenames []string) error {
+	doc := &ast.SchemaDocument{}
+	for _, filename := range filenames {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		d, err := ast.ParseSchema(string(data))
+		if err != nil {
+			return fmt.Errorf("%v: %v", filename, err)
+		}
+		doc.Definitions = append(doc.Definitions, d.Definitions...)
+	}
+
+	header := "// generated by graphql-gen " + strings.Join(os.Args[1:], " ") + "; DO NOT EDIT"
+	src, err := generate(pkg, header, doc)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = outputName(filenames[0])
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
+
+// outputName returns the name of the file written for a schema file e.g. starwars_graphql.go
+// for starwars.graphql
+func outputName(filename string) string {
+	base := filepath.Base(filename)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_graphql.go"
+}
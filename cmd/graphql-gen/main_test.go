@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRun(t *testing.T) {
+	Convey("Given schema files", t, func() {
+		dir, err := ioutil.TempDir("", "graphql-gen")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		query := filepath.Join(dir, "query.graphql")
+		types := filepath.Join(dir, "types.graphql")
+		So(ioutil.WriteFile(query, []byte(`type Query { user: User }`), 0644), ShouldBeNil)
+		So(ioutil.WriteFile(types, []byte(`type User { name: String! }`), 0644), ShouldBeNil)
+
+		Convey("When I run the command on them", func() {
+			output := filepath.Join(dir, "out.go")
+			err := run("users", output, []string{query, types})
+			So(err, ShouldBeNil)
+
+			Convey("Then the code for both should be written", func() {
+				data, err := ioutil.ReadFile(output)
+				So(err, ShouldBeNil)
+				So(string(data), ShouldContainSubstring, "package users")
+				So(string(data), ShouldContainSubstring, "User(ctx context.Context) (*User, error)")
+				So(string(data), ShouldContainSubstring, "type User struct {\n\tName string\n}")
+			})
+		})
+
+		Convey("When one of them is invalid", func() {
+			So(ioutil.WriteFile(types, []byte(`type User {`), 0644), ShouldBeNil)
+			err := run("users", filepath.Join(dir, "out.go"), []string{query, types})
+
+			Convey("Then the file should be named in the error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, types)
+			})
+		})
+	})
+
+	Convey("Given the name of a schema file", t, func() {
+		Convey("Then the code should be written to a file named after it", func() {
+			So(outputName("schema/starwars.graphql"), ShouldEqual, "starwars_graphql.go")
+			So(outputName("schema.gql"), ShouldEqual, "schema_graphql.go")
+		})
+	})
+}
@@ -1,14 +1,59 @@
 package graphql
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"reflect"
+	"strconv"
+	"sync"
 
 	"github.com/savaki/graphql/ast"
 )
 
 type Executor struct {
 	Store Store
+
+	// Validator optionally checks each document before it's executed; a document that fails
+	// validation is rejected before anything is written
+	Validator Validator
+
+	// Concurrency is the number of fields that may be resolved at once.  Sibling fields are
+	// resolved concurrently, up to this limit, and written in document order; the root fields
+	// of a mutation are always resolved one after another.  Zero or one resolves each field in
+	// turn.
+	Concurrency int
+
+	directives map[string]DirectiveFunc
+	scalars    map[string]*Scalar
+}
+
+// Directive registers a custom field directive.  @skip and @include are built in and may not
+// be replaced; Directive panics if asked to.  Directives that have not been registered are
+// ignored.
+func (e *Executor) Directive(name string, fn DirectiveFunc) {
+	if name == "skip" || name == "include" {
+		panic("graphql: @" + name + " is built in and may not be replaced")
+	}
+	if e.directives == nil {
+		e.directives = map[string]DirectiveFunc{}
+	}
+	e.directives[name] = fn
+}
+
+// Scalar registers a custom scalar type.  Variables declared with the type, and the fields and
+// arguments a Selection reports to be of the type through ScalarTypes, are converted by it.
+// The spec's scalars are built in and may not be replaced; Scalar panics if asked to.
+func (e *Executor) Scalar(scalar *Scalar) {
+	if _, ok := builtinScalars[scalar.Name]; ok {
+		panic("graphql: " + scalar.Name + " is built in and may not be replaced")
+	}
+	if e.scalars == nil {
+		e.scalars = map[string]*Scalar{}
+	}
+	e.scalars[scalar.Name] = scalar
 }
 
 func New(store Store) Executor {
@@ -18,115 +63,1059 @@ func New(store Store) Executor {
 }
 
 func (e Executor) Handle(query string, w io.Writer) error {
-	doc, err := ast.Parse(string(query))
+	return e.HandleRequest(query, "", nil, w)
+}
+
+// HandleRequest executes query using the variables provided and writes the response, an
+// object holding the data and any errors, to w.  A document may contain several operations
+// in which case operationName selects the one executed; see ast.Document.Operation for how
+// legacy operations are named.  If the response holds errors, they are returned as Errors
+// once the response has been written.
+func (e Executor) HandleRequest(query, operationName string, variables map[string]interface{}, w io.Writer) error {
+	return e.HandleContext(context.Background(), query, operationName, variables, w)
+}
+
+// HandleContext executes query as HandleRequest does within ctx.  ctx is handed to the stores
+// and fields that implement QueryContext and its kin; once ctx is done, the fields that have
+// yet to be resolved are abandoned and ctx.Err() is reported in their place.
+func (e Executor) HandleContext(ctx context.Context, query, operationName string, variables map[string]interface{}, w io.Writer) error {
+	return writeResponse(w, e.ExecuteContext(ctx, query, operationName, variables))
+}
+
+// Execute executes query as HandleRequest does but returns the response rather than writing
+// it.  Transports use the response to decide how to reply e.g. which status code to send.
+func (e Executor) Execute(query, operationName string, variables map[string]interface{}) *Response {
+	return e.ExecuteContext(context.Background(), query, operationName, variables)
+}
+
+// ExecuteContext executes query within ctx as HandleContext does and returns the response
+func (e Executor) ExecuteContext(ctx context.Context, query, operationName string, variables map[string]interface{}) *Response {
+	doc, err := ast.Parse(query)
+	if err != nil {
+		return &Response{Errors: toErrors(err)}
+	}
+
+	if e.Validator != nil {
+		if err := e.Validator.Validate(doc); err != nil {
+			return &Response{Errors: toErrors(err)}
+		}
+	}
+
+	x := &execution{
+		loaders:       &loaders{loaders: map[interface{}]*Loader{}},
+		doc:           doc,
+		operationName: operationName,
+		variables:     variables,
+		directives:    e.directives,
+		scalars:       e.scalars,
+	}
+	if e.Concurrency > 1 {
+		// the goroutine executing the request counts as one of the workers
+		x.workers = make(chan struct{}, e.Concurrency-1)
+	}
+	x.ctx = context.WithValue(ctx, loadersKey{}, x.loaders)
+	return x.execute(e.Store)
+}
+
+// execution holds the state of a single document being executed
+type execution struct {
+	ctx           context.Context
+	loaders       *loaders
+	doc           *ast.Document
+	operationName string
+	variables     map[string]interface{}
+	directives    map[string]DirectiveFunc
+	scalars       map[string]*Scalar
+
+	// operation and vars hold the operation being executed and its coerced variable values
+	operation *ast.Operation
+	vars      map[string]interface{}
+
+	// workers holds a token for each field being resolved on a goroutine of its own; it's nil
+	// when fields are resolved one at a time
+	workers chan struct{}
+
+	// errors collects the field errors raised during execution
+	mu     sync.Mutex
+	errors Errors
+}
+
+// execute executes the document and returns the response.  Errors that prevent execution
+// from starting produce a response without data; errors raised by fields are collected and
+// the fields set to null.
+func (x *execution) execute(store Store) *Response {
+	data, err := x.executeDocument(store)
+	if err != nil {
+		return &Response{Errors: append(x.errors, toErrors(err)...)}
+	}
+	if data == nil {
+		return &Response{Executed: true, Errors: x.errors}
+	}
+	return &Response{Data: data, Executed: true, Errors: x.errors}
+}
+
+// executeDocument executes the requested operation and returns its result or nil if a field
+// error propagated to the root
+func (x *execution) executeDocument(store Store) (*resultMap, error) {
+	if err := checkFragmentCycles(x.doc); err != nil {
+		return nil, err
+	}
+
+	op, err := x.selectOperation()
+	if err != nil {
+		return nil, err
+	}
+	return x.executeOperation(store, op)
+}
+
+// selectOperation returns the operation named by the request.  The name may only be omitted
+// when the document contains a single operation.
+func (x *execution) selectOperation() (*ast.Operation, error) {
+	if x.operationName != "" {
+		op := x.doc.Operation(x.operationName)
+		if op == nil {
+			return nil, ErrUnknownOperation
+		}
+		return op, nil
+	}
+
+	switch len(x.doc.Operations) {
+	case 0:
+		return nil, ErrNoOperation
+	case 1:
+		return x.doc.Operations[0], nil
+	default:
+		return nil, ErrOperationNameRequired
+	}
+}
+
+// executeOperation resolves the root fields of an operation; the result is nil if a field
+// error propagated to the root
+func (x *execution) executeOperation(store Store, qOp *ast.Operation) (*resultMap, error) {
+	vars, err := x.coerceVariables(qOp)
+	if err != nil {
+		return nil, err
+	}
+	x.operation = qOp
+	x.vars = vars
+
+	// mutations resolve their root fields through Store.Mutate, one after another as the spec
+	// requires; the selections of the mutation results are executed like those of a query
+	var result *resultMap
+	if qOp.Type == ast.OpMutation {
+		result, err = x.executeSerially(mutation{store: store}, qOp.SelectionSet)
+	} else {
+		result, err = x.executeSelection(store, qOp.SelectionSet, nil)
+	}
+	if err != nil {
+		if err != errNullChild {
+			x.fail(err, nil, nil)
+		}
+		return nil, nil
+	}
+	return result, nil
+}
+
+// executeSelection resolves the fields of a selection set.  It returns errNullChild if a
+// non-null field was null, in which case the object as a whole is null.
+func (x *execution) executeSelection(selection Selection, qSelector *ast.Selection, path []interface{}) (*resultMap, error) {
+	o, err := x.resolveObject(selection, qSelector, path)
+	if err != nil {
+		return nil, err
+	}
+	x.completeObjects([]*object{o})
+	return o.result, o.err
+}
+
+// executeSerially executes the fields of a selection set one after another; each field and
+// its selection is complete before the next field is resolved
+func (x *execution) executeSerially(selection Selection, qSelector *ast.Selection) (*resultMap, error) {
+	qFields, err := x.collectFields(selection, qSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &resultMap{}
+	for _, qField := range qFields {
+		o := x.resolveFields(selection, []*ast.Field{qField}, nil)
+		x.completeObjects([]*object{o})
+		if o.err != nil {
+			return nil, o.err
+		}
+		result.add(qField.Key(), o.result.values[0])
+	}
+	return result, nil
+}
+
+// object is an object whose fields are being executed.  Objects are executed a level at a
+// time.  The fields of every object at one depth are resolved, the loaders of the request
+// are flushed, and the values of the fields completed; completing a field that holds an
+// object resolves the fields of an object at the next depth.  The keys asked of a Loader by
+// the fields of a level are so loaded in a single batch.
+type object struct {
+	selection Selection
+	qFields   []*ast.Field
+	path      []interface{}
+
+	// fields and values hold the resolved fields and their values in the order of qFields
+	fields []Field
+	values []*value
+
+	// result and err hold the object once the values of its fields are complete
+	result *resultMap
+	err    error
+}
+
+// value holds the value of a field, or of an element of a list, as it's completed.  The
+// value of a leaf is known as soon as its field is resolved; that of an object once the
+// level below has been executed.
+type value struct {
+	qField *ast.Field
+	path   []interface{}
+	scalar *Scalar
+	err    error
+
+	leaf     interface{}
+	object   *object
+	list     bool
+	elements []*value
+	nonNull  bool
+}
+
+// resolveObject collects the fields of a selection set and resolves them
+func (x *execution) resolveObject(selection Selection, qSelector *ast.Selection, path []interface{}) (*object, error) {
+	qFields, err := x.collectFields(selection, qSelector)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return x.resolveFields(selection, qFields, path), nil
+}
+
+// resolveFields resolves the fields of an object.  Errors are held with the value of the
+// field that raised them until the object is completed.
+func (x *execution) resolveFields(selection Selection, qFields []*ast.Field, path []interface{}) *object {
+	o := &object{
+		selection: selection,
+		qFields:   qFields,
+		path:      path,
+		fields:    make([]Field, len(qFields)),
+		values:    make([]*value, len(qFields)),
+	}
+	x.each(len(qFields), func(index int) {
+		qField := qFields[index]
+		v := &value{qField: qField, path: appendPath(path, qField.Key())}
+		if types, ok := selection.(ScalarTypes); ok {
+			v.scalar = x.scalar(types.ScalarField(qField.Name))
+		}
+		o.values[index] = v
 
-	return writeDocument(w, e.Store, doc)
+		if v.err = x.ctx.Err(); v.err != nil {
+			return
+		}
+		o.fields[index], v.err = x.resolveField(selection, qField)
+	})
+	return o
 }
 
-func writeDocument(w io.Writer, store Store, doc *ast.Document) error {
-	if !doc.HasDefaultQueryOnly() {
-		io.WriteString(w, "{")
+func (x *execution) resolveField(selection Selection, qField *ast.Field) (Field, error) {
+	var scalarArg func(string) *Scalar
+	if types, ok := selection.(ScalarTypes); ok {
+		scalarArg = func(arg string) *Scalar {
+			return x.scalar(types.ScalarArg(qField.Name, arg))
+		}
+	}
+
+	args, err := x.args(qField.Args, scalarArg)
+	if err != nil {
+		return nil, err
+	}
+	c := &Context{Name: qField.Name, Args: args}
+	return x.resolve(selection, c, qField)
+}
+
+// completeObjects completes the values of the fields of a level of objects, executing the
+// level below along the way, and then assembles the result of each object
+func (x *execution) completeObjects(objects []*object) {
+	x.flush()
+
+	type pending struct {
+		field Field
+		value *value
 	}
-	for index, ops := range doc.Operations {
-		err := writeOperation(w, store, ops)
+	var fields []pending
+	for _, o := range objects {
+		for index, field := range o.fields {
+			if o.values[index].err == nil {
+				fields = append(fields, pending{field: field, value: o.values[index]})
+			}
+		}
+	}
+
+	below := make([][]*object, len(fields))
+	x.each(len(fields), func(index int) {
+		below[index] = x.completeValue(fields[index].field, fields[index].value)
+	})
+
+	var next []*object
+	for _, objects := range below {
+		next = append(next, objects...)
+	}
+	if len(next) > 0 {
+		x.completeObjects(next)
+	}
+
+	for _, o := range objects {
+		o.result, o.err = x.assemble(o)
+	}
+}
+
+// completeValue fills in the value of a leaf field, resolves the fields of a field holding
+// an object, or does either for each element of a field holding a list.  It returns the
+// objects whose fields were resolved; they make up part of the next level.
+func (x *execution) completeValue(field Field, v *value) []*object {
+	field, err := force(field)
+	if err != nil || field == nil {
+		v.err = err
+		return nil
+	}
+
+	if v.qField.IsScalar() {
+		value, err := fieldValue(x.ctx, field)
+		if err != nil || value == nil {
+			v.err = err
+			return nil
+		}
+		if value, err = serialize(v.scalar, value); err != nil {
+			v.err = err
+			return nil
+		}
+		data, err := json.Marshal(value)
 		if err != nil {
-			return err
+			v.err = err
+			return nil
 		}
-		if index < len(doc.Operations)-1 {
-			io.WriteString(w, ",")
+		v.leaf = json.RawMessage(data)
+		return nil
+	}
+
+	if list, ok := field.(List); ok {
+		elements, err := listElements(x.ctx, list)
+		if err != nil || elements == nil {
+			v.err = err
+			return nil
 		}
+
+		v.list = true
+		if ne, ok := list.(NonNullElements); ok {
+			v.nonNull = ne.NonNullElements()
+		}
+		v.elements = make([]*value, len(elements))
+
+		var next []*object
+		for index, element := range elements {
+			ev := &value{qField: v.qField, path: appendPath(v.path, index), scalar: v.scalar}
+			v.elements[index] = ev
+			next = append(next, x.completeValue(element, ev)...)
+		}
+		return next
 	}
-	if !doc.HasDefaultQueryOnly() {
-		io.WriteString(w, "}")
+
+	selection, err := fieldSelection(x.ctx, field)
+	if err != nil || selection == nil {
+		v.err = err
+		return nil
 	}
+	o, err := x.resolveObject(selection, v.qField.Selection, v.path)
+	if err != nil {
+		v.err = err
+		return nil
+	}
+	v.object = o
+	return []*object{o}
+}
 
-	return nil
+// assemble writes the values of the fields of an object into its result.  A field that
+// failed is recorded and set to null; if the field is non-null, errNullChild is returned so
+// that the object is null instead, as section 6.4.4 of the spec describes.  Once the
+// request's context is done, its error is returned to abandon the rest of the operation.
+func (x *execution) assemble(o *object) (*resultMap, error) {
+	result := &resultMap{}
+	for index, qField := range o.qFields {
+		v := o.values[index]
+		value, err := x.assembleValue(v)
+		if err != nil {
+			if x.done(err) {
+				return nil, err
+			}
+			if err != errNullChild {
+				x.fail(err, qField, v.path)
+			}
+			if fields, ok := o.selection.(NonNullFields); ok && fields.NonNullField(qField.Name) {
+				return nil, errNullChild
+			}
+			value = nil
+		}
+		result.add(qField.Key(), value)
+	}
+	return result, nil
+}
+
+func (x *execution) assembleValue(v *value) (interface{}, error) {
+	switch {
+	case v.err != nil:
+		return nil, v.err
+
+	case v.object != nil:
+		if v.object.err != nil {
+			return nil, v.object.err
+		}
+		return v.object.result, nil
+
+	case v.list:
+		values := make([]interface{}, len(v.elements))
+		for index, element := range v.elements {
+			value, err := x.assembleValue(element)
+			if err != nil {
+				if x.done(err) {
+					return nil, err
+				}
+				if err != errNullChild {
+					x.fail(err, v.qField, element.path)
+				}
+				if v.nonNull {
+					return nil, errNullChild
+				}
+			}
+			values[index] = value
+		}
+		return values, nil
+
+	default:
+		return v.leaf, nil
+	}
 }
 
-func writeOperation(w io.Writer, store Store, qOp *ast.Operation) error {
-	if qOp.Field.Name == "" {
-		return writeSelection(w, store, qOp.Field.Selection)
+// each calls fn with each index from 0 to n.  Calls are handed to goroutines of their own
+// while workers are free and made by the caller otherwise, so execution always progresses
+// however deeply the calls nest.  Without workers, the calls are made in order.
+func (x *execution) each(n int, fn func(index int)) {
+	if x.workers == nil || n < 2 {
+		for index := 0; index < n; index++ {
+			fn(index)
+		}
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	for index := 0; index < n; index++ {
+		select {
+		case x.workers <- struct{}{}:
+			wg.Add(1)
+			go func(index int) {
+				defer wg.Done()
+				defer func() { <-x.workers }()
+				fn(index)
+			}(index)
+		default:
+			fn(index)
+		}
+	}
+	wg.Wait()
+}
+
+// fail records a field error along with the location of the field and its path
+func (x *execution) fail(err error, qField *ast.Field, path []interface{}) {
+	e := &Error{Message: err.Error(), Path: path}
+	if v, ok := err.(*Error); ok {
+		e.Message = v.Message
+	}
+	if qField != nil {
+		e.Locations = []ast.Loc{qField.Loc}
 	}
 
-	io.WriteString(w, `"`)
-	io.WriteString(w, qOp.Field.Key())
-	io.WriteString(w, `":`)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.errors = append(x.errors, e)
+}
+
+// appendPath returns a copy of path with elem appended so that paths may be shared safely
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	p := make([]interface{}, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, elem)
+}
 
-	args := make([]Arg, len(qOp.Field.Args))
-	for index, arg := range qOp.Field.Args {
-		args[index] = Arg{
+// resolve queries the selection for a field, running any custom directives the field is
+// annotated with around the query
+func (x *execution) resolve(selection Selection, c *Context, qField *ast.Field) (Field, error) {
+	next := Resolver(func(c *Context) (Field, error) {
+		return resolveQuery(x.ctx, selection, c)
+	})
+	if qField.Name == typeNameField {
+		if typed, ok := selection.(Typed); ok && typed.TypeName() != "" {
+			next = func(*Context) (Field, error) { return typeName(typed.TypeName()), nil }
+		}
+	}
+	for i := len(qField.Directives) - 1; i >= 0; i-- {
+		directive := qField.Directives[i]
+		fn, ok := x.directives[directive.Name]
+		if !ok {
+			continue
+		}
+		args, err := x.args(directive.Args, nil)
+		if err != nil {
+			return nil, err
+		}
+		inner := next
+		next = func(c *Context) (Field, error) {
+			return fn(c, args, inner)
+		}
+	}
+	return next(c)
+}
+
+// mutation exposes Store.Mutate as a Selection so the root fields of a mutation are resolved
+// in the same way as those of a query
+type mutation struct {
+	store Store
+}
+
+func (m mutation) Query(c *Context) (Field, error) {
+	return m.store.Mutate(c)
+}
+
+func (m mutation) QueryContext(ctx context.Context, c *Context) (Field, error) {
+	if v, ok := m.store.(MutateContext); ok {
+		return v.MutateContext(ctx, c)
+	}
+	return m.store.Mutate(c)
+}
+
+// --[ context.Context ]----------------------------------------------
+
+// flush loads the keys queued with the loaders of the request
+func (x *execution) flush() {
+	loaders := x.loaders.all()
+	x.each(len(loaders), func(index int) {
+		loaders[index].Flush()
+	})
+}
+
+// done reports whether err is the error of the request's context, which is done
+func (x *execution) done(err error) bool {
+	return err != nil && err == x.ctx.Err()
+}
+
+// resolveQuery, fieldSelection, fieldValue and listElements adapt stores written without a context
+// to the executor; the context is passed along when the context aware method is implemented
+func resolveQuery(ctx context.Context, q Query, c *Context) (Field, error) {
+	if v, ok := q.(QueryContext); ok {
+		return v.QueryContext(ctx, c)
+	}
+	return q.Query(c)
+}
+
+func fieldSelection(ctx context.Context, f Field) (Selection, error) {
+	if v, ok := f.(FieldContext); ok {
+		return v.SelectionContext(ctx)
+	}
+	return f.Selection()
+}
+
+func fieldValue(ctx context.Context, f Field) (Value, error) {
+	if v, ok := f.(FieldContext); ok {
+		return v.ValueContext(ctx)
+	}
+	return f.Value()
+}
+
+func listElements(ctx context.Context, l List) ([]Field, error) {
+	if v, ok := l.(ListContext); ok {
+		return v.ElementsContext(ctx)
+	}
+	return l.Elements()
+}
+
+// args converts query arguments into the Args handed to the store, substituting the value
+// of any variable referenced.  Arguments whose variable was not provided are omitted.
+// scalarArg, if given, returns the scalar type of an argument, which then parses its value.
+func (x *execution) args(qArgs []*ast.Arg, scalarArg func(name string) *Scalar) ([]Arg, error) {
+	args := make([]Arg, 0, len(qArgs))
+	for _, arg := range qArgs {
+		if v, ok := arg.Value.(*ast.Variable); ok {
+			if x.operation.Variable(v.Name) == nil {
+				return nil, fmt.Errorf("undefined variable, $%v", v.Name)
+			}
+			if _, ok := x.vars[v.Name]; !ok {
+				continue
+			}
+		}
+
+		var scalar *Scalar
+		if scalarArg != nil {
+			scalar = scalarArg(arg.Name)
+		}
+
+		var value interface{}
+		var err error
+		if scalar != nil {
+			value, err = x.parseLiteral(scalar, arg.Value)
+		} else {
+			value, err = x.valueOf(arg.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, Arg{
 			Name:  arg.Name,
-			Value: arg.Value,
+			Value: value,
+		})
+	}
+	return args, nil
+}
+
+// valueOf converts an input value from the query into its go equivalent; int64, float64,
+// string, bool, nil, []interface{} or map[string]interface{}.  Enum values are returned as
+// their name.
+func (x *execution) valueOf(value ast.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case *ast.Variable:
+		if x.operation == nil || x.operation.Variable(v.Name) == nil {
+			return nil, fmt.Errorf("undefined variable, $%v", v.Name)
 		}
+		return x.vars[v.Name], nil
+
+	case *ast.IntValue:
+		return strconv.ParseInt(v.Value, 10, 64)
+
+	case *ast.FloatValue:
+		return strconv.ParseFloat(v.Value, 64)
+
+	case *ast.StringValue:
+		return v.Value, nil
+
+	case *ast.BooleanValue:
+		return v.Value, nil
+
+	case *ast.NullValue:
+		return nil, nil
+
+	case *ast.EnumValue:
+		return v.Value, nil
+
+	case *ast.ListValue:
+		values := make([]interface{}, len(v.Values))
+		for index, item := range v.Values {
+			value, err := x.valueOf(item)
+			if err != nil {
+				return nil, err
+			}
+			values[index] = value
+		}
+		return values, nil
+
+	case *ast.ObjectValue:
+		fields := make(map[string]interface{}, len(v.Fields))
+		for _, field := range v.Fields {
+			value, err := x.valueOf(field.Value)
+			if err != nil {
+				return nil, err
+			}
+			fields[field.Name] = value
+		}
+		return fields, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported value, %v", value)
+	}
+}
+
+// parseLiteral converts a value written in the query with the scalar type it's given for.
+// Variables hold values already coerced to their declared type and lists are parsed element
+// by element.
+func (x *execution) parseLiteral(scalar *Scalar, value ast.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case *ast.Variable, *ast.NullValue:
+		return x.valueOf(value)
+
+	case *ast.ListValue:
+		values := make([]interface{}, len(v.Values))
+		for index, item := range v.Values {
+			value, err := x.parseLiteral(scalar, item)
+			if err != nil {
+				return nil, err
+			}
+			values[index] = value
+		}
+		return values, nil
+	}
+
+	if scalar.ParseLiteral == nil {
+		return x.valueOf(value)
 	}
-	ctx := &Context{Name: qOp.Field.Name, Args: args}
-	field, err := store.Query(ctx)
+	return scalar.ParseLiteral(value)
+}
+
+// serialize converts a resolved value with the scalar type of its field.  Slices, other than
+// []byte, hold the values of a list and are serialized element by element.
+func serialize(scalar *Scalar, value interface{}) (interface{}, error) {
+	if scalar == nil || scalar.Serialize == nil || value == nil {
+		return value, nil
+	}
+
+	if _, ok := value.([]byte); !ok {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			values := make([]interface{}, rv.Len())
+			for index := range values {
+				v, err := serialize(scalar, rv.Index(index).Interface())
+				if err != nil {
+					return nil, err
+				}
+				values[index] = v
+			}
+			return values, nil
+		}
+	}
+	return scalar.Serialize(value)
+}
+
+// scalar returns the scalar type with the given name; one of the spec's or one registered
+// with the executor.  It returns nil if there isn't one.
+func (x *execution) scalar(name string) *Scalar {
+	if scalar, ok := builtinScalars[name]; ok {
+		return scalar
+	}
+	return x.scalars[name]
+}
+
+// --[ Introspection ]------------------------------------------------
+
+// typeNameField is the meta field every object answers with the name of its type
+const typeNameField = "__typename"
+
+// typeName answers __typename for selections that implement Typed; selections that don't are
+// queried for the field like any other
+type typeName string
+
+func (t typeName) Selection() (Selection, error) {
+	return nil, ErrNotAScalar
+}
+
+func (t typeName) Value() (Value, error) {
+	return string(t), nil
+}
+
+// --[ Variables ]----------------------------------------------------
+
+// coerceVariables resolves the value of each variable the operation declares from the values
+// provided with the request or the variable's default value.  Variables that are neither
+// provided nor defaulted are absent from the result.
+func (x *execution) coerceVariables(qOp *ast.Operation) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, def := range qOp.VariableDefinitions {
+		if v, ok := x.variables[def.Name]; ok {
+			value, err := x.coerceValue(def.Type, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for variable $%v => %v", def.Name, err)
+			}
+			vars[def.Name] = value
+			continue
+		}
+
+		if def.DefaultValue != nil {
+			value, err := x.coerceLiteral(def.Type, def.DefaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default value for variable $%v => %v", def.Name, err)
+			}
+			vars[def.Name] = value
+			continue
+		}
+
+		if def.Type.NonNull {
+			return nil, fmt.Errorf("variable $%v of required type %v was not provided", def.Name, def.Type)
+		}
+	}
+	return vars, nil
+}
+
+// coerceValue converts a variable value, typically decoded from json, to the declared type
+func (x *execution) coerceValue(typ *ast.Type, v interface{}) (interface{}, error) {
+	if v == nil {
+		if typ.NonNull {
+			return nil, fmt.Errorf("expected non-null value of type %v", typ)
+		}
+		return nil, nil
+	}
+
+	if typ.IsList() {
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		values := make([]interface{}, len(items))
+		for index, item := range items {
+			value, err := x.coerceValue(typ.Elem, item)
+			if err != nil {
+				return nil, err
+			}
+			values[index] = value
+		}
+		return values, nil
+	}
+
+	scalar := x.scalar(typ.Name)
+	if scalar == nil || scalar.ParseValue == nil {
+		return v, nil
+	}
+	value, err := scalar.ParseValue(v)
 	if err != nil {
-		return ErrUnknownQuery
+		return nil, fmt.Errorf("%v is not a valid %v => %v", v, typ, err)
+	}
+	return value, nil
+}
+
+// coerceLiteral converts a constant value written in the query, such as the default value of
+// a variable, to the declared type.  Values of scalar types are parsed by the scalar.
+func (x *execution) coerceLiteral(typ *ast.Type, value ast.Value) (interface{}, error) {
+	named := typ
+	for named.IsList() {
+		named = named.Elem
 	}
 
-	selection, err := field.Selection()
+	if scalar := x.scalar(named.Name); scalar != nil {
+		v, err := x.parseLiteral(scalar, value)
+		if err != nil {
+			return nil, fmt.Errorf("%v is not a valid %v => %v", value, typ, err)
+		}
+		if v == nil && typ.NonNull {
+			return nil, fmt.Errorf("expected non-null value of type %v", typ)
+		}
+		return v, nil
+	}
+
+	// other values are constant and so may be converted without an operation
+	literal, err := x.valueOf(value)
 	if err != nil {
-		return ErrUnknownQuery
+		return nil, err
+	}
+	return x.coerceValue(typ, literal)
+}
+
+// --[ Directives ]---------------------------------------------------
+
+// include evaluates the built in @skip and @include directives and reports whether the
+// annotated field or fragment should be included
+func (x *execution) include(directives []*ast.Directive) (bool, error) {
+	for _, directive := range directives {
+		switch directive.Name {
+		case "skip", "include":
+			arg := directive.Arg("if")
+			if arg == nil {
+				return false, fmt.Errorf("@%v requires the if argument", directive.Name)
+			}
+			args, err := x.args([]*ast.Arg{arg}, nil)
+			if err != nil {
+				return false, err
+			}
+			condition, err := toBool(args)
+			if err != nil {
+				return false, fmt.Errorf("@%v => %v", directive.Name, err)
+			}
+			if condition == (directive.Name == "skip") {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// toBool reads the boolean value of the sole argument; either a literal or a variable
+func toBool(args []Arg) (bool, error) {
+	if len(args) == 0 {
+		return false, errors.New("if argument must be a Boolean")
+	}
+
+	v, ok := args[0].Value.(bool)
+	if !ok {
+		return false, errors.New("if argument must be a Boolean")
 	}
+	return v, nil
+}
+
+// --[ Fragments ]----------------------------------------------------
+
+// collectFields flattens a selection set into the fields to be written, in document order.
+// Fragments whose type condition applies to the selection are expanded in place and fields
+// sharing a response key are merged.
+func (x *execution) collectFields(selection Selection, qSelector *ast.Selection) ([]*ast.Field, error) {
+	typed, _ := selection.(Typed)
 
-	return writeSelection(w, selection, qOp.Field.Selection)
+	c := &collector{
+		x:       x,
+		typed:   typed,
+		keys:    map[string]int{},
+		visited: map[string]bool{},
+	}
+	if err := c.collect(qSelector); err != nil {
+		return nil, err
+	}
+	return c.fields, nil
 }
 
-func writeSelection(w io.Writer, selection Selection, qSelector *ast.Selection) error {
-	io.WriteString(w, "{")
-	for index, qField := range qSelector.Fields {
-		ctx := &Context{Name: qField.Name}
-		field, err := selection.Query(ctx)
+type collector struct {
+	x       *execution
+	typed   Typed
+	fields  []*ast.Field
+	keys    map[string]int
+	visited map[string]bool
+}
+
+func (c *collector) collect(qSelector *ast.Selection) error {
+	for _, selector := range qSelector.Selectors {
+		ok, err := c.x.include(directivesOf(selector))
 		if err != nil {
 			return err
 		}
-		writeField(w, field, qField)
+		if !ok {
+			continue
+		}
+
+		switch v := selector.(type) {
+		case *ast.Field:
+			c.addField(v)
 
-		if index < len(qSelector.Fields)-1 {
-			io.WriteString(w, ",")
+		case *ast.FragmentSpread:
+			if c.visited[v.Name] {
+				continue
+			}
+			fragment := c.x.doc.Fragment(v.Name)
+			if fragment == nil {
+				return fmt.Errorf("unknown fragment, %v", v.Name)
+			}
+			c.visited[v.Name] = true
+			ok, err := c.x.include(fragment.Directives)
+			if err != nil {
+				return err
+			}
+			if !ok || !c.applies(fragment.On) {
+				continue
+			}
+			if err := c.collect(fragment.Selection); err != nil {
+				return err
+			}
+
+		case *ast.InlineFragment:
+			if !c.applies(v.On) {
+				continue
+			}
+			if err := c.collect(v.Selection); err != nil {
+				return err
+			}
 		}
 	}
-	io.WriteString(w, "}")
-
 	return nil
 }
 
-func writeField(w io.Writer, field Field, qField *ast.Field) error {
-	io.WriteString(w, `"`)
-	io.WriteString(w, qField.Key())
-	io.WriteString(w, `":`)
+// checkFragmentCycles returns an error if a fragment spreads itself, either directly or by
+// way of other fragments.  Expanding such a fragment over self-referencing data would never
+// end so the document is rejected before anything is written.
+func checkFragmentCycles(doc *ast.Document) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
 
-	if qField.IsScalar() {
-		return writeValue(w, field, qField)
+	var visit func(name string) error
+	var walk func(qSelector *ast.Selection) error
 
-	} else {
-		selection, err := field.Selection()
-		if err != nil {
+	walk = func(qSelector *ast.Selection) error {
+		if qSelector == nil {
+			return nil
+		}
+		for _, selector := range qSelector.Selectors {
+			var err error
+			switch v := selector.(type) {
+			case *ast.Field:
+				err = walk(v.Selection)
+			case *ast.FragmentSpread:
+				err = visit(v.Name)
+			case *ast.InlineFragment:
+				err = walk(v.Selection)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("fragment %v spreads itself", name)
+		case done:
+			return nil
+		}
+
+		fragment := doc.Fragment(name)
+		if fragment == nil {
+			return nil // reported as an unknown fragment if it's reached during execution
+		}
+
+		state[name] = visiting
+		if err := walk(fragment.Selection); err != nil {
 			return err
 		}
-		return writeSelection(w, selection, qField.Selection)
+		state[name] = done
+		return nil
+	}
+
+	for _, fragment := range doc.Fragments {
+		if err := visit(fragment.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func directivesOf(selector ast.Selector) []*ast.Directive {
+	switch v := selector.(type) {
+	case *ast.Field:
+		return v.Directives
+	case *ast.FragmentSpread:
+		return v.Directives
+	case *ast.InlineFragment:
+		return v.Directives
+	default:
+		return nil
 	}
 }
 
-func writeValue(w io.Writer, field Field, qField *ast.Field) error {
-	v, err := field.Value()
-	if err != nil {
-		return err
+// applies reports whether a fragment with the given type condition should be expanded
+func (c *collector) applies(on string) bool {
+	return on == "" || c.typed == nil || c.typed.Satisfies(on)
+}
+
+func (c *collector) addField(qField *ast.Field) {
+	index, ok := c.keys[qField.Key()]
+	if !ok {
+		c.keys[qField.Key()] = len(c.fields)
+		c.fields = append(c.fields, qField)
+		return
 	}
 
-	if v == nil {
-		_, err = io.WriteString(w, "nil")
-		return err
+	// the same response key was selected more than once; merge the sub-selections
+	existing := c.fields[index]
+	if qField.IsScalar() {
+		return
 	}
 
-	data, err := json.Marshal(v)
-	if err != nil {
-		return err
+	merged := *existing
+	merged.Selection = &ast.Selection{}
+	if existing.Selection != nil {
+		merged.Selection.Selectors = append(merged.Selection.Selectors, existing.Selection.Selectors...)
 	}
-	_, err = w.Write(data)
-	return err
+	merged.Selection.Selectors = append(merged.Selection.Selectors, qField.Selection.Selectors...)
+	c.fields[index] = &merged
 }
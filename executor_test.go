@@ -1,6 +1,625 @@
 package graphql
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/savaki/graphql/ast"
+	. "github.com/smartystreets/goconvey/convey"
+)
 
 func TestCompiles(t *testing.T) {
 }
+
+// --[ recorder ]-----------------------------------------------------
+
+// recorder is a Store that captures the contexts it was queried and mutated with
+type recorder struct {
+	contexts  []*Context
+	mutations []*Context
+}
+
+func (r *recorder) Query(c *Context) (Field, error) {
+	r.contexts = append(r.contexts, c)
+	return r, nil
+}
+
+func (r *recorder) Mutate(c *Context) (Field, error) {
+	r.mutations = append(r.mutations, c)
+	return r, nil
+}
+
+func (r *recorder) Selection() (Selection, error) {
+	return r, nil
+}
+
+func (r *recorder) Value() (Value, error) {
+	return "ok", nil
+}
+
+func TestVariables(t *testing.T) {
+	Convey("Given a query that declares variables", t, func() {
+		query := `query user($id: Int = 1, $tags: [String!], $name: String) {
+			user(id: $id, tags: $tags, name: $name) { name }
+		}`
+
+		Convey("When variables are provided, they should replace the arguments", func() {
+			store := &recorder{}
+			buf := bytes.NewBuffer([]byte{})
+			variables := map[string]interface{}{
+				"id":   float64(123),
+				"tags": "a",
+			}
+			err := New(store).HandleRequest(query, "user", variables, buf)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, `{"data":{"user":{"name":"ok"}}}`)
+			So(store.contexts[0].Args, ShouldResemble, []Arg{
+				{Name: "id", Value: int64(123)},
+				{Name: "tags", Value: []interface{}{"a"}},
+			})
+		})
+
+		Convey("When variables are omitted, defaults should be used", func() {
+			store := &recorder{}
+			err := New(store).HandleRequest(query, "", nil, bytes.NewBuffer([]byte{}))
+			So(err, ShouldBeNil)
+			So(store.contexts[0].Args, ShouldResemble, []Arg{
+				{Name: "id", Value: int64(1)},
+			})
+		})
+
+		Convey("When a variable has the wrong type, an error should be returned", func() {
+			store := &recorder{}
+			variables := map[string]interface{}{"id": "abc"}
+			err := New(store).HandleRequest(query, "", variables, bytes.NewBuffer([]byte{}))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When the operation name is unknown, an error should be returned", func() {
+			err := New(&recorder{}).HandleRequest(query, "other", nil, bytes.NewBuffer([]byte{}))
+			So(err, ShouldResemble, Errors{{Message: ErrUnknownOperation.Error()}})
+		})
+	})
+
+	Convey("Given a variable outside the range of Int", t, func() {
+		query := `query user($id: Int) { user(id: $id) { name } }`
+		for _, id := range []interface{}{float64(1e30), int64(1) << 40, json.Number("3000000000"), 1.5} {
+			err := New(&recorder{}).HandleRequest(query, "", map[string]interface{}{"id": id}, bytes.NewBuffer([]byte{}))
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Given a document with several named operations", t, func() {
+		query := `query a { b } query c { d } query user(id: 1) { name }`
+
+		Convey("When I select one by name, only that operation should be written", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := New(&recorder{}).HandleRequest(query, "c", nil, w)
+			So(err, ShouldBeNil)
+			So(w.String(), ShouldEqual, `{"data":{"d":"ok"}}`)
+		})
+
+		Convey("When I select a legacy operation, it's named by its root field", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := New(&recorder{}).HandleRequest(query, "user", nil, w)
+			So(err, ShouldBeNil)
+			So(w.String(), ShouldEqual, `{"data":{"user":{"name":"ok"}}}`)
+		})
+
+		Convey("When I don't name an operation, the request should be rejected without data", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := New(&recorder{}).Handle(query, w)
+			So(err, ShouldResemble, Errors{{Message: ErrOperationNameRequired.Error()}})
+			So(w.String(), ShouldEqual, `{"errors":[{"message":"an operation name is required when the document contains several operations"}]}`)
+		})
+	})
+
+	Convey("Given a document with only fragments", t, func() {
+		err := New(&recorder{}).Handle(`fragment F on T { a }`, bytes.NewBuffer([]byte{}))
+		So(err, ShouldResemble, Errors{{Message: ErrNoOperation.Error()}})
+	})
+
+	Convey("Given a query that requires a variable", t, func() {
+		query := `query user($id: Int!) { user(id: $id) { name } }`
+		err := New(&recorder{}).HandleRequest(query, "", nil, bytes.NewBuffer([]byte{}))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestMutation(t *testing.T) {
+	Convey("Given a mutation with several root fields", t, func() {
+		query := `mutation {
+			first: like(id: 1) { name }
+			second: like(id: 2) { name }
+		}`
+
+		store := &recorder{}
+		buf := bytes.NewBuffer([]byte{})
+		err := New(store).Handle(query, buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{"data":{"first":{"name":"ok"},"second":{"name":"ok"}}}`)
+
+		Convey("Then each root field should be sent to Mutate in document order", func() {
+			So(len(store.mutations), ShouldEqual, 2)
+			So(store.mutations[0].Args, ShouldResemble, []Arg{{Name: "id", Value: int64(1)}})
+			So(store.mutations[1].Args, ShouldResemble, []Arg{{Name: "id", Value: int64(2)}})
+		})
+
+		Convey("Then the selections should be resolved against the mutation results", func() {
+			So(len(store.contexts), ShouldEqual, 2)
+			So(store.contexts[0].Name, ShouldEqual, "name")
+		})
+	})
+
+	Convey("Given a named mutation", t, func() {
+		query := `mutation like(id: 1) { name }`
+
+		store := &recorder{}
+		buf := bytes.NewBuffer([]byte{})
+		err := New(store).Handle(query, buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{"data":{"like":{"name":"ok"}}}`)
+		So(len(store.mutations), ShouldEqual, 1)
+		So(store.mutations[0].Name, ShouldEqual, "like")
+	})
+}
+
+func TestDirectives(t *testing.T) {
+	Convey("Given a query using @skip and @include", t, func() {
+		query := `query user($withName: Boolean = false) {
+			user {
+				id @skip(if: true)
+				name @include(if: $withName)
+				... @include(if: true) { email }
+				...details @skip(if: $withName)
+			}
+		}
+
+		fragment details on User { phone }`
+
+		Convey("Then fields and fragments should be included according to their conditions", func() {
+			buf := bytes.NewBuffer([]byte{})
+			err := New(&recorder{}).Handle(query, buf)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, `{"data":{"user":{"email":"ok","phone":"ok"}}}`)
+		})
+
+		Convey("Then variables should be used as conditions", func() {
+			buf := bytes.NewBuffer([]byte{})
+			variables := map[string]interface{}{"withName": true}
+			err := New(&recorder{}).HandleRequest(query, "", variables, buf)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, `{"data":{"user":{"name":"ok","email":"ok"}}}`)
+		})
+	})
+
+	Convey("Given a custom directive", t, func() {
+		var calls []string
+		e := New(&recorder{})
+		e.Directive("trace", func(c *Context, args []Arg, next Resolver) (Field, error) {
+			calls = append(calls, c.Name+":"+args[0].Value.(string))
+			return next(c)
+		})
+
+		buf := bytes.NewBuffer([]byte{})
+		err := e.Handle(`{ user { name @trace(label: "a") } }`, buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{"data":{"user":{"name":"ok"}}}`)
+
+		Convey("Then it should run around the resolution of the annotated field", func() {
+			So(calls, ShouldResemble, []string{"name:a"})
+		})
+	})
+
+	Convey("Given directives on the root field of a legacy operation", t, func() {
+		var calls []string
+		e := New(&recorder{})
+		e.Directive("trace", func(c *Context, args []Arg, next Resolver) (Field, error) {
+			calls = append(calls, c.Name)
+			return next(c)
+		})
+
+		query := `query a: a @skip(if: true) { b } query c: c(x: 1) @trace { d }`
+		skipped := bytes.NewBuffer([]byte{})
+		err := e.HandleRequest(query, "a", nil, skipped)
+		So(err, ShouldBeNil)
+
+		traced := bytes.NewBuffer([]byte{})
+		err = e.HandleRequest(query, "c", nil, traced)
+		So(err, ShouldBeNil)
+
+		Convey("Then they should be applied as they are to nested fields", func() {
+			So(skipped.String(), ShouldEqual, `{"data":{}}`)
+			So(traced.String(), ShouldEqual, `{"data":{"c":{"d":"ok"}}}`)
+			So(calls, ShouldResemble, []string{"c"})
+		})
+	})
+
+	Convey("Given an attempt to replace a built in directive", t, func() {
+		e := New(&recorder{})
+		register := func() {
+			e.Directive("skip", func(c *Context, args []Arg, next Resolver) (Field, error) {
+				return next(c)
+			})
+		}
+
+		Convey("Then Directive should panic", func() {
+			So(register, ShouldPanic)
+		})
+	})
+}
+
+func TestArgumentValues(t *testing.T) {
+	Convey("Given arguments of each input value kind", t, func() {
+		query := `query users($age: Int = 3) {
+			users(limit: 1.5, active: true, after: null, order: DESC, tags: ["a", "b"], filter: {age: $age, name: "bill"}) { name }
+		}`
+
+		store := &recorder{}
+		err := New(store).Handle(query, bytes.NewBuffer([]byte{}))
+		So(err, ShouldBeNil)
+
+		Convey("Then the store should receive their go equivalents", func() {
+			So(store.contexts[0].Args, ShouldResemble, []Arg{
+				{Name: "limit", Value: 1.5},
+				{Name: "active", Value: true},
+				{Name: "after", Value: nil},
+				{Name: "order", Value: "DESC"},
+				{Name: "tags", Value: []interface{}{"a", "b"}},
+				{Name: "filter", Value: map[string]interface{}{"age": int64(3), "name": "bill"}},
+			})
+		})
+	})
+}
+
+func TestNestedArgs(t *testing.T) {
+	Convey("Given arguments on fields below the root", t, func() {
+		query := `query user($distance: Int) {
+			user(id: 123) {
+				close_friends: friends(max: 5, distance: $distance) {
+					picture(size: 50)
+				}
+			}
+		}`
+
+		store := &recorder{}
+		variables := map[string]interface{}{"distance": 1}
+		err := New(store).HandleRequest(query, "", variables, bytes.NewBuffer([]byte{}))
+		So(err, ShouldBeNil)
+
+		Convey("Then each field should be queried with its own arguments", func() {
+			So(len(store.contexts), ShouldEqual, 3)
+
+			friends := store.contexts[1]
+			So(friends.Name, ShouldEqual, "friends")
+			So(friends.Args, ShouldResemble, []Arg{
+				{Name: "max", Value: int64(5)},
+				{Name: "distance", Value: int64(1)},
+			})
+
+			max, ok := friends.Arg("max")
+			So(ok, ShouldBeTrue)
+			So(max, ShouldEqual, int64(5))
+
+			_, ok = friends.Arg("first")
+			So(ok, ShouldBeFalse)
+
+			size, _ := store.contexts[2].Arg("size")
+			So(size, ShouldEqual, int64(50))
+		})
+	})
+}
+
+func TestNestedErrors(t *testing.T) {
+	Convey("Given queries that fail below the root", t, func() {
+		queries := []string{
+			`{ a { b @skip(if: $nope) } }`,
+			`{ a { b { ...Missing } } }`,
+		}
+
+		for _, query := range queries {
+			err := New(&recorder{}).Handle(query, bytes.NewBuffer([]byte{}))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestFragmentCycles(t *testing.T) {
+	Convey("Given a fragment that spreads itself", t, func() {
+		queries := []string{
+			`{ a { ...F } } fragment F on T { b { ...F } }`,
+			`{ a { ...F } } fragment F on T { b { ...G } } fragment G on T { ... on T { c { ...F } } }`,
+		}
+
+		for _, query := range queries {
+			err := New(&recorder{}).Handle(query, bytes.NewBuffer([]byte{}))
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Given fragments that are spread more than once without a cycle", t, func() {
+		query := `{ a { ...F b { ...F } } } fragment F on T { c }`
+
+		w := bytes.NewBuffer([]byte{})
+		err := New(&recorder{}).Handle(query, w)
+		So(err, ShouldBeNil)
+		So(w.String(), ShouldEqual, `{"data":{"a":{"c":"ok","b":{"c":"ok"}}}}`)
+	})
+}
+
+// typedRecorder is a recorder that reports a type name
+type typedRecorder struct {
+	recorder
+}
+
+func (r *typedRecorder) TypeName() string {
+	return "Droid"
+}
+
+func (r *typedRecorder) Satisfies(typeCondition string) bool {
+	return typeCondition == "Droid"
+}
+
+func TestTypeName(t *testing.T) {
+	Convey("Given a store that implements Typed", t, func() {
+		store := &typedRecorder{}
+
+		w := bytes.NewBuffer([]byte{})
+		err := New(store).Handle(`{ __typename kind: __typename @include(if: true) a }`, w)
+		So(err, ShouldBeNil)
+		So(w.String(), ShouldEqual, `{"data":{"__typename":"Droid","kind":"Droid","a":"ok"}}`)
+
+		Convey("Then __typename isn't queried from the store", func() {
+			So(len(store.contexts), ShouldEqual, 1)
+			So(store.contexts[0].Name, ShouldEqual, "a")
+		})
+	})
+
+	Convey("Given a store that doesn't implement Typed", t, func() {
+		store := &recorder{}
+
+		w := bytes.NewBuffer([]byte{})
+		err := New(store).Handle(`{ __typename }`, w)
+		So(err, ShouldBeNil)
+		So(w.String(), ShouldEqual, `{"data":{"__typename":"ok"}}`)
+
+		Convey("Then __typename is queried like any other field", func() {
+			So(len(store.contexts), ShouldEqual, 1)
+		})
+	})
+}
+
+// failing is a recorder whose fields named fail and failRequired can't be resolved; fields
+// named required and failRequired are declared non-null
+type failing struct {
+	recorder
+}
+
+func (f *failing) Query(c *Context) (Field, error) {
+	if c.Name == "fail" || c.Name == "failRequired" {
+		return nil, errors.New("boom")
+	}
+	return f, nil
+}
+
+func (f *failing) Selection() (Selection, error) {
+	return f, nil
+}
+
+func (f *failing) NonNullField(name string) bool {
+	return name == "required" || name == "failRequired"
+}
+
+func TestResponse(t *testing.T) {
+	Convey("Given a query that can't be parsed", t, func() {
+		w := bytes.NewBuffer([]byte{})
+		err := New(&recorder{}).Handle(`{ a `, w)
+
+		Convey("Then the response holds errors and no data", func() {
+			So(err, ShouldNotBeNil)
+			So(w.String(), ShouldStartWith, `{"errors":[{"message":`)
+		})
+	})
+
+	Convey("Given a query with a syntax error", t, func() {
+		w := bytes.NewBuffer([]byte{})
+		err := New(&recorder{}).Handle(`{ a(x: ) }`, w)
+
+		Convey("Then the error is located at the offending token", func() {
+			So(err, ShouldNotBeNil)
+			So(w.String(), ShouldEqual, `{"errors":[{"message":"illegal value","locations":[{"line":1,"column":8}]}]}`)
+		})
+	})
+
+	Convey("Given a field that fails", t, func() {
+		w := bytes.NewBuffer([]byte{})
+		err := New(&failing{}).Handle(`{ a { fail b } }`, w)
+
+		Convey("Then the field is null and the remaining fields are written", func() {
+			So(err, ShouldResemble, Errors{{Message: "boom", Locations: []ast.Loc{{Start: 6, End: 10, Line: 1, Column: 7}}, Path: []interface{}{"a", "fail"}}})
+			So(w.String(), ShouldEqual, `{"data":{"a":{"fail":null,"b":"ok"}},"errors":[{"message":"boom","locations":[{"line":1,"column":7}],"path":["a","fail"]}]}`)
+		})
+	})
+
+	Convey("Given a failure below a non-null field", t, func() {
+		w := bytes.NewBuffer([]byte{})
+		New(&failing{}).Handle(`{ a { required { required { failRequired } } } b }`, w)
+
+		Convey("Then the null propagates through non-null fields to the nearest nullable one", func() {
+			So(w.String(), ShouldEqual, `{"data":{"a":null,"b":"ok"},"errors":[{"message":"boom","locations":[{"line":1,"column":29}],"path":["a","required","required","failRequired"]}]}`)
+		})
+	})
+}
+
+// contextual is a Store implementing the context aware interfaces.  Fields answer with the
+// value held by the context under valueKey; resolving a field named cancel cancels the request.
+type contextual struct {
+	cancel  context.CancelFunc
+	queried []string
+}
+
+type valueKey struct{}
+
+var errContextIgnored = errors.New("called without the context")
+
+func (c *contextual) Query(*Context) (Field, error)  { return nil, errContextIgnored }
+func (c *contextual) Mutate(*Context) (Field, error) { return nil, errContextIgnored }
+func (c *contextual) Selection() (Selection, error)  { return nil, errContextIgnored }
+func (c *contextual) Value() (Value, error)          { return nil, errContextIgnored }
+
+func (c *contextual) QueryContext(ctx context.Context, gc *Context) (Field, error) {
+	c.queried = append(c.queried, gc.Name)
+	if gc.Name == "cancel" {
+		c.cancel()
+	}
+	return c, nil
+}
+
+func (c *contextual) MutateContext(ctx context.Context, gc *Context) (Field, error) {
+	return c.QueryContext(ctx, gc)
+}
+
+func (c *contextual) SelectionContext(ctx context.Context) (Selection, error) {
+	return c, nil
+}
+
+func (c *contextual) ValueContext(ctx context.Context) (Value, error) {
+	return ctx.Value(valueKey{}), nil
+}
+
+func TestContext(t *testing.T) {
+	Convey("Given a store implementing the context aware interfaces", t, func() {
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), valueKey{}, "principal"))
+		defer cancel()
+
+		store := &contextual{cancel: cancel}
+		w := bytes.NewBuffer([]byte{})
+
+		Convey("When a query is executed with a context", func() {
+			err := New(store).HandleContext(ctx, `{ a { b } c }`, "", nil, w)
+
+			Convey("Then the context should reach the store and its fields", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"a":{"b":"principal"},"c":"principal"}}`)
+			})
+		})
+
+		Convey("When a mutation is executed with a context", func() {
+			err := New(store).HandleContext(ctx, `mutation { a }`, "", nil, w)
+
+			Convey("Then the context should reach Mutate", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"a":"principal"}}`)
+			})
+		})
+
+		Convey("When the context is cancelled part way through", func() {
+			err := New(store).HandleContext(ctx, `{ a { cancel b } c }`, "", nil, w)
+
+			Convey("Then the fields yet to be resolved should be abandoned", func() {
+				So(err, ShouldResemble, Errors{{Message: "context canceled"}})
+				So(w.String(), ShouldEqual, `{"data":null,"errors":[{"message":"context canceled"}]}`)
+				So(store.queried, ShouldResemble, []string{"a", "c", "cancel"})
+			})
+		})
+	})
+
+	Convey("Given a store written without a context", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		store := &recorder{}
+		w := bytes.NewBuffer([]byte{})
+		err := New(store).HandleContext(ctx, `{ a }`, "", nil, w)
+
+		Convey("Then it should still be adapted and the request abandoned once cancelled", func() {
+			So(err, ShouldResemble, Errors{{Message: "context canceled"}})
+			So(len(store.contexts), ShouldEqual, 0)
+		})
+	})
+}
+
+// slow is a Store whose fields take a while to resolve.  It counts the fields being resolved
+// at once and remembers the most it saw.
+type slow struct {
+	mu       sync.Mutex
+	inFlight int
+	most     int
+	order    []string
+}
+
+func (s *slow) Query(c *Context) (Field, error) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.most {
+		s.most = s.inFlight
+	}
+	s.order = append(s.order, c.Name)
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	if c.Name == "fail" {
+		return nil, errors.New("boom")
+	}
+	return s, nil
+}
+
+func (s *slow) Mutate(c *Context) (Field, error) { return s.Query(c) }
+func (s *slow) Selection() (Selection, error)    { return s, nil }
+func (s *slow) Value() (Value, error)            { return "ok", nil }
+
+func TestConcurrency(t *testing.T) {
+	Convey("Given an executor that resolves up to 3 fields at once", t, func() {
+		store := &slow{}
+		executor := New(store)
+		executor.Concurrency = 3
+		w := bytes.NewBuffer([]byte{})
+
+		Convey("When sibling fields are queried", func() {
+			err := executor.Handle(`{ a { b c } d { e f } g fail h }`, w)
+
+			Convey("Then they should be resolved concurrently and written in document order", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"a":{"b":"ok","c":"ok"},"d":{"e":"ok","f":"ok"},"g":"ok","fail":null,"h":"ok"},"errors":[{"message":"boom","locations":[{"line":1,"column":25}],"path":["fail"]}]}`)
+				So(store.most, ShouldBeGreaterThan, 1)
+			})
+
+			Convey("Then no more than 3 fields should have been resolved at once", func() {
+				So(store.most, ShouldBeLessThanOrEqualTo, 3)
+			})
+		})
+
+		Convey("When a mutation is executed", func() {
+			err := executor.Handle(`mutation { a { b c } d { e f } g }`, w)
+
+			Convey("Then the root fields should be resolved one after another", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"a":{"b":"ok","c":"ok"},"d":{"e":"ok","f":"ok"},"g":"ok"}}`)
+				So(store.order[0], ShouldEqual, "a")
+				So(store.order[3], ShouldEqual, "d")
+				So(store.order[6], ShouldEqual, "g")
+			})
+		})
+	})
+
+	Convey("Given an executor without a concurrency limit", t, func() {
+		store := &slow{}
+		w := bytes.NewBuffer([]byte{})
+		New(store).Handle(`{ a b c }`, w)
+
+		Convey("Then fields should be resolved one at a time", func() {
+			So(store.most, ShouldEqual, 1)
+			So(store.order, ShouldResemble, []string{"a", "b", "c"})
+		})
+	})
+}
@@ -1,12 +1,23 @@
 package graphql
 
-import "errors"
+import (
+	"context"
+	"errors"
+
+	"github.com/savaki/graphql/ast"
+)
 
 var (
-	ErrFieldNotFound  = errors.New("field not found")
-	ErrNotImplemented = errors.New("feature not implemented")
-	ErrNotAScalar     = errors.New("invalid attempt to treat non-scalar as scalar")
-	ErrUnknownQuery   = errors.New("unknown query operation")
+	ErrFieldNotFound         = errors.New("field not found")
+	ErrNotImplemented        = errors.New("feature not implemented")
+	ErrNotAScalar            = errors.New("invalid attempt to treat non-scalar as scalar")
+	ErrUnknownQuery          = errors.New("unknown query operation")
+	ErrUnknownOperation      = errors.New("no operation with the requested name")
+	ErrNoOperation           = errors.New("document does not contain an operation")
+	ErrOperationNameRequired = errors.New("an operation name is required when the document contains several operations")
+
+	// errNullChild reports that a non-null field was null so its parent must be null too
+	errNullChild = errors.New("non-null field was null")
 )
 
 // --[ Value ]--------------------------------------------------------
@@ -28,6 +39,26 @@ type Context struct {
 	Args []Arg
 }
 
+// Arg returns the value of the named argument and whether the field was given it
+func (c *Context) Arg(name string) (Value, bool) {
+	for _, arg := range c.Args {
+		if arg.Name == name {
+			return arg.Value, true
+		}
+	}
+	return nil, false
+}
+
+// --[ Directives ]---------------------------------------------------
+
+// Resolver resolves the field described by the Context
+type Resolver func(*Context) (Field, error)
+
+// DirectiveFunc implements a custom field directive.  It runs around the resolution of each
+// field annotated with the directive; args holds the directive's arguments and next resolves
+// the field.
+type DirectiveFunc func(c *Context, args []Arg, next Resolver) (Field, error)
+
 // --[ Selection ]----------------------------------------------------
 
 type Query interface {
@@ -43,7 +74,84 @@ type Field interface {
 	Value() (Value, error)
 }
 
+// List may be implemented by a Field whose value is a list of objects.  The executor applies
+// the field's selection to each element and writes the results as an array.
+type List interface {
+	Field
+	Elements() ([]Field, error)
+}
+
+// NonNullFields may be implemented by a Selection to report which of its fields are declared
+// non-null.  When such a field fails, its parent is set to null in its place.  Fields of
+// selections that don't implement NonNullFields are nullable.
+type NonNullFields interface {
+	NonNullField(name string) bool
+}
+
+// ScalarTypes may be implemented by a Selection to report which of its fields, and of their
+// arguments, are of a scalar type.  ScalarField returns the name of the scalar type of the
+// named field, or of its elements if the field is a list, and ScalarArg that of an argument
+// of the field; both return "" when the type isn't a scalar or isn't known.  Values of fields
+// of the spec's scalars, or of a scalar registered with the Executor, are serialized by the
+// scalar before they're written; literal arguments are parsed by it.
+type ScalarTypes interface {
+	ScalarField(name string) string
+	ScalarArg(field, arg string) string
+}
+
+// NonNullElements may be implemented by a List to report whether its elements are declared
+// non-null.  When such an element fails, the list as a whole is set to null.
+type NonNullElements interface {
+	NonNullElements() bool
+}
+
+// Typed may be implemented by a Selection to report its type.  TypeName returns the name of
+// the concrete type and Satisfies reports whether a fragment with the given type condition
+// applies; the condition may name the concrete type or an interface or union it belongs to.
+// Fragments always apply to selections that don't implement Typed.
+type Typed interface {
+	TypeName() string
+	Satisfies(typeCondition string) bool
+}
+
 type Store interface {
 	Query
 	Mutate(*Context) (Field, error)
 }
+
+// --[ context.Context ]----------------------------------------------
+
+// QueryContext may be implemented by a Query that wants the context.Context of the request,
+// which carries its deadline and request scoped values and is cancelled along with it.  The
+// executor calls QueryContext in place of Query when it's implemented, so stores need only
+// implement the interfaces they have a use for.
+type QueryContext interface {
+	QueryContext(ctx context.Context, c *Context) (Field, error)
+}
+
+// MutateContext may be implemented by a Store to receive the context.Context of the request
+// when a mutation is executed; see QueryContext
+type MutateContext interface {
+	MutateContext(ctx context.Context, c *Context) (Field, error)
+}
+
+// FieldContext may be implemented by a Field to receive the context.Context of the request
+// when its value or selection is asked for; see QueryContext
+type FieldContext interface {
+	SelectionContext(ctx context.Context) (Selection, error)
+	ValueContext(ctx context.Context) (Value, error)
+}
+
+// ListContext may be implemented by a List to receive the context.Context of the request
+// when its elements are asked for; see QueryContext
+type ListContext interface {
+	ElementsContext(ctx context.Context) ([]Field, error)
+}
+
+// --[ Validator ]----------------------------------------------------
+
+// Validator checks a parsed document before it's executed; see the validation package for a
+// Validator that checks documents against a schema
+type Validator interface {
+	Validate(doc *ast.Document) error
+}
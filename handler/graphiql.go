@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// --[ GraphiQL ]-----------------------------------------------------
+
+// acceptsHTML reports whether the Accept header names text/html, as browsers do when a page
+// is opened.  Wildcards don't count; clients that accept anything are sent JSON.
+func acceptsHTML(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, mediaParams, err := mime.ParseMediaType(mediaRange)
+		if err == nil && mediaType == "text/html" && mediaParams["q"] != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// serveGraphiQL writes the IDE.  The page is self contained so it works without access to
+// the internet; it reads the query, variables and operationName URL parameters to fill its
+// editors and posts requests back to the URL it was served from.
+func serveGraphiQL(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(graphiQL))
+}
+
+const graphiQL = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GraphiQL</title>
+<style>
+* { box-sizing: border-box; }
+html, body { height: 100%; margin: 0; }
+body { display: flex; flex-direction: column; font: 14px -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #333; }
+header { display: flex; align-items: center; gap: 8px; padding: 8px 12px; background: #f7f7f7; border-bottom: 1px solid #ddd; }
+header h1 { margin: 0 12px 0 0; font-size: 18px; font-weight: normal; }
+header h1 em { color: #e535ab; font-style: italic; }
+header input { padding: 4px 6px; border: 1px solid #ccc; border-radius: 3px; }
+button { padding: 5px 12px; border: 1px solid #bbb; border-radius: 3px; background: #fff; cursor: pointer; }
+button.run { background: #e535ab; border-color: #c42f93; color: #fff; }
+.spacer { flex: 1; }
+main { display: flex; flex: 1; min-height: 0; }
+.pane { display: flex; flex-direction: column; flex: 1; min-width: 0; border-right: 1px solid #ddd; }
+.pane h2 { margin: 0; padding: 4px 12px; font-size: 11px; font-weight: bold; letter-spacing: 1px; text-transform: uppercase; color: #888; background: #fafafa; border-bottom: 1px solid #eee; }
+textarea, pre { flex: 1; margin: 0; padding: 8px 12px; border: 0; outline: 0; resize: none; overflow: auto; font: 13px Menlo, Consolas, "Liberation Mono", monospace; tab-size: 2; white-space: pre; }
+#variables { flex: 0 0 30%; border-top: 1px solid #eee; }
+#result { background: #fcfcfc; }
+#result.error { color: #b00; }
+#docs { flex: 0 0 320px; overflow: auto; display: none; border-right: 0; }
+#docs.open { display: flex; }
+#docs .content { padding: 8px 12px; overflow: auto; }
+#docs h3 { margin: 12px 0 4px; font-size: 14px; }
+#docs a { color: #1f61a0; cursor: pointer; text-decoration: none; }
+#docs .field { margin: 4px 0; font-family: Menlo, Consolas, monospace; font-size: 12px; }
+#docs .description { color: #777; font-family: inherit; margin: 2px 0 6px 12px; }
+</style>
+</head>
+<body>
+<header>
+  <h1>Graph<em>i</em>QL</h1>
+  <button class="run" id="run" title="Execute query (Ctrl-Enter)">&#9654; Run</button>
+  <input id="operationName" placeholder="operation name" size="20">
+  <button id="prettify" title="Prettify variables">Prettify</button>
+  <span class="spacer"></span>
+  <button id="toggleDocs">Docs</button>
+</header>
+<main>
+  <section class="pane">
+    <h2>Query</h2>
+    <textarea id="query" spellcheck="false" placeholder="{ field }"></textarea>
+    <h2>Variables</h2>
+    <textarea id="variables" spellcheck="false" placeholder="{}"></textarea>
+  </section>
+  <section class="pane">
+    <h2>Result</h2>
+    <pre id="result"></pre>
+  </section>
+  <section class="pane" id="docs">
+    <h2>Documentation</h2>
+    <div class="content" id="docsContent">Loading schema&hellip;</div>
+  </section>
+</main>
+<script>
+(function () {
+  var $ = function (id) { return document.getElementById(id); };
+  var query = $("query"), variables = $("variables"), operationName = $("operationName"), result = $("result");
+  var storage = window.localStorage;
+
+  // the URL takes precedence over the last query written so that links can be shared
+  var params = new URLSearchParams(window.location.search);
+  query.value = params.get("query") || (storage && storage.getItem("graphiql:query")) || "";
+  variables.value = params.get("variables") || (storage && storage.getItem("graphiql:variables")) || "";
+  operationName.value = params.get("operationName") || "";
+
+  function save() {
+    if (storage) {
+      storage.setItem("graphiql:query", query.value);
+      storage.setItem("graphiql:variables", variables.value);
+    }
+  }
+
+  function fetchGraphQL(body) {
+    return fetch(window.location.pathname, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        "Accept": "application/graphql-response+json, application/json"
+      },
+      body: JSON.stringify(body)
+    }).then(function (response) { return response.json(); });
+  }
+
+  function run() {
+    var vars = null;
+    if (variables.value.trim() !== "") {
+      try {
+        vars = JSON.parse(variables.value);
+      } catch (e) {
+        result.className = "error";
+        result.textContent = "Variables are not valid JSON: " + e.message;
+        return;
+      }
+    }
+
+    save();
+    var url = new URL(window.location.href);
+    url.searchParams.set("query", query.value);
+    ["variables", "operationName"].forEach(function (name) {
+      var value = $(name).value;
+      value ? url.searchParams.set(name, value) : url.searchParams.delete(name);
+    });
+    window.history.replaceState(null, "", url);
+
+    result.className = "";
+    result.textContent = "Loading…";
+    fetchGraphQL({ query: query.value, variables: vars, operationName: operationName.value || null })
+      .then(function (data) { result.textContent = JSON.stringify(data, null, 2); })
+      .catch(function (e) { result.className = "error"; result.textContent = String(e); });
+  }
+
+  // tab inserts spaces rather than moving focus
+  function indent(e) {
+    if (e.key === "Tab") {
+      e.preventDefault();
+      var el = e.target, start = el.selectionStart;
+      el.value = el.value.substring(0, start) + "  " + el.value.substring(el.selectionEnd);
+      el.selectionStart = el.selectionEnd = start + 2;
+    }
+  }
+
+  document.addEventListener("keydown", function (e) {
+    if ((e.ctrlKey || e.metaKey) && e.key === "Enter") {
+      e.preventDefault();
+      run();
+    }
+  });
+  query.addEventListener("keydown", indent);
+  variables.addEventListener("keydown", indent);
+  $("run").addEventListener("click", run);
+  $("prettify").addEventListener("click", function () {
+    try {
+      variables.value = JSON.stringify(JSON.parse(variables.value), null, 2);
+    } catch (e) {
+    }
+  });
+  $("toggleDocs").addEventListener("click", function () { $("docs").classList.toggle("open"); });
+
+  // --[ Documentation ]---------------------------------------------
+
+  var introspectionQuery = "query IntrospectionQuery { __schema { queryType { name } mutationType { name } " +
+    "types { kind name description fields { name description args { name type { ...TypeRef } defaultValue } type { ...TypeRef } } " +
+    "inputFields { name description type { ...TypeRef } defaultValue } enumValues { name description } possibleTypes { name } } } } " +
+    "fragment TypeRef on __Type { kind name ofType { kind name ofType { kind name ofType { kind name } } } }";
+  var types = {};
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) { node.className = className; }
+    if (text) { node.textContent = text; }
+    return node;
+  }
+
+  function typeLink(ref) {
+    if (ref.kind === "NON_NULL") {
+      var span = el("span");
+      span.appendChild(typeLink(ref.ofType));
+      span.appendChild(document.createTextNode("!"));
+      return span;
+    }
+    if (ref.kind === "LIST") {
+      var list = el("span");
+      list.appendChild(document.createTextNode("["));
+      list.appendChild(typeLink(ref.ofType));
+      list.appendChild(document.createTextNode("]"));
+      return list;
+    }
+    var a = el("a", "", ref.name);
+    a.addEventListener("click", function () { showType(ref.name); });
+    return a;
+  }
+
+  function showFields(content, fields) {
+    fields.forEach(function (field) {
+      var line = el("div", "field", field.name);
+      if (field.args && field.args.length) {
+        line.appendChild(document.createTextNode("("));
+        field.args.forEach(function (arg, index) {
+          if (index > 0) { line.appendChild(document.createTextNode(", ")); }
+          line.appendChild(document.createTextNode(arg.name + ": "));
+          line.appendChild(typeLink(arg.type));
+        });
+        line.appendChild(document.createTextNode(")"));
+      }
+      line.appendChild(document.createTextNode(": "));
+      line.appendChild(typeLink(field.type));
+      content.appendChild(line);
+      if (field.description) { content.appendChild(el("div", "description", field.description)); }
+    });
+  }
+
+  function showType(name) {
+    var type = types[name], content = $("docsContent");
+    content.innerHTML = "";
+    var back = el("a", "", "‹ Schema");
+    back.addEventListener("click", showSchema);
+    content.appendChild(back);
+    content.appendChild(el("h3", "", type.name + " (" + type.kind.toLowerCase().replace("_", " ") + ")"));
+    if (type.description) { content.appendChild(el("div", "description", type.description)); }
+    if (type.fields) { showFields(content, type.fields); }
+    if (type.inputFields) { showFields(content, type.inputFields); }
+    (type.enumValues || []).forEach(function (value) {
+      content.appendChild(el("div", "field", value.name));
+      if (value.description) { content.appendChild(el("div", "description", value.description)); }
+    });
+    (type.possibleTypes || []).forEach(function (possible) {
+      var line = el("div", "field");
+      line.appendChild(typeLink(possible));
+      content.appendChild(line);
+    });
+  }
+
+  function showSchema() {
+    var content = $("docsContent");
+    content.innerHTML = "";
+    Object.keys(types).sort().forEach(function (name) {
+      if (name.indexOf("__") === 0) { return; }
+      var line = el("div", "field");
+      line.appendChild(typeLink(types[name]));
+      content.appendChild(line);
+    });
+  }
+
+  fetchGraphQL({ query: introspectionQuery, operationName: "IntrospectionQuery" })
+    .then(function (data) {
+      if (!data.data || !data.data.__schema) { throw new Error("no schema"); }
+      data.data.__schema.types.forEach(function (type) { types[type.name] = type; });
+      showSchema();
+    })
+    .catch(function () {
+      $("docsContent").textContent = "This service doesn't describe its schema.";
+    });
+})();
+</script>
+</body>
+</html>
+`
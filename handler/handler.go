@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/savaki/graphql"
+	"github.com/savaki/graphql/ast"
+)
+
+const (
+	contentTypeJSON            = "application/json"
+	contentTypeGraphQL         = "application/graphql"
+	contentTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+var (
+	errMissingQuery       = errors.New("request does not contain a query")
+	errMethodNotAllowed   = errors.New("requests must be sent with GET or POST")
+	errMutationNotAllowed = errors.New("mutations may only be sent with POST")
+	errNotAcceptable      = errors.New("responses are only available as " + contentTypeGraphQLResponse + " or " + contentTypeJSON)
+)
+
+// --[ Handler ]------------------------------------------------------
+
+// Handler serves GraphQL over HTTP as the GraphQL-over-HTTP spec describes.  It accepts
+//
+//	GET with the query, variables and operationName URL parameters; variables holds JSON
+//	POST with an application/json body holding query, variables and operationName
+//	POST with an application/graphql body holding the query; the other parameters are
+//	     read from the URL
+//
+// Mutations are only accepted over POST.  Responses are application/graphql-response+json
+// when the client accepts it and application/json otherwise.  The media type decides the
+// status of a request that fails before execution, e.g. because it doesn't validate;
+// application/graphql-response+json replies 400 where application/json replies 200.
+// Queries are executed within the context of the request so they're abandoned along with it.
+//
+// Browsers that GET the handler with Accept: text/html are sent a GraphiQL-style IDE instead.
+type Handler struct {
+	Executor graphql.Executor
+
+	// GraphiQL serves the IDE to browsers; New enables it
+	GraphiQL bool
+}
+
+func New(executor graphql.Executor) *Handler {
+	return &Handler{
+		Executor: executor,
+		GraphiQL: true,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.GraphiQL {
+		w.Header().Add("Vary", "Accept")
+		if r.Method == http.MethodGet && acceptsHTML(r.Header.Get("Accept")) {
+			serveGraphiQL(w)
+			return
+		}
+	}
+
+	contentType, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		writeError(w, contentTypeJSON, &requestError{status: http.StatusNotAcceptable, err: errNotAcceptable})
+		return
+	}
+
+	var p *params
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		p, err = readURL(r)
+	case http.MethodPost:
+		p, err = readBody(r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		err = &requestError{status: http.StatusMethodNotAllowed, err: errMethodNotAllowed}
+	}
+	if err != nil {
+		writeError(w, contentType, err)
+		return
+	}
+
+	if r.Method == http.MethodGet && isMutation(p.Query, p.OperationName) {
+		w.Header().Set("Allow", "POST")
+		writeError(w, contentType, &requestError{status: http.StatusMethodNotAllowed, err: errMutationNotAllowed})
+		return
+	}
+
+	response := h.Executor.ExecuteContext(r.Context(), p.Query, p.OperationName, p.Variables)
+
+	status := http.StatusOK
+	if !response.Executed && contentType == contentTypeGraphQLResponse {
+		status = http.StatusBadRequest
+	}
+	writeResponse(w, contentType, status, response)
+}
+
+// --[ Request ]------------------------------------------------------
+
+// params holds the parameters of a request however they were sent
+type params struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// requestError reports a request that couldn't be read along with the status to reply with
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(err error) error {
+	return &requestError{status: http.StatusBadRequest, err: err}
+}
+
+// readURL reads the parameters of a GET request from its URL
+func readURL(r *http.Request) (*params, error) {
+	p, err := urlParams(r)
+	if err != nil {
+		return nil, err
+	}
+	if p.Query == "" {
+		return nil, badRequest(errMissingQuery)
+	}
+	return p, nil
+}
+
+// urlParams reads whichever parameters the URL holds
+func urlParams(r *http.Request) (*params, error) {
+	values := r.URL.Query()
+	p := &params{
+		Query:         values.Get("query"),
+		OperationName: values.Get("operationName"),
+	}
+
+	if v := values.Get("variables"); v != "" {
+		if err := decodeJSON([]byte(v), &p.Variables); err != nil {
+			return nil, badRequest(errors.New("variables must be a JSON object: " + err.Error()))
+		}
+	}
+	return p, nil
+}
+
+// readBody reads the parameters of a POST request from its body
+func readBody(r *http.Request) (*params, error) {
+	mediaType, mediaParams, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, unsupportedMediaType()
+	}
+	if charset, ok := mediaParams["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return nil, unsupportedMediaType()
+	}
+
+	switch mediaType {
+	case contentTypeJSON:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+
+		p := &params{}
+		if err := decodeJSON(body, p); err != nil {
+			return nil, badRequest(errors.New("body must be a JSON object: " + err.Error()))
+		}
+		if p.Query == "" {
+			return nil, badRequest(errMissingQuery)
+		}
+		return p, nil
+
+	case contentTypeGraphQL:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+		if len(body) == 0 {
+			return nil, badRequest(errMissingQuery)
+		}
+
+		// the body is the query; the rest of the parameters may still be given in the URL
+		p, err := urlParams(r)
+		if err != nil {
+			return nil, err
+		}
+		p.Query = string(body)
+		return p, nil
+
+	default:
+		return nil, unsupportedMediaType()
+	}
+}
+
+func unsupportedMediaType() error {
+	return &requestError{
+		status: http.StatusUnsupportedMediaType,
+		err:    errors.New("requests must be sent as " + contentTypeJSON + " or " + contentTypeGraphQL),
+	}
+}
+
+// decodeJSON decodes data into v, keeping numbers as json.Number so that integers keep
+// their precision until they're coerced to the type of their variable
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// isMutation reports whether the operation a request would execute is a mutation.  Queries
+// that can't be parsed, or don't identify an operation, are left for the executor to report.
+func isMutation(query, operationName string) bool {
+	doc, err := ast.Parse(query)
+	if err != nil {
+		return false
+	}
+
+	var op *ast.Operation
+	switch {
+	case operationName != "":
+		op = doc.Operation(operationName)
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	}
+	return op != nil && op.Type == ast.OpMutation
+}
+
+// --[ Response ]-----------------------------------------------------
+
+// negotiate picks the media type of the response from the Accept header.  A request without
+// one is treated as accepting application/json, as the spec asks.  ok is false when the
+// client accepts neither media type.
+func negotiate(accept string) (contentType string, ok bool) {
+	if accept == "" {
+		return contentTypeJSON, true
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, mediaParams, err := mime.ParseMediaType(mediaRange)
+		if err != nil || mediaParams["q"] == "0" {
+			continue
+		}
+
+		switch mediaType {
+		case contentTypeGraphQLResponse:
+			return contentTypeGraphQLResponse, true
+		case contentTypeJSON, "application/*", "*/*":
+			contentType, ok = contentTypeJSON, true
+		}
+	}
+	return contentType, ok
+}
+
+func writeResponse(w http.ResponseWriter, contentType string, status int, response *graphql.Response) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// writeError replies to a request that couldn't be executed with a response holding only
+// the error
+func writeError(w http.ResponseWriter, contentType string, err error) {
+	status := http.StatusBadRequest
+	if v, ok := err.(*requestError); ok {
+		status = v.status
+	}
+
+	response := &graphql.Response{
+		Errors: graphql.Errors{{Message: err.Error()}},
+	}
+	writeResponse(w, contentType, status, response)
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/savaki/graphql"
+	"github.com/savaki/graphql/provider/mapq"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serve(method, target, contentType, accept, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	store := mapq.New(map[string]interface{}{"hello": "world"})
+	w := httptest.NewRecorder()
+	New(graphql.New(store)).ServeHTTP(w, req)
+	return w
+}
+
+func TestGet(t *testing.T) {
+	Convey("Given a GET request", t, func() {
+		Convey("When the URL holds a query", func() {
+			w := serve("GET", "/graphql?query="+url.QueryEscape(`{ hello }`), "", "", "")
+
+			Convey("Then the response should hold the data as application/json", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+				So(w.Body.String(), ShouldEqual, `{"data":{"hello":"world"}}`)
+			})
+		})
+
+		Convey("When the URL holds variables and an operation name", func() {
+			values := url.Values{}
+			values.Set("query", `query a { a: hello } query b($x: Int) { b: hello @include(if: true) }`)
+			values.Set("operationName", "b")
+			values.Set("variables", `{"x":1}`)
+			w := serve("GET", "/graphql?"+values.Encode(), "", "", "")
+
+			Convey("Then the named operation should be executed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, `{"data":{"b":"world"}}`)
+			})
+		})
+
+		Convey("When the variables aren't JSON", func() {
+			w := serve("GET", "/graphql?query=%7Bhello%7D&variables=x", "", "", "")
+
+			Convey("Then the request should be rejected as bad", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldStartWith, `{"errors":[{"message":"variables must be a JSON object`)
+			})
+		})
+
+		Convey("When the URL doesn't hold a query", func() {
+			w := serve("GET", "/graphql", "", "", "")
+
+			Convey("Then the request should be rejected as bad", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldEqual, `{"errors":[{"message":"request does not contain a query"}]}`)
+			})
+		})
+
+		Convey("When the query is a mutation", func() {
+			w := serve("GET", "/graphql?query="+url.QueryEscape(`mutation { hello }`), "", "", "")
+
+			Convey("Then the request should be rejected and POST advertised", func() {
+				So(w.Code, ShouldEqual, http.StatusMethodNotAllowed)
+				So(w.Header().Get("Allow"), ShouldEqual, "POST")
+				So(w.Body.String(), ShouldEqual, `{"errors":[{"message":"mutations may only be sent with POST"}]}`)
+			})
+		})
+
+		Convey("When the operation name selects a mutation", func() {
+			values := url.Values{}
+			values.Set("query", `query a { hello } mutation b { hello }`)
+			values.Set("operationName", "b")
+			w := serve("GET", "/graphql?"+values.Encode(), "", "", "")
+
+			Convey("Then the request should be rejected", func() {
+				So(w.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			})
+		})
+	})
+}
+
+func TestPost(t *testing.T) {
+	Convey("Given a POST request", t, func() {
+		Convey("When the body is application/json", func() {
+			body := `{"query":"query a { a: hello } query b { b: hello }","operationName":"b","variables":null}`
+			w := serve("POST", "/graphql", "application/json; charset=utf-8", "", body)
+
+			Convey("Then the named operation should be executed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, `{"data":{"b":"world"}}`)
+			})
+		})
+
+		Convey("When the body is application/graphql", func() {
+			w := serve("POST", "/graphql?operationName=b", "application/graphql", "", `query a { a: hello } query b { b: hello }`)
+
+			Convey("Then the body should be read as the query", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, `{"data":{"b":"world"}}`)
+			})
+		})
+
+		Convey("When the body is a mutation", func() {
+			w := serve("POST", "/graphql", "application/graphql", "", `mutation { hello }`)
+
+			Convey("Then it should be executed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldStartWith, `{"data":{"hello":null},"errors":`)
+			})
+		})
+
+		Convey("When the body isn't JSON", func() {
+			w := serve("POST", "/graphql", "application/json", "", `{`)
+
+			Convey("Then the request should be rejected as bad", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When the body is of another media type", func() {
+			w := serve("POST", "/graphql", "text/plain", "", `{ hello }`)
+
+			Convey("Then the request should be rejected as unsupported", func() {
+				So(w.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+			})
+		})
+
+		Convey("When the body isn't utf-8", func() {
+			w := serve("POST", "/graphql", "application/graphql; charset=latin1", "", `{ hello }`)
+
+			Convey("Then the request should be rejected as unsupported", func() {
+				So(w.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+			})
+		})
+	})
+
+	Convey("Given a request with another method", t, func() {
+		w := serve("PUT", "/graphql", "application/graphql", "", `{ hello }`)
+
+		Convey("Then it should be rejected and GET and POST advertised", func() {
+			So(w.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			So(w.Header().Get("Allow"), ShouldEqual, "GET, POST")
+		})
+	})
+}
+
+func TestContentNegotiation(t *testing.T) {
+	Convey("Given a query that doesn't parse", t, func() {
+		query := "/graphql?query=" + url.QueryEscape(`{ hello(id: ) }`)
+
+		Convey("When application/graphql-response+json is accepted", func() {
+			w := serve("GET", query, "", "application/json, application/graphql-response+json", "")
+
+			Convey("Then the response should be sent as it with a 400", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/graphql-response+json; charset=utf-8")
+				So(w.Body.String(), ShouldEqual, `{"errors":[{"message":"illegal value","locations":[{"line":1,"column":13}]}]}`)
+			})
+		})
+
+		Convey("When only application/json is accepted", func() {
+			w := serve("GET", query, "", "application/json", "")
+
+			Convey("Then the response should be sent with a 200", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+			})
+		})
+	})
+
+	Convey("Given a query that executes with errors", t, func() {
+		w := serve("GET", "/graphql?query="+url.QueryEscape(`{ missing }`), "", "application/graphql-response+json", "")
+
+		Convey("Then the response should be sent with a 200", func() {
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldStartWith, `{"data":{"missing":null},"errors":`)
+		})
+	})
+
+	Convey("Given a client that accepts neither media type", t, func() {
+		w := serve("GET", "/graphql?query=%7Bhello%7D", "", "application/xml, application/json;q=0", "")
+
+		Convey("Then the request should be rejected as not acceptable", func() {
+			So(w.Code, ShouldEqual, http.StatusNotAcceptable)
+		})
+	})
+
+	Convey("Given a client that accepts anything", t, func() {
+		w := serve("GET", "/graphql?query=%7Bhello%7D", "", "*/*", "")
+
+		Convey("Then the response should be application/json", func() {
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+		})
+	})
+}
+
+func TestGraphiQL(t *testing.T) {
+	Convey("Given a browser", t, func() {
+		accept := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+		Convey("When it opens the handler", func() {
+			w := serve("GET", "/graphql?query="+url.QueryEscape(`{ hello }`), "", accept, "")
+
+			Convey("Then the IDE should be served", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+				So(w.Header().Get("Vary"), ShouldEqual, "Accept")
+				So(w.Body.String(), ShouldContainSubstring, "<title>GraphiQL</title>")
+			})
+
+			Convey("Then the page should not load anything from elsewhere", func() {
+				So(w.Body.String(), ShouldNotContainSubstring, "http://")
+				So(w.Body.String(), ShouldNotContainSubstring, "https://")
+				So(w.Body.String(), ShouldNotContainSubstring, " src=")
+			})
+		})
+
+		Convey("When it posts a query", func() {
+			w := serve("POST", "/graphql", "application/json", accept, `{"query":"{ hello }"}`)
+
+			Convey("Then the query should be executed", func() {
+				So(w.Body.String(), ShouldEqual, `{"data":{"hello":"world"}}`)
+			})
+		})
+	})
+
+	Convey("Given a handler with the IDE disabled", t, func() {
+		req := httptest.NewRequest("GET", "/graphql?query=%7Bhello%7D", nil)
+		req.Header.Set("Accept", "text/html, */*")
+		w := httptest.NewRecorder()
+		h := New(graphql.New(mapq.New(map[string]interface{}{"hello": "world"})))
+		h.GraphiQL = false
+		h.ServeHTTP(w, req)
+
+		Convey("Then browsers should be sent JSON", func() {
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+			So(w.Body.String(), ShouldEqual, `{"data":{"hello":"world"}}`)
+		})
+	})
+}
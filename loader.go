@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// --[ Thunk ]--------------------------------------------------------
+
+// Thunk is a Field whose resolution is put off until the loaders of the request have been
+// flushed.  A resolver that asks a Loader for a key returns a Thunk that waits for the key's
+// value, so that the keys asked for by every field of a level are loaded in a single batch;
+// the executor calls the Thunk once the level has been resolved.
+type Thunk func() (Field, error)
+
+func (t Thunk) Selection() (Selection, error) {
+	field, err := t()
+	if err != nil || field == nil {
+		return nil, err
+	}
+	return field.Selection()
+}
+
+func (t Thunk) Value() (Value, error) {
+	field, err := t()
+	if err != nil || field == nil {
+		return nil, err
+	}
+	return field.Value()
+}
+
+// force calls thunks until it holds the field they stand for
+func force(field Field) (Field, error) {
+	for {
+		thunk, ok := field.(Thunk)
+		if !ok {
+			return field, nil
+		}
+
+		var err error
+		if field, err = thunk(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// --[ Loader ]-------------------------------------------------------
+
+// BatchFunc loads the values of several keys at once.  It returns a value for each key, and
+// optionally an error, in the order of keys; errs may be nil when every key was loaded.
+type BatchFunc func(ctx context.Context, keys []interface{}) (values []interface{}, errs []error)
+
+// Loader collects the keys asked of it and loads them with a single call to its BatchFunc.
+// Values are cached, so each key is loaded once however often it's asked for.  Loaders are
+// safe for concurrent use.
+type Loader struct {
+	ctx   context.Context
+	batch BatchFunc
+
+	mu      sync.Mutex
+	cache   map[interface{}]*load
+	pending []*load
+}
+
+// load holds a key and, once done is closed, its value
+type load struct {
+	key   interface{}
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewLoader returns a Loader that loads keys with batch within ctx.  Resolvers usually call
+// LoaderFor instead, which shares a loader among the fields of a request.
+func NewLoader(ctx context.Context, batch BatchFunc) *Loader {
+	return &Loader{
+		ctx:   ctx,
+		batch: batch,
+		cache: map[interface{}]*load{},
+	}
+}
+
+// Load queues key to be loaded with the next batch, unless it already has been, and returns
+// a function that waits for its value.  The function flushes the loader if the key hasn't
+// been loaded by the time it's called.
+func (l *Loader) Load(key interface{}) func() (interface{}, error) {
+	l.mu.Lock()
+	v, ok := l.cache[key]
+	if !ok {
+		v = &load{key: key, done: make(chan struct{})}
+		l.cache[key] = v
+		l.pending = append(l.pending, v)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		select {
+		case <-v.done:
+		default:
+			l.Flush()
+			<-v.done
+		}
+		return v.value, v.err
+	}
+}
+
+// Flush loads the keys queued since the loader was last flushed
+func (l *Loader) Flush() {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	keys := make([]interface{}, len(pending))
+	for index, v := range pending {
+		keys[index] = v.key
+	}
+
+	values, errs := l.batch(l.ctx, keys)
+	for index, v := range pending {
+		switch {
+		case index < len(errs) && errs[index] != nil:
+			v.err = errs[index]
+		case len(values) != len(keys):
+			v.err = fmt.Errorf("loader returned %v values for %v keys", len(values), len(keys))
+		default:
+			v.value = values[index]
+		}
+		close(v.done)
+	}
+}
+
+// loaders holds the loaders of a request by the key they were created for
+type loaders struct {
+	mu      sync.Mutex
+	loaders map[interface{}]*Loader
+}
+
+type loadersKey struct{}
+
+// LoaderFor returns the loader of the request executing within ctx that was created for key,
+// creating it with batch if the request doesn't yet have one; key is typically the store the
+// loader belongs to.  The executor flushes the loaders of a request each time the fields of
+// a level have been resolved, and the loaders cache their values until the request is done.
+// Outside of a request, LoaderFor returns a new loader each time it's called.
+func LoaderFor(ctx context.Context, key interface{}, batch BatchFunc) *Loader {
+	r, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		return NewLoader(ctx, batch)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.loaders[key]
+	if !ok {
+		l = NewLoader(ctx, batch)
+		r.loaders[key] = l
+	}
+	return l
+}
+
+func (r *loaders) all() []*Loader {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaders := make([]*Loader, 0, len(r.loaders))
+	for _, l := range r.loaders {
+		loaders = append(loaders, l)
+	}
+	return loaders
+}
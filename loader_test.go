@@ -0,0 +1,172 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// --[ people ]-------------------------------------------------------
+
+// people is a Store of people, each of whom has a manager.  Managers are loaded through a
+// loader and the batches it was asked to load are recorded.
+type people struct {
+	mu      sync.Mutex
+	batches [][]interface{}
+}
+
+type person struct {
+	store *people
+	id    int
+}
+
+func (p *people) Query(c *Context) (Field, error) {
+	return nil, errors.New("people must be queried with a context")
+}
+
+func (p *people) Mutate(c *Context) (Field, error) {
+	return nil, ErrNotImplemented
+}
+
+func (p *people) QueryContext(ctx context.Context, c *Context) (Field, error) {
+	return &team{store: p, ids: []int{1, 2, 3, 4}}, nil
+}
+
+func (p *people) managers(ctx context.Context, keys []interface{}) ([]interface{}, []error) {
+	p.mu.Lock()
+	p.batches = append(p.batches, keys)
+	p.mu.Unlock()
+
+	values := make([]interface{}, len(keys))
+	errs := make([]error, len(keys))
+	for index, key := range keys {
+		if id := key.(int); id < 0 {
+			errs[index] = fmt.Errorf("no manager for %v", -id)
+		} else {
+			values[index] = &person{store: p, id: id}
+		}
+	}
+	return values, errs
+}
+
+type team struct {
+	store *people
+	ids   []int
+}
+
+func (t *team) Selection() (Selection, error) { return nil, ErrNotAScalar }
+func (t *team) Value() (Value, error)         { return nil, ErrNotAScalar }
+
+func (t *team) Elements() ([]Field, error) {
+	fields := make([]Field, len(t.ids))
+	for index, id := range t.ids {
+		fields[index] = &person{store: t.store, id: id}
+	}
+	return fields, nil
+}
+
+// identifier is the id of a person written as a string
+type identifier int
+
+func (i identifier) Selection() (Selection, error) { return nil, ErrNotAScalar }
+func (i identifier) Value() (Value, error)         { return fmt.Sprint(int(i)), nil }
+
+func (p *person) Selection() (Selection, error) { return p, nil }
+func (p *person) Value() (Value, error)         { return nil, ErrNotAScalar }
+
+func (p *person) Query(c *Context) (Field, error) {
+	return nil, errors.New("people must be queried with a context")
+}
+
+func (p *person) QueryContext(ctx context.Context, c *Context) (Field, error) {
+	switch c.Name {
+	case "id":
+		return identifier(p.id), nil
+	case "manager":
+		// people 1 and 2 report to 10, 3 and 4 to 20 and 10 and 20 have no manager
+		id := 10 * ((p.id + 1) / 2)
+		if p.id >= 10 {
+			id = -p.id
+		}
+		load := LoaderFor(ctx, p.store, p.store.managers).Load(id)
+		return Thunk(func() (Field, error) {
+			manager, err := load()
+			if err != nil {
+				return nil, err
+			}
+			return manager.(Field), nil
+		}), nil
+	default:
+		return nil, ErrFieldNotFound
+	}
+}
+
+func TestLoader(t *testing.T) {
+	Convey("Given a list of people who each ask for their manager", t, func() {
+		store := &people{}
+		w := bytes.NewBuffer([]byte{})
+		err := New(store).Handle(`{ team { id manager { id manager { id } } } }`, w)
+
+		Convey("Then the managers of each level should be loaded in a single batch", func() {
+			So(store.batches, ShouldResemble, [][]interface{}{{10, 20}, {-10, -20}})
+		})
+
+		Convey("Then the response should be complete", func() {
+			So(err, ShouldNotBeNil)
+			So(w.String(), ShouldStartWith, `{"data":{"team":[{"id":"1","manager":{"id":"10","manager":null}},{"id":"2","manager":{"id":"10","manager":null}},{"id":"3","manager":{"id":"20","manager":null}},{"id":"4","manager":{"id":"20","manager":null}}]},"errors":[{"message":"no manager for 10"`)
+		})
+	})
+
+	Convey("Given an executor that resolves several fields at once", t, func() {
+		store := &people{}
+		executor := New(store)
+		executor.Concurrency = 4
+		w := bytes.NewBuffer([]byte{})
+		executor.Handle(`{ team { manager { id } } }`, w)
+
+		Convey("Then the managers should still be loaded in a single batch", func() {
+			So(len(store.batches), ShouldEqual, 1)
+			So(w.String(), ShouldEqual, `{"data":{"team":[{"manager":{"id":"10"}},{"manager":{"id":"10"}},{"manager":{"id":"20"}},{"manager":{"id":"20"}}]}}`)
+		})
+	})
+
+	Convey("Given a loader used outside of a request", t, func() {
+		calls := 0
+		batch := func(ctx context.Context, keys []interface{}) ([]interface{}, []error) {
+			calls++
+			return keys, nil
+		}
+		loader := LoaderFor(context.Background(), "key", batch)
+
+		a, b, c := loader.Load(1), loader.Load(2), loader.Load(1)
+		va, _ := a()
+		vb, _ := b()
+		vc, _ := c()
+
+		Convey("Then the keys queued before the first value was asked for should be loaded together", func() {
+			So(calls, ShouldEqual, 1)
+			So([]interface{}{va, vb, vc}, ShouldResemble, []interface{}{1, 2, 1})
+		})
+
+		Convey("Then cached keys should not be loaded again", func() {
+			loader.Load(2)()
+			So(calls, ShouldEqual, 1)
+		})
+	})
+
+	Convey("Given a batch that returns too few values", t, func() {
+		loader := NewLoader(context.Background(), func(ctx context.Context, keys []interface{}) ([]interface{}, []error) {
+			return nil, nil
+		})
+		_, err := loader.Load(1)()
+
+		Convey("Then each key should fail", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -13,10 +13,15 @@ type Field struct {
 }
 
 func (f Field) Selection() (graphql.Selection, error) {
+	if string(f.data) == "null" {
+		return nil, nil
+	}
 	s, err := New(f.data)
 	return s, err
 }
 
+// Value decodes the json scalar.  Integers are returned as int64 so that large ids keep
+// their precision; other numbers are returned as float64.
 func (f Field) Value() (graphql.Value, error) {
 	switch f.data[0] {
 	case '"':
@@ -24,19 +29,79 @@ func (f Field) Value() (graphql.Value, error) {
 		err := json.Unmarshal(f.data, &s)
 		return s, err
 
-	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-		var i float32
-		err := json.Unmarshal(f.data, &i)
-		return i, err
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		var n json.Number
+		if err := json.Unmarshal(f.data, &n); err != nil {
+			return nil, err
+		}
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+		return n.Float64()
 
-	case '{', '[':
-		return nil, graphql.ErrNotAScalar
+	case 't', 'f':
+		var b bool
+		err := json.Unmarshal(f.data, &b)
+		return b, err
+
+	case 'n':
+		return nil, nil
 
 	default:
 		return nil, graphql.ErrNotAScalar
 	}
 }
 
+// newField returns a List for json arrays and a Field for any other json value
+func newField(data json.RawMessage) graphql.Field {
+	if len(data) > 0 && data[0] == '[' {
+		return List{data: data}
+	}
+	return Field{data: data}
+}
+
+// --[ List ]-------------------------------------------------------------
+
+// List holds a json array.  When the query selects fields of its elements, the selection is
+// applied to each element; otherwise the array's values are the value of the field.
+type List struct {
+	data json.RawMessage
+}
+
+func (l List) Selection() (graphql.Selection, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+func (l List) Value() (graphql.Value, error) {
+	elements, err := l.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(elements))
+	for index, element := range elements {
+		v, err := element.Value()
+		if err != nil {
+			return nil, err
+		}
+		values[index] = v
+	}
+	return values, nil
+}
+
+func (l List) Elements() ([]graphql.Field, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(l.data, &items); err != nil {
+		return nil, err
+	}
+
+	fields := make([]graphql.Field, len(items))
+	for index, item := range items {
+		fields[index] = newField(item)
+	}
+	return fields, nil
+}
+
 // --[ Store ]------------------------------------------------------------
 
 type Store struct {
@@ -59,7 +124,23 @@ func (s Store) Query(c *graphql.Context) (graphql.Field, error) {
 		return nil, graphql.ErrFieldNotFound
 	}
 
-	return Field{data: v}, nil
+	return newField(v), nil
+}
+
+// TypeName reports the concrete type of the object from its __typename key, if present
+func (s Store) TypeName() string {
+	var typeName string
+	if data, ok := s.props["__typename"]; ok {
+		json.Unmarshal(data, &typeName)
+	}
+	return typeName
+}
+
+// Satisfies reports whether the object's __typename matches the type condition.  Objects
+// without a __typename satisfy every condition.
+func (s Store) Satisfies(typeCondition string) bool {
+	typeName := s.TypeName()
+	return typeName == "" || typeName == typeCondition
 }
 
 func (s Store) Mutate(c *graphql.Context) (graphql.Field, error) {
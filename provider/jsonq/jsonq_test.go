@@ -1,6 +1,7 @@
 package jsonq
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/savaki/graphql"
@@ -44,3 +45,56 @@ func TestParse(t *testing.T) {
 		}
 	})
 }
+
+func TestLists(t *testing.T) {
+	Convey("Given a json block holding arrays", t, func() {
+		data := []byte(`{
+			"users": [
+				{ "name": "Adam", "tags": ["a", "b"] },
+				null,
+				{ "name": "Eve", "tags": [] }
+			],
+			"matrix": [[{ "name": "x" }], []]
+		}`)
+
+		store, err := New(data)
+		So(err, ShouldBeNil)
+
+		Convey("When I select fields of the elements", func() {
+			buf := bytes.NewBuffer([]byte{})
+			err := graphql.New(store).Handle(`{ users { name tags } matrix { name } }`, buf)
+
+			Convey("Then the selection should be applied to each element", func() {
+				So(err, ShouldBeNil)
+				So(buf.String(), ShouldEqual, `{"data":{"users":[{"name":"Adam","tags":["a","b"]},null,{"name":"Eve","tags":[]}],"matrix":[[{"name":"x"}],[]]}}`)
+			})
+		})
+	})
+}
+
+func TestScalars(t *testing.T) {
+	Convey("Given a json block holding each kind of scalar", t, func() {
+		data := []byte(`{
+			"id": 16777217,
+			"big": 9007199254740993,
+			"negative": -42,
+			"ratio": 0.25,
+			"active": true,
+			"deleted": false,
+			"parent": null
+		}`)
+
+		store, err := New(data)
+		So(err, ShouldBeNil)
+
+		Convey("When I query them", func() {
+			buf := bytes.NewBuffer([]byte{})
+			err := graphql.New(store).Handle(`{ id big negative ratio active deleted parent }`, buf)
+
+			Convey("Then integers should keep their precision", func() {
+				So(err, ShouldBeNil)
+				So(buf.String(), ShouldEqual, `{"data":{"id":16777217,"big":9007199254740993,"negative":-42,"ratio":0.25,"active":true,"deleted":false,"parent":null}}`)
+			})
+		})
+	})
+}
@@ -14,7 +14,7 @@ func ExampleMap() {
 	model := map[string]interface{}{"hello": "world"}
 	store := mapq.New(model)
 	graphql.New(store).Handle(`{hello}`, os.Stdout)
-	// prints {"hello":"world"}
+	// prints {"data":{"hello":"world"}}
 }
 
 func TestHelloWorld(t *testing.T) {
@@ -25,6 +25,6 @@ func TestHelloWorld(t *testing.T) {
 		err := graphql.New(store).Handle(`{hello}`, buf)
 
 		So(err, ShouldBeNil)
-		So(buf.String(), ShouldEqual, `{"hello":"world"}`)
+		So(buf.String(), ShouldEqual, `{"data":{"hello":"world"}}`)
 	})
 }
@@ -2,6 +2,7 @@ package mapq
 
 import (
 	"errors"
+	"reflect"
 
 	"github.com/savaki/graphql"
 )
@@ -23,6 +24,8 @@ func (f *field) Value() (graphql.Value, error) {
 
 func (f *field) Selection() (graphql.Selection, error) {
 	switch v := f.value.(type) {
+	case nil:
+		return nil, nil
 	case map[string]interface{}:
 		return &selection{data: v}, nil
 	}
@@ -30,6 +33,46 @@ func (f *field) Selection() (graphql.Selection, error) {
 	return nil, errNotImplemented
 }
 
+// newField returns a list for slices and arrays, other than []byte, and a field otherwise
+func newField(value interface{}) graphql.Field {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		if _, ok := value.([]byte); !ok {
+			return &list{value: value}
+		}
+	}
+	return &field{value: value}
+}
+
+// --[ List ]-------------------------------------------------------------
+
+// list holds a slice.  When the query selects fields of its elements, the selection is
+// applied to each element; otherwise the slice is the value of the field.
+type list struct {
+	value interface{}
+}
+
+func (l *list) Value() (graphql.Value, error) {
+	return l.value, nil
+}
+
+func (l *list) Selection() (graphql.Selection, error) {
+	return nil, errNotImplemented
+}
+
+func (l *list) Elements() ([]graphql.Field, error) {
+	v := reflect.ValueOf(l.value)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return nil, nil
+	}
+
+	fields := make([]graphql.Field, v.Len())
+	for index := range fields {
+		fields[index] = newField(v.Index(index).Interface())
+	}
+	return fields, nil
+}
+
 // --[ Store / Selection ]------------------------------------------------
 
 type selection struct {
@@ -42,12 +85,25 @@ func New(data map[string]interface{}) graphql.Store {
 	}
 }
 
+// TypeName reports the concrete type of the map from its __typename key, if present
+func (s *selection) TypeName() string {
+	typeName, _ := s.data["__typename"].(string)
+	return typeName
+}
+
+// Satisfies reports whether the map's __typename matches the type condition.  Maps without
+// a __typename satisfy every condition.
+func (s *selection) Satisfies(typeCondition string) bool {
+	typeName := s.TypeName()
+	return typeName == "" || typeName == typeCondition
+}
+
 func (s *selection) Query(c *graphql.Context) (graphql.Field, error) {
 	v, ok := s.data[c.Name]
 	if !ok {
 		return nil, errFieldNotFound
 	}
-	return &field{value: v}, nil
+	return newField(v), nil
 }
 
 func (s *selection) Mutate(c *graphql.Context) (graphql.Field, error) {
@@ -26,14 +26,14 @@ func TestStore(t *testing.T) {
 		store := New(data)
 
 		buf := bytes.NewBuffer([]byte{})
-		query := `query bill { friends }`
+		query := `query bill { bill { friends } }`
 		err := graphql.New(store).Handle(query, buf)
 
-		v := map[string]map[string][]string{}
+		v := map[string]map[string]map[string][]string{}
 		err = json.Unmarshal(buf.Bytes(), &v)
 		So(err, ShouldBeNil)
 
-		So(v, ShouldResemble, map[string]map[string][]string{
+		So(v["data"], ShouldResemble, map[string]map[string][]string{
 			"bill": map[string][]string{
 				"friends": friends,
 			},
@@ -41,6 +41,76 @@ func TestStore(t *testing.T) {
 	})
 }
 
+func TestFragments(t *testing.T) {
+	Convey("Verify fragment spreads are expanded against the map store", t, func() {
+		data := map[string]interface{}{
+			"user": map[string]interface{}{
+				"id":   "123",
+				"name": "Bill",
+				"age":  42,
+			},
+		}
+		store := New(data)
+
+		buf := bytes.NewBuffer([]byte{})
+		query := `{ user { id ...userFields } }
+
+		fragment userFields on User {
+			name
+			id
+		}`
+		err := graphql.New(store).Handle(query, buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{"data":{"user":{"id":"123","name":"Bill"}}}`)
+	})
+}
+
+func TestInlineFragments(t *testing.T) {
+	Convey("Verify inline fragments only apply to the matching __typename", t, func() {
+		data := map[string]interface{}{
+			"hero": map[string]interface{}{
+				"__typename":      "Droid",
+				"name":            "R2-D2",
+				"primaryFunction": "Astromech",
+			},
+		}
+		store := New(data)
+
+		buf := bytes.NewBuffer([]byte{})
+		query := `{
+			hero {
+				name
+				... on Droid { primaryFunction }
+				... on Human { homePlanet }
+			}
+		}`
+		err := graphql.New(store).Handle(query, buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{"data":{"hero":{"name":"R2-D2","primaryFunction":"Astromech"}}}`)
+	})
+}
+
+func TestLists(t *testing.T) {
+	Convey("Verify selections are applied to each element of a list", t, func() {
+		data := map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "Bill", "tags": []string{"a", "b"}},
+				nil,
+				map[string]interface{}{"name": "Jen", "tags": []string{}},
+			},
+			"teams": []map[string]interface{}{
+				{"members": [][]map[string]interface{}{{{"name": "Joe"}}}},
+			},
+		}
+		store := New(data)
+
+		buf := bytes.NewBuffer([]byte{})
+		err := graphql.New(store).Handle(`{ users { name tags } teams { members { name } } }`, buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{"data":{"users":[{"name":"Bill","tags":["a","b"]},null,{"name":"Jen","tags":[]}],"teams":[{"members":[[{"name":"Joe"}]]}]}}`)
+	})
+}
+
 func BenchmarkStore(b *testing.B) {
 	friends := []string{
 		"james",
@@ -58,7 +128,7 @@ func BenchmarkStore(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		buf.Reset()
-		query := `query bill { friends }`
+		query := `{ bill { friends } }`
 		err := graphql.New(store).Handle(query, buf)
 		if err != nil {
 			log.Fatalln(err)
@@ -1,9 +1,11 @@
 package restq
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"errors"
+	"sync"
 
 	"github.com/savaki/graphql"
 	"github.com/savaki/graphql/provider/jsonq"
@@ -24,21 +26,54 @@ func (s *Store) Mutate(c *graphql.Context) (graphql.Field, error) {
 }
 
 func (s *Store) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+// QueryContext issues the request described by the field.  The request is cancelled when ctx
+// is, so a slow upstream doesn't outlive the query that asked for it.  Requests are batched
+// with the other fields of the level and identical requests are issued once.
+func (s *Store) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
 	switch c.Name {
 	case "GET", "get":
-		selection, err := s.get(c.Args[0].Value.(string))
-		if err != nil {
-			return nil, err
-		}
-		return field{selection: selection}, nil
+		load := graphql.LoaderFor(ctx, s, s.getAll).Load(c.Args[0].Value.(string))
+		return graphql.Thunk(func() (graphql.Field, error) {
+			selection, err := load()
+			if err != nil {
+				return nil, err
+			}
+			return field{selection: selection.(graphql.Selection)}, nil
+		}), nil
 
 	default:
 		return nil, errors.New("Query only supports the GET method")
 	}
 }
 
-func (s *Store) get(url string) (graphql.Selection, error) {
-	resp, err := s.Client.Get(url)
+// getAll issues a GET for each url at once
+func (s *Store) getAll(ctx context.Context, urls []interface{}) ([]interface{}, []error) {
+	values := make([]interface{}, len(urls))
+	errs := make([]error, len(urls))
+
+	wg := &sync.WaitGroup{}
+	for index, url := range urls {
+		wg.Add(1)
+		go func(index int, url string) {
+			defer wg.Done()
+			values[index], errs[index] = s.get(ctx, url)
+		}(index, url.(string))
+	}
+	wg.Wait()
+
+	return values, errs
+}
+
+func (s *Store) get(ctx context.Context, url string) (graphql.Selection, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,79 @@
+package restq
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/savaki/graphql"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGet(t *testing.T) {
+	Convey("Given a rest service", t, func() {
+		release := make(chan struct{})
+		requests := map[string]int{}
+		mu := &sync.Mutex{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests[r.URL.Path]++
+			mu.Unlock()
+
+			if r.URL.Path == "/slow" {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			}
+			w.Write([]byte(`{"name":"London"}`))
+		}))
+		defer server.Close()
+		defer close(release)
+
+		executor := graphql.New(New())
+		buf := bytes.NewBuffer(nil)
+
+		Convey("When I query it", func() {
+			err := executor.Handle(`query city: GET(url: "`+server.URL+`/fast") { name }`, buf)
+
+			Convey("Then I expect the response to be returned", func() {
+				So(err, ShouldBeNil)
+				So(buf.String(), ShouldEqual, `{"data":{"city":{"name":"London"}}}`)
+			})
+		})
+
+		Convey("When several fields GET the same url", func() {
+			query := `{
+				a: GET(url: "` + server.URL + `/fast") { name }
+				b: GET(url: "` + server.URL + `/fast") { name }
+				c: GET(url: "` + server.URL + `/other") { name }
+			}`
+			err := executor.Handle(query, buf)
+
+			Convey("Then I expect each url to be requested once", func() {
+				So(err, ShouldBeNil)
+				So(buf.String(), ShouldEqual, `{"data":{"a":{"name":"London"},"b":{"name":"London"},"c":{"name":"London"}}}`)
+				So(requests, ShouldResemble, map[string]int{"/fast": 1, "/other": 1})
+			})
+		})
+
+		Convey("When the query's context expires before the service replies", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			started := time.Now()
+			err := executor.HandleContext(ctx, `query city: GET(url: "`+server.URL+`/slow") { name }`, "", nil, buf)
+
+			Convey("Then I expect the request to be abandoned", func() {
+				So(err, ShouldNotBeNil)
+				So(time.Since(started), ShouldBeLessThan, 5*time.Second)
+				So(buf.String(), ShouldStartWith, `{"data":{"city":null},"errors":[{"message":"Get`)
+				So(buf.String(), ShouldContainSubstring, "context deadline exceeded")
+			})
+		})
+	})
+}
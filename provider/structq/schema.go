@@ -0,0 +1,367 @@
+package structq
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/savaki/graphql/schema"
+)
+
+// --[ Generator ]--------------------------------------------------------
+
+// Generator derives a schema from Go types so that they're the one description of the API.
+// Types map as follows
+//
+//	bool                        Boolean
+//	signed and unsigned ints    Int; ID for fields named id
+//	float32 and float64         Float
+//	string                      String; ID for fields named id
+//	named strings               an enum of the values declared with Enum
+//	slices and arrays           a list of the element type
+//	structs                     an object type named after the struct; an input object,
+//	                            named with an Input suffix, when used for arguments
+//
+// Fields that can't be nil, i.e. anything other than pointers and slices, are
+// non-null; arguments too, so optional arguments are declared as pointers.  The fields of
+// an object are the fields and methods structq resolves, described by their description
+// tag; the arguments of a method are the fields of its argument struct.  Methods that take
+// []graphql.Arg can't be described and are reported as an error.
+type Generator struct {
+	scalars map[reflect.Type]*schema.ScalarType
+	enums   map[reflect.Type]*schema.EnumType
+	objects map[reflect.Type]*schema.ObjectType
+	inputs  map[reflect.Type]*schema.InputObjectType
+	names   map[string]reflect.Type
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		scalars: map[reflect.Type]*schema.ScalarType{},
+		enums:   map[reflect.Type]*schema.EnumType{},
+		objects: map[reflect.Type]*schema.ObjectType{},
+		inputs:  map[reflect.Type]*schema.InputObjectType{},
+		names:   map[string]reflect.Type{},
+	}
+}
+
+// Generate derives a schema from the types of query and mutation, which may be nil, using
+// a Generator without enums or custom scalars
+func Generate(query, mutation interface{}) (*schema.Schema, error) {
+	return NewGenerator().Generate(query, mutation)
+}
+
+// Enum declares the values of a named string type, which is then described as an enum whose
+// values are named by the strings they hold.  Enum panics if the values aren't all of the
+// same named string type.
+func (g *Generator) Enum(values ...interface{}) {
+	if len(values) == 0 {
+		panic("structq: Enum requires at least one value")
+	}
+
+	t := reflect.TypeOf(values[0])
+	if t.Kind() != reflect.String || t.PkgPath() == "" {
+		panic(fmt.Sprintf("structq: enum values must be of a named string type, got %v", t))
+	}
+
+	enum := &schema.EnumType{Name: t.Name()}
+	for _, value := range values {
+		if reflect.TypeOf(value) != t {
+			panic(fmt.Sprintf("structq: enum values must all be of type %v, got %T", t, value))
+		}
+		enum.Values = append(enum.Values, &schema.EnumValue{
+			Name:  reflect.ValueOf(value).String(),
+			Value: value,
+		})
+	}
+	g.enums[t] = enum
+}
+
+// Scalar describes values of the same type as value, e.g. time.Time{}, with the scalar
+func (g *Generator) Scalar(value interface{}, scalar *schema.ScalarType) {
+	g.scalars[reflect.TypeOf(value)] = scalar
+}
+
+// Generate derives a schema from the types of query and mutation, structs or pointers to
+// them.  mutation may be nil.  The schema is resolved by the fields and methods of the
+// values handed to schema.NewStore, as structq would resolve them; as the store hands the
+// same root value to queries and mutations, it may be a struct embedding both root types.
+func (g *Generator) Generate(query, mutation interface{}) (*schema.Schema, error) {
+	s := &schema.Schema{}
+
+	var err error
+	if s.Query, err = g.root(query); err != nil {
+		return nil, err
+	}
+	if mutation != nil {
+		if s.Mutation, err = g.root(mutation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (g *Generator) root(value interface{}) (*schema.ObjectType, error) {
+	t := reflect.TypeOf(value)
+	if t == nil || structType(t) == nil {
+		return nil, fmt.Errorf("structq: the root of a schema must be a struct, got %v", t)
+	}
+	return g.object(structType(t))
+}
+
+// --[ Output Types ]-----------------------------------------------------
+
+// object returns the object type of the struct t, describing it the first time it's seen
+func (g *Generator) object(t reflect.Type) (*schema.ObjectType, error) {
+	if o, ok := g.objects[t]; ok {
+		return o, nil
+	}
+	if err := g.claim(t.Name(), t); err != nil {
+		return nil, err
+	}
+
+	// registered before its fields so that types may refer to one another
+	o := &schema.ObjectType{Name: t.Name()}
+	g.objects[t] = o
+
+	info := typeOf(t)
+	for _, sf := range info.fields {
+		if _, ok := info.method(sf.name); ok {
+			continue
+		}
+		typ, err := g.describe(sf.typ, false)
+		if err != nil {
+			return nil, fmt.Errorf("%v.%v => %v", t.Name(), sf.name, err)
+		}
+		o.Fields = append(o.Fields, &schema.Field{
+			Name:        sf.name,
+			Description: sf.description,
+			Type:        idType(sf.name, typ),
+			Resolve:     resolveField(sf.index),
+		})
+	}
+
+	for _, m := range info.methods {
+		f, err := g.method(t, m)
+		if err != nil {
+			return nil, err
+		}
+		o.Fields = append(o.Fields, f)
+	}
+
+	if len(o.Fields) == 0 {
+		return nil, fmt.Errorf("%v has no fields", t.Name())
+	}
+	return o, nil
+}
+
+func (g *Generator) method(t reflect.Type, m *method) (*schema.Field, error) {
+	typ, err := g.describe(m.typ, false)
+	if err != nil {
+		return nil, fmt.Errorf("%v.%v => %v", t.Name(), m.name, err)
+	}
+
+	f := &schema.Field{
+		Name:           m.name,
+		Type:           idType(m.name, typ),
+		ResolveContext: resolveMethod(m),
+	}
+
+	switch {
+	case m.args == nil:
+	case m.args == argsType:
+		return nil, fmt.Errorf("%v.%v => arguments of type []graphql.Arg can't be described; use a struct", t.Name(), m.name)
+	default:
+		if f.Args, err = g.inputValues(structType(m.args)); err != nil {
+			return nil, fmt.Errorf("%v.%v => %v", t.Name(), m.name, err)
+		}
+	}
+	return f, nil
+}
+
+// --[ Input Types ]------------------------------------------------------
+
+// inputObject returns the input object type of the struct t
+func (g *Generator) inputObject(t reflect.Type) (*schema.InputObjectType, error) {
+	if o, ok := g.inputs[t]; ok {
+		return o, nil
+	}
+
+	name := t.Name()
+	if !strings.HasSuffix(name, "Input") {
+		name += "Input"
+	}
+	if err := g.claim(name, t); err != nil {
+		return nil, err
+	}
+
+	o := &schema.InputObjectType{Name: name}
+	g.inputs[t] = o
+
+	fields, err := g.inputValues(t)
+	if err != nil {
+		return nil, fmt.Errorf("%v => %v", name, err)
+	}
+	o.Fields = fields
+	return o, nil
+}
+
+// inputValues describes the fields of the struct t as arguments or input fields
+func (g *Generator) inputValues(t reflect.Type) ([]*schema.InputValue, error) {
+	var values []*schema.InputValue
+	for _, sf := range typeOf(t).fields {
+		typ, err := g.describe(sf.typ, true)
+		if err != nil {
+			return nil, fmt.Errorf("%v => %v", sf.name, err)
+		}
+		values = append(values, &schema.InputValue{
+			Name:        sf.name,
+			Description: sf.description,
+			Type:        idType(sf.name, typ),
+		})
+	}
+	return values, nil
+}
+
+// --[ Type Mapping ]-----------------------------------------------------
+
+// describe returns the type of a field, or of an argument or input field when input is
+// set, whose values are of the Go type t
+func (g *Generator) describe(t reflect.Type, input bool) (schema.Type, error) {
+	nullable := false
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var typ schema.Type
+	switch {
+	case g.scalars[t] != nil:
+		typ = g.scalars[t]
+
+	case g.enums[t] != nil:
+		typ = g.enums[t]
+
+	case t.Kind() == reflect.Bool:
+		typ = schema.Boolean
+
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		typ = schema.Int
+
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		typ = schema.Float
+
+	case t.Kind() == reflect.String:
+		typ = schema.String
+
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return nil, fmt.Errorf("anonymous structs can't be described")
+		}
+		var err error
+		if input {
+			typ, err = g.inputObject(t)
+		} else {
+			typ, err = g.object(t)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8:
+		elem, err := g.describe(t.Elem(), input)
+		if err != nil {
+			return nil, err
+		}
+		typ = &schema.List{OfType: elem}
+		nullable = nullable || t.Kind() == reflect.Slice
+
+	default:
+		return nil, fmt.Errorf("values of type %v can't be described", t)
+	}
+
+	if nullable {
+		return typ, nil
+	}
+	return &schema.NonNull{OfType: typ}, nil
+}
+
+// claim reserves a type name for the Go type t
+func (g *Generator) claim(name string, t reflect.Type) error {
+	if other, ok := g.names[name]; ok && other != t {
+		return fmt.Errorf("%v and %v would both be named %v", other, t, name)
+	}
+	g.names[name] = t
+	return nil
+}
+
+// idType describes fields named id that hold an Int or String as an ID
+func idType(name string, t schema.Type) schema.Type {
+	if name != "id" {
+		return t
+	}
+	switch v := t.(type) {
+	case *schema.NonNull:
+		return &schema.NonNull{OfType: idType(name, v.OfType)}
+	case *schema.ScalarType:
+		if v == schema.Int || v == schema.String {
+			return schema.ID
+		}
+	}
+	return t
+}
+
+// --[ Resolvers ]--------------------------------------------------------
+
+// resolveField reads the field of the struct at index
+func resolveField(index []int) schema.ResolveFunc {
+	return func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		v := indirect(reflect.ValueOf(source))
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("expected a struct, got %T", source)
+		}
+		return value(fieldByIndex(v, index)), nil
+	}
+}
+
+// resolveMethod calls the method with the field's arguments decoded into its argument struct
+func resolveMethod(m *method) schema.ResolveContextFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		// the method is found by name as the source may be a struct that embeds its type
+		var fn reflect.Value
+		if s, ok := newSelection(reflect.ValueOf(source)); ok && s.value.Kind() == reflect.Ptr {
+			fn = s.value.MethodByName(m.goName)
+		}
+		if !fn.IsValid() {
+			return nil, fmt.Errorf("%T has no method %v", source, m.goName)
+		}
+
+		var arg reflect.Value
+		if m.args != nil {
+			var err error
+			if arg, err = decodeValues(m.args, args); err != nil {
+				return nil, err
+			}
+		}
+
+		out, err := m.invoke(ctx, fn, arg)
+		if err != nil {
+			return nil, err
+		}
+		return value(out), nil
+	}
+}
+
+// value returns the value v holds, following pointers, or nil
+func value(v reflect.Value) interface{} {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
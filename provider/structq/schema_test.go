@@ -0,0 +1,202 @@
+package structq
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/savaki/graphql"
+	"github.com/savaki/graphql/schema"
+	"github.com/savaki/graphql/validation"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type Episode string
+
+const (
+	NewHope Episode = "NEWHOPE"
+	Empire  Episode = "EMPIRE"
+)
+
+type Droid struct {
+	ID        int64
+	Name      string `description:"What others call it."`
+	AppearsIn []Episode
+	Friends   []*Droid
+	Model     *string
+}
+
+type Review struct {
+	Episode    Episode
+	Stars      int
+	Commentary *string
+}
+
+type ReviewInput struct {
+	Stars      int
+	Commentary *string
+}
+
+type Query struct {
+	droids []Droid
+}
+
+func (q *Query) Droid(ctx context.Context, args struct{ ID int64 }) (*Droid, error) {
+	for index, droid := range q.droids {
+		if droid.ID == args.ID {
+			return &q.droids[index], nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *Query) Hero(ctx context.Context, args struct{ Episode *Episode }) (Droid, error) {
+	if args.Episode != nil && *args.Episode == Empire {
+		return q.droids[1], nil
+	}
+	return q.droids[0], nil
+}
+
+type Mutation struct {
+	reviews []Review
+}
+
+func (m *Mutation) CreateReview(ctx context.Context, args struct {
+	Episode Episode
+	Review  ReviewInput
+}) (*Review, error) {
+	review := Review{Episode: args.Episode, Stars: args.Review.Stars, Commentary: args.Review.Commentary}
+	m.reviews = append(m.reviews, review)
+	return &review, nil
+}
+
+type Lookup struct{}
+
+func (l *Lookup) Find(ctx context.Context, args []graphql.Arg) (string, error) {
+	return "", nil
+}
+
+// root holds the values of both root types, as schema.NewStore hands the same source to
+// the query and mutation fields
+type root struct {
+	Query
+	Mutation
+}
+
+func generate() (*schema.Schema, error) {
+	g := NewGenerator()
+	g.Enum(NewHope, Empire)
+	return g.Generate(Query{}, Mutation{})
+}
+
+func TestGenerate(t *testing.T) {
+	Convey("Given Go types", t, func() {
+		s, err := generate()
+		So(err, ShouldBeNil)
+
+		Convey("When I print the schema derived from them", func() {
+			sdl := s.String()
+
+			Convey("Then each type should be described", func() {
+				So(sdl, ShouldEqual, `type Droid {
+  id: ID!
+  """What others call it."""
+  name: String!
+  appearsIn: [Episode!]
+  friends: [Droid]
+  model: String
+}
+
+enum Episode {
+  NEWHOPE
+  EMPIRE
+}
+
+type Mutation {
+  createReview(episode: Episode!, review: ReviewInput!): Review
+}
+
+type Query {
+  droid(id: ID!): Droid
+  hero(episode: Episode): Droid!
+}
+
+type Review {
+  episode: Episode!
+  stars: Int!
+  commentary: String
+}
+
+input ReviewInput {
+  stars: Int!
+  commentary: String
+}
+`)
+			})
+		})
+
+		Convey("When I execute queries against it", func() {
+			model := "astromech"
+			value := &root{Query: Query{droids: []Droid{
+				{ID: 2001, Name: "R2-D2", AppearsIn: []Episode{NewHope, Empire}, Model: &model},
+				{ID: 2000, Name: "C-3PO"},
+			}}}
+			value.droids[0].Friends = []*Droid{&value.droids[1]}
+
+			executor := graphql.Executor{
+				Store:     schema.NewStore(s, value),
+				Validator: validation.New(s),
+			}
+			execute := func(query string) string {
+				w := &bytes.Buffer{}
+				executor.Handle(query, w)
+				return w.String()
+			}
+
+			Convey("Then fields and methods should be resolved", func() {
+				So(execute(`{ droid(id: "2001") { id name appearsIn model friends { name model } } hero(episode: EMPIRE) { name } }`), ShouldEqual,
+					`{"data":{"droid":{"id":"2001","name":"R2-D2","appearsIn":["NEWHOPE","EMPIRE"],"model":"astromech","friends":[{"name":"C-3PO","model":null}]},"hero":{"name":"C-3PO"}}}`)
+			})
+
+			Convey("Then input objects should be decoded into argument structs", func() {
+				So(execute(`mutation { createReview(episode: NEWHOPE, review: { stars: 5 }) { episode stars commentary } }`), ShouldEqual,
+					`{"data":{"createReview":{"episode":"NEWHOPE","stars":5,"commentary":null}}}`)
+				So(value.reviews, ShouldResemble, []Review{{Episode: NewHope, Stars: 5}})
+			})
+
+			Convey("Then queries that don't fit the schema should be rejected", func() {
+				So(execute(`{ hero(episode: JEDI) { name } }`), ShouldStartWith, `{"errors":[{"message":`)
+				So(execute(`{ droid { name } }`), ShouldStartWith, `{"errors":[{"message":`)
+			})
+
+			Convey("Then the schema should be introspectable", func() {
+				So(execute(`{ __type(name: "Episode") { kind enumValues { name } } }`), ShouldEqual,
+					`{"data":{"__type":{"kind":"ENUM","enumValues":[{"name":"NEWHOPE"},{"name":"EMPIRE"}]}}}`)
+			})
+		})
+	})
+
+	Convey("Given a method that takes []graphql.Arg", t, func() {
+		_, err := Generate(Lookup{}, nil)
+
+		Convey("Then it should be reported as an error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Lookup.find")
+		})
+	})
+
+	Convey("Given a field whose type can't be described", t, func() {
+		_, err := Generate(struct{ Tags map[string]string }{}, nil)
+
+		Convey("Then it should be reported as an error", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given values that aren't of a named string type", t, func() {
+		Convey("Then Enum should panic", func() {
+			So(func() { NewGenerator().Enum("a", "b") }, ShouldPanic)
+			So(func() { NewGenerator().Enum(NewHope, 1) }, ShouldPanic)
+		})
+	})
+}
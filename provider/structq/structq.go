@@ -0,0 +1,218 @@
+// Package structq exposes Go values, structs, pointers, slices and maps with string keys,
+// as a graphql.Store.
+//
+// Fields of a struct are named by their graphql tag or, without one, by their Go name with
+// the leading capitals lowered, e.g. Name is queried as name and URLPath as urlPath.  Fields
+// tagged graphql:"-" and unexported fields are hidden; the fields of embedded structs are
+// promoted as they are in Go.
+//
+// Methods of the form
+//
+//	func (t *T) Name(ctx context.Context, args A) (R, error)
+//	func (t *T) Name(ctx context.Context) (R, error)
+//
+// are fields too; their arguments are decoded into A, which may be a struct, a pointer to a
+// struct or []graphql.Arg.  The fields of an argument struct are named as the fields of any
+// other struct.  A method takes precedence over a field of the same name.
+//
+// A Generator derives a schema from the same types, so that queries may be validated and
+// introspected and the API printed as schema definition language.
+package structq
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/savaki/graphql"
+)
+
+// --[ Field ]------------------------------------------------------------
+
+// field holds a value; the zero Value stands for null
+type field struct {
+	value reflect.Value
+}
+
+func (f *field) Value() (graphql.Value, error) {
+	if !f.value.IsValid() {
+		return nil, nil
+	}
+	return f.value.Interface(), nil
+}
+
+func (f *field) Selection() (graphql.Selection, error) {
+	if !f.value.IsValid() {
+		return nil, nil
+	}
+	if s, ok := newSelection(f.value); ok {
+		return s, nil
+	}
+	return nil, graphql.ErrNotAScalar
+}
+
+// newField returns a list for slices and arrays, other than []byte, and a field otherwise.
+// Pointers and interfaces are followed to the value they hold.
+func newField(v reflect.Value) graphql.Field {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return &list{value: v}
+		}
+	}
+	return &field{value: v}
+}
+
+// indirect follows pointers and interfaces; the result is the zero Value if it meets nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// --[ List ]-------------------------------------------------------------
+
+// list holds a slice or an array.  When the query selects fields of its elements, the
+// selection is applied to each element; otherwise the list is the value of the field.
+type list struct {
+	value reflect.Value
+}
+
+func (l *list) Value() (graphql.Value, error) {
+	return l.value.Interface(), nil
+}
+
+func (l *list) Selection() (graphql.Selection, error) {
+	return nil, graphql.ErrNotAScalar
+}
+
+func (l *list) Elements() ([]graphql.Field, error) {
+	if l.value.Kind() == reflect.Slice && l.value.IsNil() {
+		return nil, nil
+	}
+
+	fields := make([]graphql.Field, l.value.Len())
+	for index := range fields {
+		fields[index] = newField(l.value.Index(index))
+	}
+	return fields, nil
+}
+
+// --[ Store / Selection ]------------------------------------------------
+
+// selection holds a pointer to a struct or a map with string keys
+type selection struct {
+	value reflect.Value
+}
+
+// New returns a Store whose root fields are the fields and methods of value, a struct or a
+// pointer to one, or the entries of value, a map with string keys.  Mutations call the
+// methods of value just as queries do.
+func New(value interface{}) graphql.Store {
+	s, ok := newSelection(reflect.ValueOf(value))
+	if !ok {
+		return &selection{}
+	}
+	return s
+}
+
+// newSelection returns a selection for structs and maps with string keys.  Structs are held
+// by pointer so that methods with pointer receivers may be called; structs that can't be
+// addressed are copied.
+func newSelection(v reflect.Value) (*selection, bool) {
+	v = indirect(v)
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		return &selection{value: v}, true
+
+	case v.Kind() == reflect.Struct:
+		if !v.CanAddr() {
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			return &selection{value: p}, true
+		}
+		return &selection{value: v.Addr()}, true
+	}
+	return nil, false
+}
+
+func (s *selection) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *selection) Mutate(c *graphql.Context) (graphql.Field, error) {
+	return s.MutateContext(context.Background(), c)
+}
+
+func (s *selection) MutateContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(ctx, c)
+}
+
+// QueryContext resolves the field named by c; methods are called with ctx
+func (s *selection) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	switch s.value.Kind() {
+	case reflect.Map:
+		item := s.value.MapIndex(reflect.ValueOf(c.Name).Convert(s.value.Type().Key()))
+		if !item.IsValid() {
+			return nil, graphql.ErrFieldNotFound
+		}
+		return newField(item), nil
+
+	case reflect.Ptr:
+		t := typeOf(s.value.Type().Elem())
+		if m, ok := t.method(c.Name); ok {
+			return m.call(ctx, s.value, c.Args)
+		}
+		if f, ok := t.field(c.Name); ok {
+			return newField(fieldByIndex(s.value.Elem(), f.index)), nil
+		}
+	}
+	return nil, graphql.ErrFieldNotFound
+}
+
+// TypeName reports the name of the struct's Go type.  Values that implement graphql.Typed
+// themselves report their own name.
+func (s *selection) TypeName() string {
+	if typed, ok := s.typed(); ok {
+		return typed.TypeName()
+	}
+	if s.value.Kind() != reflect.Ptr {
+		return ""
+	}
+	return typeOf(s.value.Type().Elem()).name
+}
+
+// Satisfies reports whether the struct's type, or one of the structs it embeds, is named
+// by the type condition; embedding is how a struct declares the interfaces it implements.
+// Maps satisfy every condition.
+func (s *selection) Satisfies(typeCondition string) bool {
+	if typed, ok := s.typed(); ok {
+		return typed.Satisfies(typeCondition)
+	}
+	if s.value.Kind() != reflect.Ptr {
+		return true
+	}
+
+	t := typeOf(s.value.Type().Elem())
+	if t.name == typeCondition {
+		return true
+	}
+	for _, name := range t.embedded {
+		if name == typeCondition {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *selection) typed() (graphql.Typed, bool) {
+	if !s.value.IsValid() {
+		return nil, false
+	}
+	typed, ok := s.value.Interface().(graphql.Typed)
+	return typed, ok
+}
@@ -0,0 +1,198 @@
+package structq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/graphql"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type Character struct {
+	ID   int64 `graphql:"id"`
+	Name string
+}
+
+type Human struct {
+	Character
+	Height  float64
+	Email   *string
+	Friends []*Human
+	Tags    []string
+	Avatar  []byte
+	Ignored string `graphql:"-"`
+	secret  string
+}
+
+type GreetArgs struct {
+	To    string `graphql:"to"`
+	Times int
+	Shout *bool
+}
+
+func (h *Human) Greeting(ctx context.Context, args GreetArgs) (string, error) {
+	greeting := "hello " + args.To
+	if args.Shout != nil && *args.Shout {
+		greeting = "HELLO " + args.To
+	}
+	for i := 1; i < args.Times; i++ {
+		greeting += "!"
+	}
+	return greeting, nil
+}
+
+func (h *Human) Friend(ctx context.Context, args []graphql.Arg) (*Human, error) {
+	name, _ := (&graphql.Context{Args: args}).Arg("name")
+	for _, friend := range h.Friends {
+		if friend != nil && friend.Name == name {
+			return friend, nil
+		}
+	}
+	return nil, nil
+}
+
+func (h Human) Tenant(ctx context.Context) (string, error) {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant, nil
+}
+
+func (h *Human) Fail(ctx context.Context) (string, error) {
+	return "", errors.New("boom")
+}
+
+type tenantKey struct{}
+
+func handle(store graphql.Store, query string) string {
+	buf := bytes.NewBuffer([]byte{})
+	graphql.New(store).HandleContext(context.WithValue(context.Background(), tenantKey{}, "acme"), query, "", nil, buf)
+	return buf.String()
+}
+
+func TestFields(t *testing.T) {
+	Convey("Given a struct", t, func() {
+		email := "adam@example.com"
+		adam := Human{
+			Character: Character{ID: 16777217, Name: "Adam"},
+			Height:    1.8,
+			Email:     &email,
+			Friends:   []*Human{{Character: Character{ID: 2, Name: "Eve"}}, nil},
+			Tags:      []string{"a", "b"},
+			Avatar:    []byte("png"),
+			Ignored:   "ignored",
+			secret:    "secret",
+		}
+
+		Convey("When I query its fields", func() {
+			out := handle(New(adam), `{ id name height email avatar tags friends { name email tags } }`)
+
+			Convey("Then they should be named by their tag or their Go name", func() {
+				So(out, ShouldEqual, `{"data":{"id":16777217,"name":"Adam","height":1.8,"email":"adam@example.com","avatar":"cG5n","tags":["a","b"],"friends":[{"name":"Eve","email":null,"tags":null},null]}}`)
+			})
+		})
+
+		Convey("When I query hidden fields", func() {
+			out := handle(New(&adam), `{ ignored secret Name }`)
+
+			Convey("Then they should not be found", func() {
+				So(out, ShouldStartWith, `{"data":{"ignored":null,"secret":null,"Name":null},"errors":[{"message":"field not found"`)
+			})
+		})
+
+		Convey("When I call its methods", func() {
+			out := handle(New(adam), `{
+				greeting(to: "Eve", times: 3)
+				shout: greeting(to: "Eve", shout: true)
+				friend(name: "Eve") { name }
+				missing: friend(name: "Cain") { name }
+				tenant
+			}`)
+
+			Convey("Then their arguments should be decoded and their results written", func() {
+				So(out, ShouldEqual, `{"data":{"greeting":"hello Eve!!","shout":"HELLO Eve","friend":{"name":"Eve"},"missing":null,"tenant":"acme"}}`)
+			})
+		})
+
+		Convey("When a method fails", func() {
+			out := handle(New(adam), `{ name fail }`)
+
+			Convey("Then the field should be null", func() {
+				So(out, ShouldStartWith, `{"data":{"name":"Adam","fail":null},"errors":[{"message":"boom"`)
+			})
+		})
+
+		Convey("When an argument doesn't fit its field", func() {
+			out := handle(New(adam), `{ a: greeting(to: 1) b: greeting(times: 1.5) c: greeting(other: 1) }`)
+
+			Convey("Then the field should fail", func() {
+				So(out, ShouldStartWith, `{"data":{"a":null,"b":null,"c":null},"errors":[{"message":"invalid argument, to =\u003e string cannot represent 1"`)
+			})
+		})
+	})
+}
+
+func TestMaps(t *testing.T) {
+	Convey("Given a map of structs", t, func() {
+		store := New(map[string]interface{}{
+			"adam":  &Human{Character: Character{Name: "Adam"}},
+			"count": 2,
+			"names": map[string]string{"first": "Adam"},
+		})
+
+		Convey("When I query its entries", func() {
+			out := handle(store, `{ adam { name } count names { first } }`)
+
+			Convey("Then each entry should be a field", func() {
+				So(out, ShouldEqual, `{"data":{"adam":{"name":"Adam"},"count":2,"names":{"first":"Adam"}}}`)
+			})
+		})
+	})
+}
+
+func TestTypes(t *testing.T) {
+	Convey("Given a struct that embeds another", t, func() {
+		store := New(map[string]interface{}{
+			"hero": &Human{Character: Character{Name: "Adam"}, Height: 1.8},
+		})
+
+		Convey("When I query it through fragments", func() {
+			out := handle(store, `{ hero { __typename ... on Character { name } ... on Human { height } ... on Droid { model } } }`)
+
+			Convey("Then it should satisfy its own type and the types it embeds", func() {
+				So(out, ShouldEqual, `{"data":{"hero":{"__typename":"Human","name":"Adam","height":1.8}}}`)
+			})
+		})
+	})
+
+	Convey("Given a struct type", t, func() {
+		info := typeOf(reflect.TypeOf(Human{}))
+
+		Convey("Then its metadata should be built once", func() {
+			So(typeOf(reflect.TypeOf(Human{})), ShouldEqual, info)
+		})
+
+		Convey("Then its promoted fields should follow its own", func() {
+			var names []string
+			for _, f := range info.fields {
+				names = append(names, f.name)
+			}
+			So(names, ShouldResemble, []string{"height", "email", "friends", "tags", "avatar", "id", "name"})
+		})
+	})
+}
+
+func TestFieldName(t *testing.T) {
+	Convey("Given Go names", t, func() {
+		for name, expected := range map[string]string{
+			"Name":    "name",
+			"ID":      "id",
+			"URLPath": "urlPath",
+			"UserID":  "userID",
+			"A":       "a",
+		} {
+			So(fieldName(name), ShouldEqual, expected)
+		}
+	})
+}
@@ -0,0 +1,397 @@
+package structq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/savaki/graphql"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	argsType    = reflect.TypeOf([]graphql.Arg(nil))
+)
+
+// --[ Types ]------------------------------------------------------------
+
+// typeInfo holds what reflection tells us of a struct type; it's built once per type
+type typeInfo struct {
+	name     string
+	fields   []*structField
+	methods  []*method
+	embedded []string
+}
+
+// structField is a field of a struct, possibly promoted from an embedded struct
+type structField struct {
+	name        string
+	description string
+	index       []int
+	typ         reflect.Type
+}
+
+// method is a method callable as a field; args is nil if the method takes only a context
+type method struct {
+	name   string
+	goName string
+	index  int
+	args   reflect.Type
+	typ    reflect.Type
+}
+
+var types = struct {
+	sync.RWMutex
+	info map[reflect.Type]*typeInfo
+}{
+	info: map[reflect.Type]*typeInfo{},
+}
+
+// typeOf returns the fields and methods of the struct type t
+func typeOf(t reflect.Type) *typeInfo {
+	types.RLock()
+	info, ok := types.info[t]
+	types.RUnlock()
+	if ok {
+		return info
+	}
+
+	info = &typeInfo{
+		name:    t.Name(),
+		fields:  fieldsOf(t, nil, map[string]bool{}),
+		methods: methodsOf(reflect.PtrTo(t)),
+	}
+	info.embedded = embeddedIn(t)
+
+	types.Lock()
+	types.info[t] = info
+	types.Unlock()
+	return info
+}
+
+func (t *typeInfo) field(name string) (*structField, bool) {
+	for _, f := range t.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func (t *typeInfo) method(name string) (*method, bool) {
+	for _, m := range t.methods {
+		if m.name == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// fieldsOf lists the visible fields of the struct type t.  Fields of embedded structs are
+// promoted unless a shallower field, recorded in seen, has the same name.
+func fieldsOf(t reflect.Type, index []int, seen map[string]bool) []*structField {
+	var fields []*structField
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("graphql"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && tag == "" && structType(sf.Type) != nil {
+			// as with encoding/json, the fields of unexported embedded pointers can't be reached
+			if sf.PkgPath == "" || sf.Type.Kind() != reflect.Ptr {
+				embedded = append(embedded, sf)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = fieldName(sf.Name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, &structField{
+			name:        name,
+			description: sf.Tag.Get("description"),
+			index:       append(append([]int{}, index...), i),
+			typ:         sf.Type,
+		})
+	}
+
+	for _, sf := range embedded {
+		promoted := fieldsOf(structType(sf.Type), append(append([]int{}, index...), sf.Index...), seen)
+		fields = append(fields, promoted...)
+	}
+	return fields
+}
+
+// embeddedIn lists the names of the structs t embeds, however deeply
+func embeddedIn(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if et := structType(sf.Type); sf.Anonymous && et != nil {
+			names = append(names, et.Name())
+			names = append(names, embeddedIn(et)...)
+		}
+	}
+	return names
+}
+
+// structType returns t, or the type t points to, if it's a struct; nil otherwise
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// methodsOf lists the methods of t that may be called as fields
+func methodsOf(t reflect.Type) []*method {
+	var methods []*method
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		mt := m.Type // the receiver is the first argument
+
+		if mt.NumIn() < 2 || mt.NumIn() > 3 || mt.In(1) != contextType {
+			continue
+		}
+		if mt.NumOut() != 2 || mt.Out(1) != errorType {
+			continue
+		}
+
+		var args reflect.Type
+		if mt.NumIn() == 3 {
+			args = mt.In(2)
+			if args != argsType && structType(args) == nil {
+				continue
+			}
+		}
+
+		methods = append(methods, &method{
+			name:   fieldName(m.Name),
+			goName: m.Name,
+			index:  i,
+			args:   args,
+			typ:    mt.Out(0),
+		})
+	}
+	return methods
+}
+
+// fieldName returns the name a Go field or method is queried by; its leading capitals are
+// lowered, so Name becomes name, ID becomes id and URLPath becomes urlPath
+func fieldName(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// fieldByIndex returns the field of the struct v at index.  Unlike reflect's FieldByIndex
+// it returns the zero Value, rather than panicking, when an embedded pointer is nil.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// --[ Methods ]----------------------------------------------------------
+
+// call invokes the method on the pointer to a struct v with the field's arguments
+func (m *method) call(ctx context.Context, v reflect.Value, args []graphql.Arg) (graphql.Field, error) {
+	var arg reflect.Value
+	if m.args != nil {
+		var err error
+		if arg, err = decodeArgs(m.args, args); err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := m.invoke(ctx, v.Method(m.index), arg)
+	if err != nil {
+		return nil, err
+	}
+	return newField(out), nil
+}
+
+// invoke calls fn, the method bound to its receiver; arg is ignored if the method takes
+// only a context
+func (m *method) invoke(ctx context.Context, fn reflect.Value, arg reflect.Value) (reflect.Value, error) {
+	in := []reflect.Value{reflect.ValueOf(&ctx).Elem()}
+	if m.args != nil {
+		in = append(in, arg)
+	}
+
+	out := fn.Call(in)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return reflect.Value{}, err
+	}
+	return out[0], nil
+}
+
+// decodeArgs converts the arguments of a field to the type a method accepts them as
+func decodeArgs(t reflect.Type, args []graphql.Arg) (reflect.Value, error) {
+	if t == argsType {
+		return reflect.ValueOf(args), nil
+	}
+
+	values := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		values[arg.Name] = arg.Value
+	}
+	return decodeValues(t, values)
+}
+
+// decodeValues converts argument values, keyed by name, to a struct or a pointer to one
+func decodeValues(t reflect.Type, values map[string]interface{}) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if err := assign(v, values); err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
+
+// assign sets dst to the argument value v.  Input objects, held as maps, are assigned to
+// structs field by field and lists element by element; a single value is accepted for a
+// list of one, as the spec allows.  Numbers are converted provided they keep their value.
+func assign(dst reflect.Value, v interface{}) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(dst.Type().Elem())
+		if err := assign(p.Elem(), v); err != nil {
+			return err
+		}
+		dst.Set(p)
+		return nil
+
+	case reflect.Struct:
+		if fields, ok := v.(map[string]interface{}); ok {
+			return assignStruct(dst, fields)
+		}
+
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() != reflect.Uint8 {
+			items, ok := v.([]interface{})
+			if !ok {
+				items = []interface{}{v}
+			}
+			s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+			for index, item := range items {
+				if err := assign(s.Index(index), item); err != nil {
+					return err
+				}
+			}
+			dst.Set(s)
+			return nil
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			rv = reflect.ValueOf(i)
+		} else if f, err := n.Float64(); err == nil {
+			rv = reflect.ValueOf(f)
+		}
+	}
+
+	switch {
+	case rv.Type().AssignableTo(dst.Type()):
+		dst.Set(rv)
+		return nil
+
+	case isNumber(dst.Kind()) && isNumber(rv.Kind()):
+		converted := rv.Convert(dst.Type())
+		if !isFloat(dst.Kind()) && converted.Convert(rv.Type()).Interface() != rv.Interface() {
+			return fmt.Errorf("%v cannot represent %v", dst.Type(), v)
+		}
+		dst.Set(converted)
+		return nil
+
+	case isNumber(dst.Kind()) && !isFloat(dst.Kind()) && rv.Kind() == reflect.String:
+		// IDs are written as strings whatever they hold
+		n, err := strconv.ParseInt(rv.String(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%v cannot represent %v", dst.Type(), v)
+		}
+		return assign(dst, n)
+
+	case dst.Kind() == reflect.String && rv.Kind() == reflect.String,
+		dst.Kind() == reflect.Bool && rv.Kind() == reflect.Bool:
+		dst.Set(rv.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("%v cannot represent %v", dst.Type(), v)
+}
+
+// assignStruct sets the fields of the struct dst from an input object
+func assignStruct(dst reflect.Value, values map[string]interface{}) error {
+	t := typeOf(dst.Type())
+	for name, value := range values {
+		f, ok := t.field(name)
+		if !ok {
+			return fmt.Errorf("unknown argument, %v", name)
+		}
+		if err := assign(allocFieldByIndex(dst, f.index), value); err != nil {
+			return fmt.Errorf("invalid argument, %v => %v", name, err)
+		}
+	}
+	return nil
+}
+
+// allocFieldByIndex returns the field of the struct v at index, allocating any nil embedded
+// pointer along the way
+func allocFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/savaki/graphql/ast"
+)
+
+// --[ Errors ]-------------------------------------------------------
+
+// Error is an entry in the errors of a response.  Locations points at the part of the query
+// the error relates to and Path, a list of response keys and list indices, at the field that
+// failed.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []ast.Loc     `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+
+	locations := make([]string, len(e.Locations))
+	for index, loc := range e.Locations {
+		locations[index] = fmt.Sprintf("%v:%v", loc.Line, loc.Column)
+	}
+	return e.Message + " (" + strings.Join(locations, ", ") + ")"
+}
+
+// Errors holds the errors of a response in the order they occurred.  Handle returns them once
+// the response has been written.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// toErrors converts err to response errors, keeping any Error or Errors as they are.  Syntax
+// errors are reported at their location, without the excerpt of the query.
+func toErrors(err error) Errors {
+	switch v := err.(type) {
+	case Errors:
+		return v
+	case *Error:
+		return Errors{v}
+	case *ast.SyntaxError:
+		return Errors{{Message: v.Message, Locations: []ast.Loc{v.Loc}}}
+	default:
+		return Errors{{Message: err.Error()}}
+	}
+}
+
+// --[ Response ]-----------------------------------------------------
+
+// resultMap holds the fields of an object in the order they were selected
+type resultMap struct {
+	keys   []string
+	values []interface{}
+}
+
+func (m *resultMap) add(key string, value interface{}) {
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+func (m *resultMap) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("{")
+	for index, key := range m.keys {
+		if index > 0 {
+			buf.WriteString(",")
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteString(":")
+
+		v, err := json.Marshal(m.values[index])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// Response holds the result of a request.  Data is the result of the operation, nil if a field
+// error propagated to the root, and Errors the errors raised along the way.  Executed is false
+// when the request failed before execution began, in which case data is left out of the
+// response rather than written as null.
+type Response struct {
+	Data     interface{}
+	Errors   Errors
+	Executed bool
+}
+
+func (r *Response) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("{")
+	if r.Executed {
+		buf.WriteString(`"data":`)
+		v, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	if len(r.Errors) > 0 {
+		if r.Executed {
+			buf.WriteString(",")
+		}
+		buf.WriteString(`"errors":`)
+		v, err := json.Marshal(r.Errors)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// writeResponse writes the response envelope and returns its errors, if any, as Errors
+func writeResponse(w io.Writer, r *Response) error {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(r.Errors) > 0 {
+		return r.Errors
+	}
+	return nil
+}
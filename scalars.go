@@ -0,0 +1,292 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/savaki/graphql/ast"
+)
+
+// --[ Scalars ]------------------------------------------------------
+
+// Scalar describes how the values of a scalar type are converted.  Serialize converts a
+// resolved value to the value written in the response, ParseValue converts the value of a
+// variable, typically decoded from json, and ParseLiteral a value written in the query.
+// Each returns an error when the value can't represent the type; a nil func leaves values
+// as they are.
+type Scalar struct {
+	Name         string
+	Serialize    func(interface{}) (interface{}, error)
+	ParseValue   func(interface{}) (interface{}, error)
+	ParseLiteral func(ast.Value) (interface{}, error)
+}
+
+// the scalars the spec defines
+var (
+	Int = &Scalar{
+		Name:         "Int",
+		Serialize:    SerializeInt,
+		ParseValue:   CoerceInt,
+		ParseLiteral: parseIntLiteral,
+	}
+
+	Float = &Scalar{
+		Name:         "Float",
+		Serialize:    SerializeFloat,
+		ParseValue:   CoerceFloat,
+		ParseLiteral: parseFloatLiteral,
+	}
+
+	String = &Scalar{
+		Name:         "String",
+		Serialize:    SerializeString,
+		ParseValue:   ParseString,
+		ParseLiteral: parseStringLiteral,
+	}
+
+	Boolean = &Scalar{
+		Name:         "Boolean",
+		Serialize:    SerializeBoolean,
+		ParseValue:   ParseBoolean,
+		ParseLiteral: parseBooleanLiteral,
+	}
+
+	ID = &Scalar{
+		Name:         "ID",
+		Serialize:    CoerceID,
+		ParseValue:   CoerceID,
+		ParseLiteral: parseIDLiteral,
+	}
+)
+
+// builtinScalars holds the spec's scalar types by name
+var builtinScalars = map[string]*Scalar{
+	Int.Name:     Int,
+	Float.Name:   Float,
+	String.Name:  String,
+	Boolean.Name: Boolean,
+	ID.Name:      ID,
+}
+
+// CoerceInt converts a number to an int64 within the signed 32 bit range the spec allows for
+// Int.  Fractional values and values outside the range are rejected.
+func CoerceInt(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent %v; value exceeds 32 bits", v)
+		}
+		return n, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := rv.Uint()
+		if n > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent %v; value exceeds 32 bits", v)
+		}
+		return int64(n), nil
+
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt32 || f > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non-integer value, %v", v)
+		}
+		return int64(f), nil
+	}
+
+	if n, ok := v.(json.Number); ok {
+		i, err := n.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("Int cannot represent non-integer value, %v", v)
+		}
+		return CoerceInt(i)
+	}
+	return nil, fmt.Errorf("Int cannot represent %v", v)
+}
+
+// SerializeInt converts a resolved value to an Int.  Besides numbers, booleans are written
+// as 1 or 0 and strings holding an integer as that integer, as the spec allows.
+func SerializeInt(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case bool:
+		if value {
+			return int64(1), nil
+		}
+		return int64(0), nil
+
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Int cannot represent non-integer value, %q", value)
+		}
+		return CoerceInt(f)
+	}
+	return CoerceInt(v)
+}
+
+// CoerceFloat converts any number to a float64
+func CoerceFloat(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+
+	if n, ok := v.(json.Number); ok {
+		return n.Float64()
+	}
+	return nil, fmt.Errorf("Float cannot represent %v", v)
+}
+
+// SerializeFloat converts a resolved value to a Float.  Besides numbers, booleans are written
+// as 1 or 0 and strings holding a number as that number.  NaN and the infinities, which json
+// can't represent, are rejected.
+func SerializeFloat(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case bool:
+		if value {
+			return float64(1), nil
+		}
+		return float64(0), nil
+
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Float cannot represent non numeric value, %q", value)
+		}
+		v = f
+	}
+
+	f, err := CoerceFloat(v)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(f.(float64)) || math.IsInf(f.(float64), 0) {
+		return nil, fmt.Errorf("Float cannot represent %v", v)
+	}
+	return f, nil
+}
+
+// ParseString accepts only strings as input for String
+func ParseString(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value, %v", v)
+}
+
+// SerializeString converts a resolved value to a String; strings, values implementing
+// fmt.Stringer, booleans and numbers are written as text
+func SerializeString(v interface{}) (interface{}, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case fmt.Stringer:
+		return s.String(), nil
+	case bool:
+		return strconv.FormatBool(s), nil
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return fmt.Sprint(v), nil
+	}
+	return nil, fmt.Errorf("String cannot represent %v", v)
+}
+
+// ParseBoolean accepts only booleans as input for Boolean
+func ParseBoolean(v interface{}) (interface{}, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value, %v", v)
+}
+
+// SerializeBoolean converts a resolved value of any boolean type to a Boolean
+func SerializeBoolean(v interface{}) (interface{}, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Bool {
+		return rv.Bool(), nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent %v", v)
+}
+
+// CoerceID converts a string or an integer to the string form of an ID
+func CoerceID(v interface{}) (interface{}, error) {
+	switch id := v.(type) {
+	case string:
+		return id, nil
+	case json.Number:
+		return id.String(), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); f == math.Trunc(f) {
+			return strconv.FormatInt(int64(f), 10), nil
+		}
+	case reflect.String:
+		return rv.String(), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent %v", v)
+}
+
+func parseIntLiteral(value ast.Value) (interface{}, error) {
+	v, ok := value.(*ast.IntValue)
+	if !ok {
+		return nil, fmt.Errorf("Int cannot represent a non integer value, %v", value)
+	}
+	i, err := strconv.ParseInt(v.Value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Int cannot represent %v; value exceeds 32 bits", v.Value)
+	}
+	return CoerceInt(i)
+}
+
+func parseFloatLiteral(value ast.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		return strconv.ParseFloat(v.Value, 64)
+	case *ast.FloatValue:
+		return strconv.ParseFloat(v.Value, 64)
+	}
+	return nil, fmt.Errorf("Float cannot represent a non numeric value, %v", value)
+}
+
+func parseStringLiteral(value ast.Value) (interface{}, error) {
+	if v, ok := value.(*ast.StringValue); ok {
+		return v.Value, nil
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value, %v", value)
+}
+
+func parseBooleanLiteral(value ast.Value) (interface{}, error) {
+	if v, ok := value.(*ast.BooleanValue); ok {
+		return v.Value, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value, %v", value)
+}
+
+func parseIDLiteral(value ast.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case *ast.StringValue:
+		return v.Value, nil
+	case *ast.IntValue:
+		return v.Value, nil
+	}
+	return nil, fmt.Errorf("ID cannot represent a non string or integer value, %v", value)
+}
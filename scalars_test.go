@@ -0,0 +1,200 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/savaki/graphql/ast"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// --[ scalars ]------------------------------------------------------
+
+// scalars is a Store whose fields answer with fixed values and report their scalar types;
+// arguments of every field are of type Date
+type scalars struct {
+	recorder
+	values map[string]interface{}
+	types  map[string]string
+}
+
+func (s *scalars) Query(c *Context) (Field, error) {
+	s.contexts = append(s.contexts, c)
+	return scalarField{value: s.values[c.Name]}, nil
+}
+
+func (s *scalars) ScalarField(name string) string {
+	return s.types[name]
+}
+
+func (s *scalars) ScalarArg(field, arg string) string {
+	return "Date"
+}
+
+type scalarField struct {
+	value interface{}
+}
+
+func (f scalarField) Selection() (Selection, error) { return nil, ErrNotAScalar }
+func (f scalarField) Value() (Value, error)         { return f.value, nil }
+
+// date is a custom scalar written as yyyy-mm-dd
+var date = &Scalar{
+	Name: "Date",
+	Serialize: func(v interface{}) (interface{}, error) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("Date cannot represent %v", v)
+		}
+		return t.Format("2006-01-02"), nil
+	},
+	ParseValue: func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("Date cannot represent %v", v)
+		}
+		return time.Parse("2006-01-02", s)
+	},
+	ParseLiteral: func(value ast.Value) (interface{}, error) {
+		s, ok := value.(*ast.StringValue)
+		if !ok {
+			return nil, fmt.Errorf("Date cannot represent %v", value)
+		}
+		return time.Parse("2006-01-02", s.Value)
+	},
+}
+
+func TestOutputCoercion(t *testing.T) {
+	Convey("Given fields of the built in scalars", t, func() {
+		store := &scalars{
+			values: map[string]interface{}{
+				"int":     "123",
+				"float":   int32(2),
+				"string":  42,
+				"boolean": true,
+				"id":      int64(1) << 40,
+				"ids":     []interface{}{1, "b"},
+				"big":     int64(1) << 40,
+			},
+			types: map[string]string{
+				"int":     "Int",
+				"float":   "Float",
+				"string":  "String",
+				"boolean": "Boolean",
+				"id":      "ID",
+				"ids":     "ID",
+				"big":     "Int",
+			},
+		}
+
+		Convey("When I query them", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := New(store).Handle(`{ int float string boolean id ids }`, w)
+
+			Convey("Then each value should be coerced to its type", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"int":123,"float":2,"string":"42","boolean":true,"id":"1099511627776","ids":["1","b"]}}`)
+			})
+		})
+
+		Convey("When a value can't represent its type", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := New(store).Handle(`{ big int }`, w)
+
+			Convey("Then the field should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"big":null,"int":123},"errors":[{"message":"Int cannot represent 1099511627776; value exceeds 32 bits","locations":[{"line":1,"column":3}],"path":["big"]}]}`)
+			})
+		})
+	})
+}
+
+func TestCustomScalars(t *testing.T) {
+	Convey("Given an executor with a custom scalar", t, func() {
+		day := time.Date(2016, 7, 4, 0, 0, 0, 0, time.UTC)
+		store := &scalars{
+			values: map[string]interface{}{"today": day},
+			types:  map[string]string{"today": "Date"},
+		}
+		executor := New(store)
+		executor.Scalar(date)
+
+		Convey("When a field of the type is queried", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := executor.Handle(`{ today }`, w)
+
+			Convey("Then the value should be serialized by the scalar", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"today":"2016-07-04"}}`)
+			})
+		})
+
+		Convey("When an argument of the type is written in the query", func() {
+			err := executor.Handle(`{ today(after: "2016-07-04", between: ["2016-07-04", null]) }`, bytes.NewBuffer([]byte{}))
+
+			Convey("Then its value should be parsed by the scalar", func() {
+				So(err, ShouldBeNil)
+				So(store.contexts[0].Args, ShouldResemble, []Arg{
+					{Name: "after", Value: day},
+					{Name: "between", Value: []interface{}{day, nil}},
+				})
+			})
+		})
+
+		Convey("When a variable of the type is provided", func() {
+			query := `query q($after: Date, $before: Date = "2016-07-04") { today(after: $after, before: $before) }`
+			err := executor.HandleRequest(query, "", map[string]interface{}{"after": "2016-07-04"}, bytes.NewBuffer([]byte{}))
+
+			Convey("Then its value, and the default value, should be parsed by the scalar", func() {
+				So(err, ShouldBeNil)
+				So(store.contexts[0].Args, ShouldResemble, []Arg{
+					{Name: "after", Value: day},
+					{Name: "before", Value: day},
+				})
+			})
+		})
+
+		Convey("When an argument can't be parsed", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := executor.Handle(`{ today(after: 1) }`, w)
+
+			Convey("Then the field should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldStartWith, `{"data":{"today":null},"errors":[{"message":"Date cannot represent`)
+			})
+		})
+	})
+
+	Convey("Given a scalar named after a built in one", t, func() {
+		executor := New(&recorder{})
+
+		Convey("Then registering it should panic", func() {
+			So(func() { executor.Scalar(&Scalar{Name: "Int"}) }, ShouldPanic)
+		})
+	})
+}
+
+func TestSerializeScalars(t *testing.T) {
+	Convey("Given values of the built in scalars", t, func() {
+		v, err := SerializeInt(json.Number("16777217"))
+		So(err, ShouldBeNil)
+		So(v, ShouldEqual, int64(16777217))
+
+		_, err = SerializeInt(1.5)
+		So(err, ShouldNotBeNil)
+
+		v, err = SerializeFloat("0.5")
+		So(err, ShouldBeNil)
+		So(v, ShouldEqual, 0.5)
+
+		v, err = CoerceID(float64(16777217))
+		So(err, ShouldBeNil)
+		So(v, ShouldEqual, "16777217")
+
+		_, err = SerializeBoolean("true")
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,436 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/savaki/graphql"
+)
+
+// --[ Directives ]---------------------------------------------------
+
+// Directive describes a directive the schema supports.  Locations names where the directive
+// may appear using the values of __DirectiveLocation e.g. FIELD or FRAGMENT_SPREAD.
+type Directive struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*InputValue
+	Repeatable  bool
+}
+
+var (
+	SkipDirective = &Directive{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args: []*InputValue{
+			{Name: "if", Description: "Skipped when true.", Type: &NonNull{OfType: Boolean}},
+		},
+	}
+
+	IncludeDirective = &Directive{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args: []*InputValue{
+			{Name: "if", Description: "Included when true.", Type: &NonNull{OfType: Boolean}},
+		},
+	}
+
+	DeprecatedDirective = &Directive{
+		Name:        "deprecated",
+		Description: "Marks an element of a GraphQL schema as no longer supported.",
+		Locations:   []string{"FIELD_DEFINITION", "ENUM_VALUE"},
+		Args: []*InputValue{
+			{
+				Name:            "reason",
+				Description:     "Explains why this element was deprecated.",
+				Type:            String,
+				DefaultValue:    "No longer supported",
+				HasDefaultValue: true,
+			},
+		},
+	}
+)
+
+// directives returns the built in directives followed by those declared by the schema
+func (s *Schema) directives() []*Directive {
+	return append([]*Directive{SkipDirective, IncludeDirective, DeprecatedDirective}, s.Directives...)
+}
+
+// --[ Meta Types ]---------------------------------------------------
+
+// The types of the introspection system.  They're ordinary object types whose sources are
+// the meta values below, so introspection queries are executed like any other query.
+var (
+	typeKindType = &EnumType{
+		Name:        "__TypeKind",
+		Description: "An enum describing what kind of type a given `__Type` is.",
+		Values:      enumValues("SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"),
+	}
+
+	directiveLocationType = &EnumType{
+		Name:        "__DirectiveLocation",
+		Description: "A Directive can be adjacent to many parts of the GraphQL language.",
+		Values: enumValues(
+			"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD",
+			"INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+			"ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT",
+			"INPUT_FIELD_DEFINITION",
+		),
+	}
+
+	schemaType     = &ObjectType{Name: "__Schema", Description: "A GraphQL Schema defines the capabilities of a GraphQL server."}
+	typeType       = &ObjectType{Name: "__Type", Description: "The fundamental unit of any GraphQL Schema is the type."}
+	fieldType      = &ObjectType{Name: "__Field", Description: "Object and Interface types are described by a list of Fields."}
+	inputValueType = &ObjectType{Name: "__InputValue", Description: "Arguments provided to Fields or Directives and the input fields of an InputObject."}
+	enumValueType  = &ObjectType{Name: "__EnumValue", Description: "One possible value for a given Enum."}
+	directiveType  = &ObjectType{Name: "__Directive", Description: "A Directive provides a way to describe alternate runtime execution in GraphQL."}
+)
+
+func init() {
+	includeDeprecated := []*InputValue{
+		{Name: "includeDeprecated", Type: Boolean, DefaultValue: false, HasDefaultValue: true},
+	}
+
+	schemaType.Fields = []*Field{
+		{Name: "description", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		}},
+		{Name: "types", Type: nonNullList(typeType), Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			s := source.(*store)
+			types := make([]*metaType, 0, len(s.types))
+			for _, name := range sortedNames(s.types) {
+				types = append(types, s.metaType(s.types[name]))
+			}
+			return types, nil
+		}},
+		{Name: "queryType", Type: &NonNull{OfType: typeType}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			s := source.(*store)
+			return s.metaType(s.schema.Query), nil
+		}},
+		{Name: "mutationType", Type: typeType, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			s := source.(*store)
+			if s.schema.Mutation == nil {
+				return nil, nil
+			}
+			return s.metaType(s.schema.Mutation), nil
+		}},
+		{Name: "subscriptionType", Type: typeType, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		}},
+		{Name: "directives", Type: nonNullList(directiveType), Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			s := source.(*store)
+			var directives []*metaDirective
+			for _, d := range s.schema.directives() {
+				directives = append(directives, &metaDirective{store: s, directive: d})
+			}
+			return directives, nil
+		}},
+	}
+
+	typeType.Fields = []*Field{
+		{Name: "kind", Type: &NonNull{OfType: typeKindType}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return kindOf(source.(*metaType).typ), nil
+		}},
+		{Name: "name", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			if named, ok := source.(*metaType).typ.(Named); ok {
+				return named.TypeName(), nil
+			}
+			return nil, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return describe(source.(*metaType).typ), nil
+		}},
+		{Name: "specifiedByURL", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		}},
+		{Name: "fields", Type: &List{OfType: &NonNull{OfType: fieldType}}, Args: includeDeprecated, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*metaType)
+			var fields []*Field
+			switch v := t.typ.(type) {
+			case *ObjectType:
+				fields = v.Fields
+			case *InterfaceType:
+				fields = v.Fields
+			default:
+				return nil, nil
+			}
+
+			values := []*metaField{}
+			for _, f := range fields {
+				if f.DeprecationReason == "" || args["includeDeprecated"] == true {
+					values = append(values, &metaField{store: t.store, field: f})
+				}
+			}
+			return values, nil
+		}},
+		{Name: "interfaces", Type: &List{OfType: &NonNull{OfType: typeType}}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*metaType)
+			switch v := t.typ.(type) {
+			case *ObjectType:
+				interfaces := []*metaType{}
+				for _, i := range v.Interfaces {
+					interfaces = append(interfaces, t.store.metaType(i))
+				}
+				return interfaces, nil
+			case *InterfaceType:
+				return []*metaType{}, nil
+			default:
+				return nil, nil
+			}
+		}},
+		{Name: "possibleTypes", Type: &List{OfType: &NonNull{OfType: typeType}}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*metaType)
+			switch v := t.typ.(type) {
+			case *InterfaceType, *UnionType:
+				types := []*metaType{}
+				for _, o := range t.store.possible[v.(Named).TypeName()] {
+					types = append(types, t.store.metaType(o))
+				}
+				return types, nil
+			default:
+				return nil, nil
+			}
+		}},
+		{Name: "enumValues", Type: &List{OfType: &NonNull{OfType: enumValueType}}, Args: includeDeprecated, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			enum, ok := source.(*metaType).typ.(*EnumType)
+			if !ok {
+				return nil, nil
+			}
+			values := []*EnumValue{}
+			for _, v := range enum.Values {
+				if v.DeprecationReason == "" || args["includeDeprecated"] == true {
+					values = append(values, v)
+				}
+			}
+			return values, nil
+		}},
+		{Name: "inputFields", Type: &List{OfType: &NonNull{OfType: inputValueType}}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*metaType)
+			input, ok := t.typ.(*InputObjectType)
+			if !ok {
+				return nil, nil
+			}
+			return t.store.metaInputValues(input.Fields), nil
+		}},
+		{Name: "ofType", Type: typeType, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*metaType)
+			switch v := t.typ.(type) {
+			case *List:
+				return t.store.metaType(v.OfType), nil
+			case *NonNull:
+				return t.store.metaType(v.OfType), nil
+			default:
+				return nil, nil
+			}
+		}},
+	}
+
+	fieldType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{OfType: String}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*metaField).field.Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nullable(source.(*metaField).field.Description), nil
+		}},
+		{Name: "args", Type: nonNullList(inputValueType), Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			f := source.(*metaField)
+			return f.store.metaInputValues(f.field.Args), nil
+		}},
+		{Name: "type", Type: &NonNull{OfType: typeType}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			f := source.(*metaField)
+			return f.store.metaType(f.field.Type), nil
+		}},
+		{Name: "isDeprecated", Type: &NonNull{OfType: Boolean}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*metaField).field.DeprecationReason != "", nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nullable(source.(*metaField).field.DeprecationReason), nil
+		}},
+	}
+
+	inputValueType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{OfType: String}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*metaInputValue).value.Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nullable(source.(*metaInputValue).value.Description), nil
+		}},
+		{Name: "type", Type: &NonNull{OfType: typeType}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			v := source.(*metaInputValue)
+			return v.store.metaType(v.value.Type), nil
+		}},
+		{Name: "defaultValue", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			v := source.(*metaInputValue).value
+			if !v.HasDefaultValue {
+				return nil, nil
+			}
+			return printValue(v.Type, v.DefaultValue), nil
+		}},
+		{Name: "isDeprecated", Type: &NonNull{OfType: Boolean}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return false, nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		}},
+	}
+
+	enumValueType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{OfType: String}},
+		{Name: "description", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nullable(source.(*EnumValue).Description), nil
+		}},
+		{Name: "isDeprecated", Type: &NonNull{OfType: Boolean}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*EnumValue).DeprecationReason != "", nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nullable(source.(*EnumValue).DeprecationReason), nil
+		}},
+	}
+
+	directiveType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{OfType: String}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*metaDirective).directive.Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nullable(source.(*metaDirective).directive.Description), nil
+		}},
+		{Name: "locations", Type: nonNullList(directiveLocationType), Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*metaDirective).directive.Locations, nil
+		}},
+		{Name: "args", Type: nonNullList(inputValueType), Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			d := source.(*metaDirective)
+			return d.store.metaInputValues(d.directive.Args), nil
+		}},
+		{Name: "isRepeatable", Type: &NonNull{OfType: Boolean}, Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return source.(*metaDirective).directive.Repeatable, nil
+		}},
+	}
+}
+
+// --[ Meta Fields ]--------------------------------------------------
+
+// metaField answers the __schema and __type meta fields of the query root; it returns nil if
+// the name isn't a meta field
+func (s *store) metaField(c *graphql.Context) (graphql.Field, error) {
+	switch c.Name {
+	case "__schema":
+		return s.newField(&NonNull{OfType: schemaType}, s), nil
+
+	case "__type":
+		v, _ := c.Arg("name")
+		name, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("__type requires a name argument of type String!")
+		}
+		t, ok := s.types[name]
+		if !ok {
+			return s.newField(typeType, nil), nil
+		}
+		return s.newField(typeType, s.metaType(t)), nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// The sources of the meta types; each carries the store so the types it references can be
+// looked up in the store's index
+type metaType struct {
+	store *store
+	typ   Type
+}
+
+type metaField struct {
+	store *store
+	field *Field
+}
+
+type metaInputValue struct {
+	store *store
+	value *InputValue
+}
+
+type metaDirective struct {
+	store     *store
+	directive *Directive
+}
+
+func (s *store) metaType(t Type) *metaType {
+	return &metaType{store: s, typ: t}
+}
+
+func (s *store) metaInputValues(values []*InputValue) []*metaInputValue {
+	meta := []*metaInputValue{}
+	for _, v := range values {
+		meta = append(meta, &metaInputValue{store: s, value: v})
+	}
+	return meta
+}
+
+func kindOf(t Type) string {
+	switch t.(type) {
+	case *ScalarType:
+		return "SCALAR"
+	case *ObjectType:
+		return "OBJECT"
+	case *InterfaceType:
+		return "INTERFACE"
+	case *UnionType:
+		return "UNION"
+	case *EnumType:
+		return "ENUM"
+	case *InputObjectType:
+		return "INPUT_OBJECT"
+	case *List:
+		return "LIST"
+	default:
+		return "NON_NULL"
+	}
+}
+
+func describe(t Type) interface{} {
+	switch v := t.(type) {
+	case *ScalarType:
+		return nullable(v.Description)
+	case *ObjectType:
+		return nullable(v.Description)
+	case *InterfaceType:
+		return nullable(v.Description)
+	case *UnionType:
+		return nullable(v.Description)
+	case *EnumType:
+		return nullable(v.Description)
+	case *InputObjectType:
+		return nullable(v.Description)
+	default:
+		return nil
+	}
+}
+
+// nullable returns nil for an empty string so unset descriptions are written as null
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nonNullList(t Type) Type {
+	return &NonNull{OfType: &List{OfType: &NonNull{OfType: t}}}
+}
+
+func enumValues(names ...string) []*EnumValue {
+	values := make([]*EnumValue, len(names))
+	for index, name := range names {
+		values[index] = &EnumValue{Name: name}
+	}
+	return values
+}
+
+// printValue formats a go value of the given input type as a GraphQL literal, as reported
+// by __InputValue.defaultValue
+func printValue(t Type, v interface{}) string {
+	return literal(t, v).String()
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/savaki/graphql"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// introspectionQuery is the query GraphiQL and most client tooling issue to learn a schema
+const introspectionQuery = `
+query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives { name description locations args { ...InputValue } }
+  }
+}
+
+fragment FullType on __Type {
+  kind name description
+  fields(includeDeprecated: true) {
+    name description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name } } }
+}`
+
+func TestIntrospection(t *testing.T) {
+	Convey("Given a schema backed store", t, func() {
+		store, _ := newStore()
+		executor := &graphql.Executor{Store: store}
+		w := &bytes.Buffer{}
+
+		Convey("When I ask for __typename", func() {
+			err := executor.Handle(`{ __typename hero { __typename } search { __typename } }`, w)
+
+			Convey("Then the concrete type of each object is returned", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"__typename":"Query","hero":{"__typename":"Droid"},"search":[{"__typename":"Human"},{"__typename":"Droid"}]}}`)
+			})
+		})
+
+		Convey("When I ask for a type by name", func() {
+			err := executor.Handle(`{
+				__type(name: "Character") { kind name fields { name type { kind ofType { name } } } possibleTypes { name } }
+				missing: __type(name: "Nope") { name }
+			}`, w)
+
+			Convey("Then the type is described", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"__type":{"kind":"INTERFACE","name":"Character","fields":[{"name":"name","type":{"kind":"NON_NULL","ofType":{"name":"String"}}}],"possibleTypes":[{"name":"Droid"},{"name":"Human"}]},"missing":null}}`)
+			})
+		})
+
+		Convey("When I describe an enum and an input object", func() {
+			err := executor.Handle(`{
+				e: __type(name: "Episode") { enumValues { name } }
+				i: __type(name: "ReviewInput") { inputFields { name defaultValue } }
+			}`, w)
+
+			Convey("Then values and defaults are reported", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"e":{"enumValues":[{"name":"NEWHOPE"},{"name":"EMPIRE"},{"name":"JEDI"}]},"i":{"inputFields":[{"name":"stars","defaultValue":null},{"name":"commentary","defaultValue":"\"none\""}]}}}`)
+			})
+		})
+
+		Convey("When I issue the standard introspection query", func() {
+			err := executor.Handle(introspectionQuery, w)
+			So(err, ShouldBeNil)
+
+			var result struct {
+				Data struct {
+					Schema struct {
+						QueryType    struct{ Name string }
+						MutationType struct{ Name string }
+						Types        []struct {
+							Kind string
+							Name string
+						}
+						Directives []struct{ Name string }
+					} `json:"__schema"`
+				}
+			}
+			So(json.Unmarshal(w.Bytes(), &result), ShouldBeNil)
+
+			Convey("Then the roots, types and directives are described", func() {
+				So(result.Data.Schema.QueryType.Name, ShouldEqual, "Query")
+				So(result.Data.Schema.MutationType.Name, ShouldEqual, "Mutation")
+				So(result.Data.Schema.Directives, ShouldHaveLength, 3)
+
+				kinds := map[string]string{}
+				for _, typ := range result.Data.Schema.Types {
+					kinds[typ.Name] = typ.Kind
+				}
+				So(kinds["Character"], ShouldEqual, "INTERFACE")
+				So(kinds["SearchResult"], ShouldEqual, "UNION")
+				So(kinds["Episode"], ShouldEqual, "ENUM")
+				So(kinds["ReviewInput"], ShouldEqual, "INPUT_OBJECT")
+				So(kinds["__Type"], ShouldEqual, "OBJECT")
+			})
+		})
+	})
+
+	Convey("Given default values of each input type", t, func() {
+		So(printValue(String, "a\"b"), ShouldEqual, `"a\"b"`)
+		So(printValue(&List{OfType: Int}, []interface{}{1, 2}), ShouldEqual, `[1, 2]`)
+		So(printValue(episodeEnum, 5), ShouldEqual, `EMPIRE`)
+		So(printValue(Boolean, nil), ShouldEqual, `null`)
+	})
+}
@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"github.com/savaki/graphql"
+)
+
+// the built in scalars
+var (
+	Int = &ScalarType{
+		Name:         "Int",
+		Description:  "The Int scalar type represents a signed 32-bit numeric non-fractional value.",
+		Serialize:    graphql.Int.Serialize,
+		ParseValue:   graphql.Int.ParseValue,
+		ParseLiteral: graphql.Int.ParseLiteral,
+	}
+
+	Float = &ScalarType{
+		Name:         "Float",
+		Description:  "The Float scalar type represents signed double-precision fractional values.",
+		Serialize:    graphql.Float.Serialize,
+		ParseValue:   graphql.Float.ParseValue,
+		ParseLiteral: graphql.Float.ParseLiteral,
+	}
+
+	String = &ScalarType{
+		Name:         "String",
+		Description:  "The String scalar type represents textual data as UTF-8 character sequences.",
+		Serialize:    graphql.String.Serialize,
+		ParseValue:   graphql.String.ParseValue,
+		ParseLiteral: graphql.String.ParseLiteral,
+	}
+
+	Boolean = &ScalarType{
+		Name:         "Boolean",
+		Description:  "The Boolean scalar type represents true or false.",
+		Serialize:    graphql.Boolean.Serialize,
+		ParseValue:   graphql.Boolean.ParseValue,
+		ParseLiteral: graphql.Boolean.ParseLiteral,
+	}
+
+	ID = &ScalarType{
+		Name:         "ID",
+		Description:  "The ID scalar type represents a unique identifier, serialized as a String.",
+		Serialize:    graphql.ID.Serialize,
+		ParseValue:   graphql.ID.ParseValue,
+		ParseLiteral: graphql.ID.ParseLiteral,
+	}
+)
@@ -0,0 +1,435 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/savaki/graphql/ast"
+)
+
+// --[ Types ]--------------------------------------------------------
+
+// Type is implemented by every schema type; String returns the type as it would be
+// referenced in a query e.g. [User!]
+type Type interface {
+	String() string
+}
+
+// Named is implemented by every type other than List and NonNull
+type Named interface {
+	Type
+	TypeName() string
+}
+
+// Unwrap strips any List and NonNull wrappers from a type and returns the named type within
+func Unwrap(t Type) Named {
+	for {
+		switch v := t.(type) {
+		case *List:
+			t = v.OfType
+		case *NonNull:
+			t = v.OfType
+		case Named:
+			return v
+		default:
+			return nil
+		}
+	}
+}
+
+// IsLeaf reports whether values of the type are written directly rather than selected from
+func IsLeaf(t Type) bool {
+	switch Unwrap(t).(type) {
+	case *ScalarType, *EnumType:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsInput reports whether the type may be used for arguments and variables
+func IsInput(t Type) bool {
+	switch Unwrap(t).(type) {
+	case *ScalarType, *EnumType, *InputObjectType:
+		return true
+	default:
+		return false
+	}
+}
+
+// --[ Scalar ]-------------------------------------------------------
+
+// ScalarType describes a leaf value.  Serialize converts a resolved value to the value
+// written in the response and ParseValue converts an argument or variable value to the
+// value handed to resolvers.  ParseLiteral, if set, is used by validation to check values
+// written in the query.
+type ScalarType struct {
+	Name         string
+	Description  string
+	Serialize    func(interface{}) (interface{}, error)
+	ParseValue   func(interface{}) (interface{}, error)
+	ParseLiteral func(ast.Value) (interface{}, error)
+}
+
+func (t *ScalarType) String() string   { return t.Name }
+func (t *ScalarType) TypeName() string { return t.Name }
+
+// --[ Object ]-------------------------------------------------------
+
+type ObjectType struct {
+	Name        string
+	Description string
+	Interfaces  []*InterfaceType
+	Fields      []*Field
+
+	// IsTypeOf optionally reports whether a value belongs to this type; it's used to
+	// resolve the concrete type of interface and union values
+	IsTypeOf func(value interface{}) bool
+}
+
+func (t *ObjectType) String() string   { return t.Name }
+func (t *ObjectType) TypeName() string { return t.Name }
+
+// Field returns the named field or nil if the type has no such field
+func (t *ObjectType) Field(name string) *Field {
+	return findField(t.Fields, name)
+}
+
+// Implements reports whether the object type declares the named interface
+func (t *ObjectType) Implements(name string) bool {
+	for _, i := range t.Interfaces {
+		if i.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// --[ Interface ]----------------------------------------------------
+
+type InterfaceType struct {
+	Name        string
+	Description string
+	Fields      []*Field
+
+	// ResolveType optionally returns the object type of a value; see ObjectType.IsTypeOf
+	ResolveType func(value interface{}) *ObjectType
+}
+
+func (t *InterfaceType) String() string   { return t.Name }
+func (t *InterfaceType) TypeName() string { return t.Name }
+
+// Field returns the named field or nil if the type has no such field
+func (t *InterfaceType) Field(name string) *Field {
+	return findField(t.Fields, name)
+}
+
+// --[ Union ]--------------------------------------------------------
+
+type UnionType struct {
+	Name        string
+	Description string
+	Types       []*ObjectType
+
+	// ResolveType optionally returns the object type of a value; see ObjectType.IsTypeOf
+	ResolveType func(value interface{}) *ObjectType
+}
+
+func (t *UnionType) String() string   { return t.Name }
+func (t *UnionType) TypeName() string { return t.Name }
+
+// --[ Enum ]---------------------------------------------------------
+
+// EnumType describes a leaf whose values are one of a set of names.  Each EnumValue maps a
+// name as it appears in queries and responses to the go value used by resolvers.
+type EnumType struct {
+	Name        string
+	Description string
+	Values      []*EnumValue
+}
+
+// EnumValue names one value of an enum.  Value is the go value resolvers receive and return
+// for it; when Value is nil the Name itself is used.
+type EnumValue struct {
+	Name              string
+	Description       string
+	Value             interface{}
+	DeprecationReason string
+}
+
+// GoValue returns the go value the enum value stands for; Value or, when that's nil, Name
+func (v *EnumValue) GoValue() interface{} {
+	if v.Value == nil {
+		return v.Name
+	}
+	return v.Value
+}
+
+func (t *EnumType) String() string   { return t.Name }
+func (t *EnumType) TypeName() string { return t.Name }
+
+// Value returns the enum value with the given name or nil if there is none
+func (t *EnumType) Value(name string) *EnumValue {
+	for _, v := range t.Values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// --[ Input Object ]-------------------------------------------------
+
+type InputObjectType struct {
+	Name        string
+	Description string
+	Fields      []*InputValue
+}
+
+func (t *InputObjectType) String() string   { return t.Name }
+func (t *InputObjectType) TypeName() string { return t.Name }
+
+// Field returns the named input field or nil if the type has no such field
+func (t *InputObjectType) Field(name string) *InputValue {
+	return findInputValue(t.Fields, name)
+}
+
+// --[ List / NonNull ]-----------------------------------------------
+
+type List struct {
+	OfType Type
+}
+
+func (t *List) String() string { return "[" + t.OfType.String() + "]" }
+
+type NonNull struct {
+	OfType Type
+}
+
+func (t *NonNull) String() string { return t.OfType.String() + "!" }
+
+// --[ Field ]--------------------------------------------------------
+
+// ResolveFunc resolves the value of a field from the value of its parent, source, and the
+// field's arguments coerced to their declared types
+type ResolveFunc func(source interface{}, args map[string]interface{}) (interface{}, error)
+
+// ResolveContextFunc is a ResolveFunc that's also handed the context of the request
+type ResolveContextFunc func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error)
+
+type Field struct {
+	Name              string
+	Description       string
+	Type              Type
+	Args              []*InputValue
+	DeprecationReason string
+
+	// Resolve resolves the field's value; when nil, the field is read from the source with
+	// DefaultResolve
+	Resolve ResolveFunc
+
+	// ResolveContext, if set, is used in place of Resolve
+	ResolveContext ResolveContextFunc
+}
+
+// Arg returns the named argument or nil if the field has no such argument
+func (f *Field) Arg(name string) *InputValue {
+	return findInputValue(f.Args, name)
+}
+
+// InputValue describes a field argument or an input object field.  DefaultValue is used
+// when the value isn't provided; HasDefaultValue distinguishes a default of nil.
+type InputValue struct {
+	Name            string
+	Description     string
+	Type            Type
+	DefaultValue    interface{}
+	HasDefaultValue bool
+}
+
+func findField(fields []*Field, name string) *Field {
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func findInputValue(values []*InputValue, name string) *InputValue {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// --[ Schema ]-------------------------------------------------------
+
+// Schema declares the types a service exposes.  Query is required; Mutation may be nil.
+// Types lists any additional types that can't be reached from the roots, such as the
+// implementations of an interface.  Directives lists any directives supported in addition
+// to @skip, @include and @deprecated.
+type Schema struct {
+	Query      *ObjectType
+	Mutation   *ObjectType
+	Types      []Named
+	Directives []*Directive
+}
+
+// TypeMap returns every named type reachable from the schema, including the built in
+// scalars and the types of the introspection system, keyed by name
+func (s *Schema) TypeMap() map[string]Named {
+	types := map[string]Named{}
+	for _, t := range []Named{Int, Float, String, Boolean, ID} {
+		types[t.TypeName()] = t
+	}
+	addType(types, schemaType)
+
+	if s.Query != nil {
+		addType(types, s.Query)
+	}
+	if s.Mutation != nil {
+		addType(types, s.Mutation)
+	}
+	for _, t := range s.Types {
+		addType(types, t)
+	}
+	for _, d := range s.Directives {
+		for _, arg := range d.Args {
+			addType(types, arg.Type)
+		}
+	}
+
+	return types
+}
+
+// Type returns the named type or nil if the schema doesn't contain it
+func (s *Schema) Type(name string) Named {
+	return s.TypeMap()[name]
+}
+
+// TypeNames returns the names of every type in the schema in sorted order
+func (s *Schema) TypeNames() []string {
+	return sortedNames(s.TypeMap())
+}
+
+func sortedNames(types map[string]Named) []string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PossibleTypes returns the object types a value of the abstract type may have; the members
+// of a union or the implementations of an interface
+func (s *Schema) PossibleTypes(t Named) []*ObjectType {
+	return s.possibleTypes(s.TypeMap(), t)
+}
+
+func (s *Schema) possibleTypes(types map[string]Named, t Named) []*ObjectType {
+	switch v := t.(type) {
+	case *ObjectType:
+		return []*ObjectType{v}
+
+	case *UnionType:
+		return v.Types
+
+	case *InterfaceType:
+		var objects []*ObjectType
+		for _, name := range sortedNames(types) {
+			if o, ok := types[name].(*ObjectType); ok && o.Implements(v.Name) {
+				objects = append(objects, o)
+			}
+		}
+		return objects
+
+	default:
+		return nil
+	}
+}
+
+// Validate performs basic consistency checks on the schema's types
+func (s *Schema) Validate() error {
+	if s.Query == nil {
+		return fmt.Errorf("schema must define a query type")
+	}
+
+	for name, t := range s.TypeMap() {
+		switch v := t.(type) {
+		case *ObjectType:
+			for _, f := range v.Fields {
+				if f.Type == nil {
+					return fmt.Errorf("%v.%v has no type", name, f.Name)
+				}
+				for _, arg := range f.Args {
+					if !IsInput(arg.Type) {
+						return fmt.Errorf("%v.%v(%v:) must be an input type, got %v", name, f.Name, arg.Name, arg.Type)
+					}
+				}
+			}
+			for _, i := range v.Interfaces {
+				for _, f := range i.Fields {
+					if v.Field(f.Name) == nil {
+						return fmt.Errorf("%v must define %v.%v", name, i.Name, f.Name)
+					}
+				}
+			}
+
+		case *InputObjectType:
+			for _, f := range v.Fields {
+				if !IsInput(f.Type) {
+					return fmt.Errorf("%v.%v must be an input type, got %v", name, f.Name, f.Type)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// addType adds t and every type it references to types
+func addType(types map[string]Named, t Type) {
+	named := Unwrap(t)
+	if named == nil {
+		return
+	}
+	if _, ok := types[named.TypeName()]; ok {
+		return
+	}
+	types[named.TypeName()] = named
+
+	switch v := named.(type) {
+	case *ObjectType:
+		for _, i := range v.Interfaces {
+			addType(types, i)
+		}
+		addFields(types, v.Fields)
+
+	case *InterfaceType:
+		addFields(types, v.Fields)
+
+	case *UnionType:
+		for _, o := range v.Types {
+			addType(types, o)
+		}
+
+	case *InputObjectType:
+		for _, f := range v.Fields {
+			addType(types, f.Type)
+		}
+	}
+}
+
+func addFields(types map[string]Named, fields []*Field) {
+	for _, f := range fields {
+		addType(types, f.Type)
+		for _, arg := range f.Args {
+			addType(types, arg.Type)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// --[ Fixtures ]-----------------------------------------------------
+
+var (
+	episodeEnum = &EnumType{
+		Name: "Episode",
+		Values: []*EnumValue{
+			{Name: "NEWHOPE", Value: 4},
+			{Name: "EMPIRE", Value: 5},
+			{Name: "JEDI", Value: 6},
+		},
+	}
+
+	characterInterface = &InterfaceType{
+		Name: "Character",
+		Fields: []*Field{
+			{Name: "name", Type: &NonNull{OfType: String}},
+		},
+	}
+
+	humanType = &ObjectType{
+		Name:       "Human",
+		Interfaces: []*InterfaceType{characterInterface},
+		Fields: []*Field{
+			{Name: "name", Type: &NonNull{OfType: String}},
+			{Name: "height", Type: Float},
+			{Name: "appearsIn", Type: &List{OfType: episodeEnum}},
+			{Name: "friends", Type: &List{OfType: characterInterface}},
+		},
+	}
+
+	droidType = &ObjectType{
+		Name:       "Droid",
+		Interfaces: []*InterfaceType{characterInterface},
+		Fields: []*Field{
+			{Name: "name", Type: &NonNull{OfType: String}},
+			{Name: "primaryFunction", Type: String},
+		},
+	}
+
+	searchResult = &UnionType{
+		Name:  "SearchResult",
+		Types: []*ObjectType{humanType, droidType},
+	}
+
+	reviewInput = &InputObjectType{
+		Name: "ReviewInput",
+		Fields: []*InputValue{
+			{Name: "stars", Type: &NonNull{OfType: Int}},
+			{Name: "commentary", Type: String, DefaultValue: "none", HasDefaultValue: true},
+		},
+	}
+)
+
+func TestSchema(t *testing.T) {
+	Convey("Given a schema with interfaces, unions and enums", t, func() {
+		s := &Schema{
+			Query: &ObjectType{
+				Name: "Query",
+				Fields: []*Field{
+					{Name: "hero", Type: characterInterface, Args: []*InputValue{
+						{Name: "episode", Type: episodeEnum},
+					}},
+					{Name: "search", Type: &List{OfType: searchResult}},
+				},
+			},
+		}
+
+		Convey("Then TypeMap includes every reachable type, the built in scalars and the introspection types", func() {
+			So(s.TypeNames(), ShouldResemble, []string{
+				"Boolean", "Character", "Droid", "Episode", "Float", "Human", "ID", "Int", "Query", "SearchResult", "String",
+				"__Directive", "__DirectiveLocation", "__EnumValue", "__Field", "__InputValue", "__Schema", "__Type", "__TypeKind",
+			})
+		})
+
+		Convey("Then PossibleTypes returns the implementations of an interface", func() {
+			So(s.PossibleTypes(characterInterface), ShouldResemble, []*ObjectType{droidType, humanType})
+		})
+
+		Convey("Then PossibleTypes returns the members of a union", func() {
+			So(s.PossibleTypes(searchResult), ShouldResemble, []*ObjectType{humanType, droidType})
+		})
+
+		Convey("Then the schema is valid", func() {
+			So(s.Validate(), ShouldBeNil)
+		})
+
+		Convey("When an argument has an output type", func() {
+			s.Query.Fields[0].Args[0].Type = humanType
+
+			Convey("Then Validate should fail", func() {
+				So(s.Validate(), ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a schema without a query type", t, func() {
+		s := &Schema{}
+
+		Convey("Then Validate should fail", func() {
+			So(s.Validate(), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTypeString(t *testing.T) {
+	Convey("Given wrapped types", t, func() {
+		typ := &NonNull{OfType: &List{OfType: &NonNull{OfType: humanType}}}
+
+		Convey("Then String renders the type reference", func() {
+			So(typ.String(), ShouldEqual, "[Human!]!")
+		})
+
+		Convey("Then Unwrap returns the named type", func() {
+			So(Unwrap(typ), ShouldEqual, humanType)
+			So(IsLeaf(typ), ShouldBeFalse)
+			So(IsLeaf(&List{OfType: episodeEnum}), ShouldBeTrue)
+			So(IsInput(reviewInput), ShouldBeTrue)
+		})
+	})
+}
+
+func TestScalars(t *testing.T) {
+	Convey("Given the built in scalars", t, func() {
+		Convey("Then Int rejects values outside 32 bits", func() {
+			v, err := Int.ParseValue(int64(42))
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, int64(42))
+
+			_, err = Int.ParseValue(int64(1) << 40)
+			So(err, ShouldNotBeNil)
+
+			_, err = Int.ParseValue(1.5)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Then Float accepts integers", func() {
+			v, err := Float.ParseValue(int64(3))
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 3.0)
+		})
+
+		Convey("Then ID accepts strings and integers", func() {
+			v, err := ID.ParseValue(int64(7))
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "7")
+		})
+
+		Convey("Then Boolean rejects strings", func() {
+			_, err := Boolean.ParseValue("true")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
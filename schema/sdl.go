@@ -0,0 +1,237 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/savaki/graphql/ast"
+)
+
+// --[ SDL ]----------------------------------------------------------
+
+// String prints the schema as schema definition language
+func (s *Schema) String() string {
+	return s.Document().String()
+}
+
+// Document describes the schema as a schema document.  The schema definition is included
+// only when the root types aren't named Query and Mutation; the directives the schema
+// declares follow, then its types in order of name.  The built in scalars and the types of
+// the introspection system are omitted.
+func (s *Schema) Document() *ast.SchemaDocument {
+	doc := &ast.SchemaDocument{}
+
+	if (s.Query != nil && s.Query.Name != "Query") || (s.Mutation != nil && s.Mutation.Name != "Mutation") {
+		def := &ast.SchemaDefinition{}
+		if s.Query != nil {
+			def.Query = s.Query.Name
+		}
+		if s.Mutation != nil {
+			def.Mutation = s.Mutation.Name
+		}
+		doc.Definitions = append(doc.Definitions, def)
+	}
+
+	for _, d := range s.Directives {
+		doc.Definitions = append(doc.Definitions, &ast.DirectiveDefinition{
+			Description: d.Description,
+			Name:        d.Name,
+			Args:        inputValueDefinitions(d.Args),
+			Repeatable:  d.Repeatable,
+			Locations:   d.Locations,
+		})
+	}
+
+	types := s.TypeMap()
+	for _, name := range sortedNames(types) {
+		if def := typeDefinition(types[name]); def != nil {
+			doc.Definitions = append(doc.Definitions, def)
+		}
+	}
+
+	return doc
+}
+
+// typeDefinition describes a named type; nil for the built in scalars and introspection types
+func typeDefinition(t Named) ast.Definition {
+	if strings.HasPrefix(t.TypeName(), "__") {
+		return nil
+	}
+
+	switch v := t.(type) {
+	case *ScalarType:
+		switch v {
+		case Int, Float, String, Boolean, ID:
+			return nil
+		}
+		return &ast.ScalarDefinition{Description: v.Description, Name: v.Name}
+
+	case *ObjectType:
+		var interfaces []string
+		for _, i := range v.Interfaces {
+			interfaces = append(interfaces, i.Name)
+		}
+		return &ast.ObjectDefinition{
+			Description: v.Description,
+			Name:        v.Name,
+			Interfaces:  interfaces,
+			Fields:      fieldDefinitions(v.Fields),
+		}
+
+	case *InterfaceType:
+		return &ast.InterfaceDefinition{
+			Description: v.Description,
+			Name:        v.Name,
+			Fields:      fieldDefinitions(v.Fields),
+		}
+
+	case *UnionType:
+		var types []string
+		for _, o := range v.Types {
+			types = append(types, o.Name)
+		}
+		return &ast.UnionDefinition{Description: v.Description, Name: v.Name, Types: types}
+
+	case *EnumType:
+		values := make([]*ast.EnumValueDefinition, len(v.Values))
+		for index, value := range v.Values {
+			values[index] = &ast.EnumValueDefinition{
+				Description: value.Description,
+				Name:        value.Name,
+				Directives:  deprecated(value.DeprecationReason),
+			}
+		}
+		return &ast.EnumDefinition{Description: v.Description, Name: v.Name, Values: values}
+
+	case *InputObjectType:
+		return &ast.InputObjectDefinition{
+			Description: v.Description,
+			Name:        v.Name,
+			Fields:      inputValueDefinitions(v.Fields),
+		}
+
+	default:
+		return nil
+	}
+}
+
+func fieldDefinitions(fields []*Field) []*ast.FieldDefinition {
+	defs := make([]*ast.FieldDefinition, len(fields))
+	for index, f := range fields {
+		defs[index] = &ast.FieldDefinition{
+			Description: f.Description,
+			Name:        f.Name,
+			Args:        inputValueDefinitions(f.Args),
+			Type:        typeRef(f.Type),
+			Directives:  deprecated(f.DeprecationReason),
+		}
+	}
+	return defs
+}
+
+func inputValueDefinitions(values []*InputValue) []*ast.InputValueDefinition {
+	defs := make([]*ast.InputValueDefinition, len(values))
+	for index, v := range values {
+		defs[index] = &ast.InputValueDefinition{
+			Description: v.Description,
+			Name:        v.Name,
+			Type:        typeRef(v.Type),
+		}
+		if v.HasDefaultValue {
+			defs[index].DefaultValue = literal(v.Type, v.DefaultValue)
+		}
+	}
+	return defs
+}
+
+// deprecated returns the @deprecated directive for a deprecation reason, if there is one
+func deprecated(reason string) []*ast.Directive {
+	if reason == "" {
+		return nil
+	}
+	return []*ast.Directive{
+		{Name: "deprecated", Args: []*ast.Arg{{Name: "reason", Value: &ast.StringValue{Value: reason}}}},
+	}
+}
+
+// typeRef returns the reference to a type as it's written in a document e.g. [User!]
+func typeRef(t Type) *ast.Type {
+	switch v := t.(type) {
+	case *NonNull:
+		ref := typeRef(v.OfType)
+		ref.NonNull = true
+		return ref
+	case *List:
+		return &ast.Type{Elem: typeRef(v.OfType)}
+	default:
+		return &ast.Type{Name: t.String()}
+	}
+}
+
+// literal converts a go value of the given input type to the literal that would be written
+// for it in a document.  Enum values are written by name and input objects, held as maps,
+// with their fields in order of name.
+func literal(t Type, v interface{}) ast.Value {
+	if isNil(v) {
+		return &ast.NullValue{}
+	}
+
+	switch typ := t.(type) {
+	case *NonNull:
+		return literal(typ.OfType, v)
+
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return literal(typ.OfType, v)
+		}
+		values := make([]ast.Value, rv.Len())
+		for i := range values {
+			values[i] = literal(typ.OfType, rv.Index(i).Interface())
+		}
+		return &ast.ListValue{Values: values}
+
+	case *EnumType:
+		for _, value := range typ.Values {
+			if reflect.DeepEqual(value.GoValue(), v) {
+				return &ast.EnumValue{Value: value.Name}
+			}
+		}
+
+	case *InputObjectType:
+		if fields, ok := v.(map[string]interface{}); ok {
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			object := &ast.ObjectValue{}
+			for _, name := range names {
+				var valueType Type = String
+				if def := typ.Field(name); def != nil {
+					valueType = def.Type
+				}
+				object.Fields = append(object.Fields, &ast.ObjectField{Name: name, Value: literal(valueType, fields[name])})
+			}
+			return object
+		}
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return &ast.StringValue{Value: fmt.Sprint(v)}
+	case reflect.Bool:
+		return &ast.BooleanValue{Value: reflect.ValueOf(v).Bool()}
+	case reflect.Float32, reflect.Float64:
+		return &ast.FloatValue{Value: fmt.Sprint(v)}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &ast.IntValue{Value: fmt.Sprint(v)}
+	default:
+		// values of other types are written as they print
+		return &ast.EnumValue{Value: fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/savaki/graphql/ast"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSDL(t *testing.T) {
+	Convey("Given a schema with interfaces, unions, enums and input objects", t, func() {
+		s := &Schema{
+			Query: &ObjectType{
+				Name:        "Root",
+				Description: "The root of all queries.",
+				Fields: []*Field{
+					{Name: "hero", Type: characterInterface, Args: []*InputValue{
+						{Name: "episode", Type: episodeEnum, DefaultValue: 4, HasDefaultValue: true},
+					}},
+					{Name: "search", Type: &List{OfType: searchResult}},
+					{Name: "droids", Type: &NonNull{OfType: &List{OfType: &NonNull{OfType: droidType}}}, DeprecationReason: "Use search."},
+				},
+			},
+			Mutation: &ObjectType{
+				Name: "Mutation",
+				Fields: []*Field{
+					{Name: "createReview", Type: Int, Args: []*InputValue{
+						{Name: "review", Type: &NonNull{OfType: reviewInput}},
+					}},
+				},
+			},
+			Types: []Named{humanType, &ScalarType{Name: "Date"}},
+		}
+
+		Convey("When I print it", func() {
+			sdl := s.String()
+
+			Convey("Then it should be written as schema definition language", func() {
+				So(sdl, ShouldEqual, `schema {
+  query: Root
+  mutation: Mutation
+}
+
+interface Character {
+  name: String!
+}
+
+scalar Date
+
+type Droid implements Character {
+  name: String!
+  primaryFunction: String
+}
+
+enum Episode {
+  NEWHOPE
+  EMPIRE
+  JEDI
+}
+
+type Human implements Character {
+  name: String!
+  height: Float
+  appearsIn: [Episode]
+  friends: [Character]
+}
+
+type Mutation {
+  createReview(review: ReviewInput!): Int
+}
+
+input ReviewInput {
+  stars: Int!
+  commentary: String = "none"
+}
+
+"""The root of all queries."""
+type Root {
+  hero(episode: Episode = NEWHOPE): Character
+  search: [SearchResult]
+  droids: [Droid!]! @deprecated(reason: "Use search.")
+}
+
+union SearchResult = Human | Droid
+`)
+			})
+
+			Convey("Then it should parse back to the same document", func() {
+				doc, err := ast.ParseSchema(sdl)
+				So(err, ShouldBeNil)
+				So(doc.String(), ShouldEqual, sdl)
+			})
+		})
+	})
+
+	Convey("Given a schema with the conventional root names", t, func() {
+		s := &Schema{Query: &ObjectType{Name: "Query", Fields: []*Field{{Name: "a", Type: String}}}}
+
+		Convey("Then the schema definition should be omitted", func() {
+			So(s.String(), ShouldEqual, "type Query {\n  a: String\n}\n")
+		})
+	})
+}
@@ -0,0 +1,512 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/savaki/graphql"
+)
+
+var (
+	errNotAList    = errors.New("invalid attempt to select from a list; use Elements")
+	errNotAnObject = errors.New("invalid attempt to select from a leaf value")
+)
+
+// NewStore exposes the schema as a graphql.Store.  root is handed to the resolvers of the
+// query and mutation root fields as their source.  The query root also answers the
+// introspection fields __schema and __type(name:).  The schema's types are indexed once here
+// so the schema should not be modified afterwards.
+func NewStore(s *Schema, root interface{}) graphql.Store {
+	types := s.TypeMap()
+
+	possible := map[string][]*ObjectType{}
+	for name, t := range types {
+		switch t.(type) {
+		case *InterfaceType, *UnionType:
+			possible[name] = s.possibleTypes(types, t)
+		}
+	}
+
+	return &store{
+		schema:   s,
+		root:     root,
+		types:    types,
+		possible: possible,
+	}
+}
+
+type store struct {
+	schema   *Schema
+	root     interface{}
+	types    map[string]Named
+	possible map[string][]*ObjectType // the possible types of each interface and union
+}
+
+func (s *store) TypeName() string {
+	return s.schema.Query.Name
+}
+
+func (s *store) Satisfies(typeCondition string) bool {
+	return s.object(s.schema.Query).Satisfies(typeCondition)
+}
+
+func (s *store) Query(c *graphql.Context) (graphql.Field, error) {
+	return s.QueryContext(context.Background(), c)
+}
+
+func (s *store) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	return s.object(s.schema.Query).QueryContext(ctx, c)
+}
+
+func (s *store) NonNullField(name string) bool {
+	return s.object(s.schema.Query).NonNullField(name)
+}
+
+func (s *store) Mutate(c *graphql.Context) (graphql.Field, error) {
+	return s.MutateContext(context.Background(), c)
+}
+
+func (s *store) MutateContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	if s.schema.Mutation == nil {
+		return nil, graphql.ErrNotImplemented
+	}
+	return s.object(s.schema.Mutation).QueryContext(ctx, c)
+}
+
+func (s *store) object(typ *ObjectType) *object {
+	return &object{
+		store:  s,
+		typ:    typ,
+		source: s.root,
+	}
+}
+
+// --[ Selection ]----------------------------------------------------
+
+// object resolves the fields of an object type against a source value
+type object struct {
+	store  *store
+	typ    *ObjectType
+	source interface{}
+}
+
+func (o *object) TypeName() string {
+	return o.typ.Name
+}
+
+// Satisfies reports whether the object is of the named type, implements the named interface
+// or is a member of the named union
+func (o *object) Satisfies(typeCondition string) bool {
+	if o.typ.Name == typeCondition || o.typ.Implements(typeCondition) {
+		return true
+	}
+	if _, ok := o.store.types[typeCondition].(*UnionType); ok {
+		for _, member := range o.store.possible[typeCondition] {
+			if member == o.typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NonNullField reports whether the named field is declared non-null
+func (o *object) NonNullField(name string) bool {
+	switch {
+	case name == "__typename":
+		return true
+	case name == "__schema" && o.typ == o.store.schema.Query:
+		return true
+	}
+
+	f := o.typ.Field(name)
+	if f == nil {
+		return false
+	}
+	_, ok := f.Type.(*NonNull)
+	return ok
+}
+
+func (o *object) Query(c *graphql.Context) (graphql.Field, error) {
+	return o.QueryContext(context.Background(), c)
+}
+
+// QueryContext resolves the named field; ctx is handed to the field's ResolveContext
+func (o *object) QueryContext(ctx context.Context, c *graphql.Context) (graphql.Field, error) {
+	if c.Name == "__typename" {
+		return o.store.newField(&NonNull{OfType: String}, o.typ.Name), nil
+	}
+	if o.typ == o.store.schema.Query {
+		if meta, err := o.store.metaField(c); meta != nil || err != nil {
+			return meta, err
+		}
+	}
+
+	f := o.typ.Field(c.Name)
+	if f == nil {
+		return nil, graphql.ErrFieldNotFound
+	}
+
+	args, err := coerceArgs(f, c.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	switch {
+	case f.ResolveContext != nil:
+		v, err = f.ResolveContext(ctx, o.source, args)
+	case f.Resolve != nil:
+		v, err = f.Resolve(o.source, args)
+	default:
+		v, err = DefaultResolve(f.Name)(o.source, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return o.store.newField(f.Type, v), nil
+}
+
+// --[ Field ]--------------------------------------------------------
+
+func (s *store) newField(typ Type, v interface{}) graphql.Field {
+	t := typ
+	if nonNull, ok := t.(*NonNull); ok {
+		t = nonNull.OfType
+	}
+	if _, ok := t.(*List); ok {
+		return &list{store: s, typ: typ, value: v}
+	}
+	return &field{store: s, typ: typ, value: v}
+}
+
+// field holds a resolved value along with its declared type
+type field struct {
+	store *store
+	typ   Type
+	value interface{}
+}
+
+func (f *field) Value() (graphql.Value, error) {
+	if isNil(f.value) {
+		return nil, checkNull(f.typ)
+	}
+
+	switch t := Unwrap(f.typ).(type) {
+	case *ScalarType:
+		if t.Serialize == nil {
+			return f.value, nil
+		}
+		return t.Serialize(f.value)
+
+	case *EnumType:
+		for _, v := range t.Values {
+			if reflect.DeepEqual(v.GoValue(), f.value) {
+				return v.Name, nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not a valid value for enum %v", f.value, t.Name)
+
+	default:
+		return nil, graphql.ErrNotAScalar
+	}
+}
+
+// Selection returns the object the value represents, resolving the concrete type of
+// interface and union values, or nil when the value is null
+func (f *field) Selection() (graphql.Selection, error) {
+	if isNil(f.value) {
+		return nil, checkNull(f.typ)
+	}
+
+	switch t := Unwrap(f.typ).(type) {
+	case *ObjectType:
+		return &object{store: f.store, typ: t, source: f.value}, nil
+
+	case *InterfaceType, *UnionType:
+		typ, err := f.store.resolveType(t, f.value)
+		if err != nil {
+			return nil, err
+		}
+		return &object{store: f.store, typ: typ, source: f.value}, nil
+
+	default:
+		return nil, errNotAnObject
+	}
+}
+
+// list holds a resolved list value; each element is exposed as a field of the element type
+type list struct {
+	store *store
+	typ   Type
+	value interface{}
+}
+
+func (l *list) Elements() ([]graphql.Field, error) {
+	if isNil(l.value) {
+		return nil, checkNull(l.typ)
+	}
+
+	rv := reflect.ValueOf(l.value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected list value for %v, got %T", l.typ, l.value)
+	}
+
+	elem := l.elem()
+	fields := make([]graphql.Field, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		fields[i] = l.store.newField(elem, rv.Index(i).Interface())
+	}
+	return fields, nil
+}
+
+// NonNullElements reports whether the list's elements are declared non-null
+func (l *list) NonNullElements() bool {
+	_, ok := l.elem().(*NonNull)
+	return ok
+}
+
+func (l *list) elem() Type {
+	t := l.typ
+	if nonNull, ok := t.(*NonNull); ok {
+		t = nonNull.OfType
+	}
+	return t.(*List).OfType
+}
+
+func (l *list) Value() (graphql.Value, error) {
+	elements, err := l.Elements()
+	if elements == nil || err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(elements))
+	for index, element := range elements {
+		v, err := element.Value()
+		if err != nil {
+			return nil, err
+		}
+		values[index] = v
+	}
+	return values, nil
+}
+
+func (l *list) Selection() (graphql.Selection, error) {
+	return nil, errNotAList
+}
+
+// resolveType determines the object type of a value of an interface or union type.  The
+// abstract type's ResolveType is used if set, then the IsTypeOf of each possible type and
+// finally the __typename key of a map.
+func (s *store) resolveType(t Named, value interface{}) (*ObjectType, error) {
+	var resolve func(interface{}) *ObjectType
+	switch v := t.(type) {
+	case *ObjectType:
+		return v, nil
+	case *InterfaceType:
+		resolve = v.ResolveType
+	case *UnionType:
+		resolve = v.ResolveType
+	}
+	if resolve != nil {
+		if typ := resolve(value); typ != nil {
+			return typ, nil
+		}
+	}
+
+	possibleTypes := s.possible[t.TypeName()]
+	for _, typ := range possibleTypes {
+		if typ.IsTypeOf != nil && typ.IsTypeOf(value) {
+			return typ, nil
+		}
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		if name, ok := m["__typename"].(string); ok {
+			for _, typ := range possibleTypes {
+				if typ.Name == name {
+					return typ, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unable to determine the %v type of %T", t.TypeName(), value)
+}
+
+// DefaultResolve returns a ResolveFunc that reads the named key of a map or the exported
+// struct field whose name matches, ignoring case
+func DefaultResolve(name string) ResolveFunc {
+	return func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		rv := reflect.ValueOf(source)
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return nil, nil
+			}
+			rv = rv.Elem()
+		}
+
+		switch rv.Kind() {
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return nil, nil
+			}
+			v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+			if !v.IsValid() {
+				return nil, nil
+			}
+			return v.Interface(), nil
+
+		case reflect.Struct:
+			v := rv.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+			if !v.IsValid() || !v.CanInterface() {
+				return nil, nil
+			}
+			return v.Interface(), nil
+
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// checkNull returns an error if a null value was resolved for a non-null type
+func checkNull(typ Type) error {
+	if _, ok := typ.(*NonNull); ok {
+		return fmt.Errorf("non-null %v resolved to null", typ)
+	}
+	return nil
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// --[ Args ]---------------------------------------------------------
+
+// coerceArgs converts the arguments handed to a field to the field's declared argument
+// types, applying defaults and enforcing required arguments
+func coerceArgs(f *Field, args []graphql.Arg) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, arg := range args {
+		def := f.Arg(arg.Name)
+		if def == nil {
+			return nil, fmt.Errorf("unknown argument %v on field %v", arg.Name, f.Name)
+		}
+		v, err := CoerceInput(def.Type, arg.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for argument %v => %v", arg.Name, err)
+		}
+		values[arg.Name] = v
+	}
+
+	for _, def := range f.Args {
+		if err := applyDefault(values, def); err != nil {
+			return nil, fmt.Errorf("argument %v on field %v %v", def.Name, f.Name, err)
+		}
+	}
+
+	return values, nil
+}
+
+// CoerceInput converts an argument or variable value to the type declared for it; scalars
+// are parsed with ParseValue, enum names are replaced by their values and input objects
+// have their fields coerced and defaulted
+func CoerceInput(t Type, v interface{}) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected non-null value of type %v", t)
+		}
+		return CoerceInput(nonNull.OfType, v)
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	switch typ := t.(type) {
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		values := make([]interface{}, len(items))
+		for index, item := range items {
+			value, err := CoerceInput(typ.OfType, item)
+			if err != nil {
+				return nil, err
+			}
+			values[index] = value
+		}
+		return values, nil
+
+	case *ScalarType:
+		if typ.ParseValue == nil {
+			return v, nil
+		}
+		return typ.ParseValue(v)
+
+	case *EnumType:
+		if name, ok := v.(string); ok {
+			if value := typ.Value(name); value != nil {
+				return value.GoValue(), nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not a valid value for enum %v", v, typ.Name)
+
+	case *InputObjectType:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected input object %v, got %T", typ.Name, v)
+		}
+		values := map[string]interface{}{}
+		for name, value := range fields {
+			def := typ.Field(name)
+			if def == nil {
+				return nil, fmt.Errorf("unknown field %v on input object %v", name, typ.Name)
+			}
+			value, err := CoerceInput(def.Type, value)
+			if err != nil {
+				return nil, err
+			}
+			values[name] = value
+		}
+		for _, def := range typ.Fields {
+			if err := applyDefault(values, def); err != nil {
+				return nil, fmt.Errorf("field %v on input object %v %v", def.Name, typ.Name, err)
+			}
+		}
+		return values, nil
+
+	default:
+		return nil, fmt.Errorf("%v is not an input type", t)
+	}
+}
+
+// applyDefault assigns the default of an input value that wasn't provided and verifies
+// required values are present
+func applyDefault(values map[string]interface{}, def *InputValue) error {
+	if _, ok := values[def.Name]; ok {
+		return nil
+	}
+	if def.HasDefaultValue {
+		values[def.Name] = def.DefaultValue
+		return nil
+	}
+	if _, ok := def.Type.(*NonNull); ok {
+		return fmt.Errorf("of type %v is required", def.Type)
+	}
+	return nil
+}
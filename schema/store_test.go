@@ -0,0 +1,318 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/savaki/graphql"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type human struct {
+	Name      string
+	Height    float64
+	AppearsIn []int
+	Friends   []interface{}
+}
+
+type droid struct {
+	Name            string
+	PrimaryFunction string
+}
+
+// lengthUnit leaves each Value unset so its names are used as the go values
+var lengthUnit = &EnumType{
+	Name:   "LengthUnit",
+	Values: []*EnumValue{{Name: "METER"}, {Name: "FOOT"}},
+}
+
+// villainType has a non-null name that its resolvers never provide
+var villainType = &ObjectType{
+	Name: "Villain",
+	Fields: []*Field{
+		{Name: "name", Type: &NonNull{OfType: String}},
+	},
+}
+
+func newStore() (graphql.Store, *[]map[string]interface{}) {
+	r2d2 := &droid{Name: "R2-D2", PrimaryFunction: "Astromech"}
+	luke := &human{Name: "Luke", Height: 1.72, AppearsIn: []int{4, 5, 6}, Friends: []interface{}{r2d2}}
+
+	humanType.IsTypeOf = func(v interface{}) bool { _, ok := v.(*human); return ok }
+	droidType.IsTypeOf = func(v interface{}) bool { _, ok := v.(*droid); return ok }
+
+	var reviews []map[string]interface{}
+	s := &Schema{
+		Query: &ObjectType{
+			Name: "Query",
+			Fields: []*Field{
+				{
+					Name: "hero",
+					Type: characterInterface,
+					Args: []*InputValue{
+						{Name: "episode", Type: episodeEnum, DefaultValue: 4, HasDefaultValue: true},
+					},
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						if args["episode"] == 5 {
+							return luke, nil
+						}
+						return r2d2, nil
+					},
+				},
+				{
+					Name: "search",
+					Type: &List{OfType: searchResult},
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						return []interface{}{luke, r2d2}, nil
+					},
+				},
+				{
+					Name: "missing",
+					Type: humanType,
+				},
+				{
+					Name: "villain",
+					Type: villainType,
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						return map[string]interface{}{}, nil
+					},
+				},
+				{
+					Name: "squad",
+					Type: &List{OfType: &NonNull{OfType: villainType}},
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						return []interface{}{map[string]interface{}{}}, nil
+					},
+				},
+				{
+					Name: "rival",
+					Type: &NonNull{OfType: villainType},
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						return map[string]interface{}{}, nil
+					},
+				},
+				{
+					Name: "unit",
+					Type: lengthUnit,
+					Args: []*InputValue{
+						{Name: "u", Type: lengthUnit},
+					},
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						return args["u"], nil
+					},
+				},
+			},
+		},
+		Mutation: &ObjectType{
+			Name: "Mutation",
+			Fields: []*Field{
+				{
+					Name: "createReview",
+					Type: Int,
+					Args: []*InputValue{
+						{Name: "review", Type: &NonNull{OfType: reviewInput}},
+					},
+					Resolve: func(source interface{}, args map[string]interface{}) (interface{}, error) {
+						reviews = append(reviews, args["review"].(map[string]interface{}))
+						return len(reviews), nil
+					},
+				},
+			},
+		},
+		Types: []Named{humanType, droidType},
+	}
+
+	return NewStore(s, nil), &reviews
+}
+
+func TestStore(t *testing.T) {
+	Convey("Given a schema backed store", t, func() {
+		store, reviews := newStore()
+		executor := &graphql.Executor{Store: store}
+		w := &bytes.Buffer{}
+
+		Convey("When I query an interface field with a default argument", func() {
+			err := executor.Handle(`{ hero { name ... on Droid { primaryFunction } } }`, w)
+
+			Convey("Then the concrete type is resolved", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"hero":{"name":"R2-D2","primaryFunction":"Astromech"}}}`)
+			})
+		})
+
+		Convey("When I use fragments conditioned on an interface and a union", func() {
+			err := executor.Handle(`{
+				hero(episode: EMPIRE) { ...C ... on Human { height } }
+				search { ... on SearchResult { ... on Character { name } } }
+			}
+			fragment C on Character { name }`, w)
+
+			Convey("Then the fragments apply to the types that belong to them", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"hero":{"name":"Luke","height":1.72},"search":[{"name":"Luke"},{"name":"R2-D2"}]}}`)
+			})
+		})
+
+		Convey("When I pass an enum argument", func() {
+			err := executor.Handle(`{ hero(episode: EMPIRE) { name ... on Human { height appearsIn friends { name } } } }`, w)
+
+			Convey("Then enum values and lists are written", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"hero":{"name":"Luke","height":1.72,"appearsIn":["NEWHOPE","EMPIRE","JEDI"],"friends":[{"name":"R2-D2"}]}}}`)
+			})
+		})
+
+		Convey("When I query a list of unions", func() {
+			err := executor.Handle(`{ search { ... on Human { name } ... on Droid { primaryFunction } } }`, w)
+
+			Convey("Then each element is resolved to its type", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"search":[{"name":"Luke"},{"primaryFunction":"Astromech"}]}}`)
+			})
+		})
+
+		Convey("When a nullable object resolves to nil", func() {
+			err := executor.Handle(`{ missing { name } }`, w)
+
+			Convey("Then null is written", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"missing":null}}`)
+			})
+		})
+
+		Convey("When I select a field the type doesn't define below the root", func() {
+			err := executor.Handle(`{ hero { name nope } }`, w)
+
+			Convey("Then the field is null and the error is reported with its path", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"hero":{"name":"R2-D2","nope":null}},"errors":[{"message":"field not found","locations":[{"line":1,"column":15}],"path":["hero","nope"]}]}`)
+			})
+		})
+
+		Convey("When a nested non-null field resolves to nil", func() {
+			err := executor.Handle(`{ hero { name } villain { name } }`, w)
+
+			Convey("Then the null propagates to the nearest nullable parent", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"hero":{"name":"R2-D2"},"villain":null},"errors":[{"message":"non-null String! resolved to null","locations":[{"line":1,"column":27}],"path":["villain","name"]}]}`)
+			})
+		})
+
+		Convey("When an element of a list of non-null values is null", func() {
+			err := executor.Handle(`{ squad { name } }`, w)
+
+			Convey("Then the list is null", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"squad":null},"errors":[{"message":"non-null String! resolved to null","locations":[{"line":1,"column":11}],"path":["squad",0,"name"]}]}`)
+			})
+		})
+
+		Convey("When the null propagates to a non-null root field", func() {
+			err := executor.Handle(`{ hero { name } rival { name } }`, w)
+
+			Convey("Then data is null", func() {
+				So(err, ShouldNotBeNil)
+				So(w.String(), ShouldEqual, `{"data":null,"errors":[{"message":"non-null String! resolved to null","locations":[{"line":1,"column":25}],"path":["rival","name"]}]}`)
+			})
+		})
+
+		Convey("When I select from a list of leaf values", func() {
+			err := executor.Handle(`{ hero(episode: EMPIRE) { ... on Human { appearsIn { name } } } }`, w)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When an enum value has no go value of its own", func() {
+			err := executor.Handle(`{ unit(u: FOOT) }`, w)
+
+			Convey("Then its name is used", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"unit":"FOOT"}}`)
+			})
+		})
+
+		Convey("When I pass an invalid enum value", func() {
+			err := executor.Handle(`{ hero(episode: CLONES) { name } }`, w)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When I call a mutation with an input object", func() {
+			err := executor.Handle(`mutation { createReview(review: {stars: 5}) }`, w)
+
+			Convey("Then the input object is coerced with its defaults", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"createReview":1}}`)
+				So(*reviews, ShouldResemble, []map[string]interface{}{
+					{"stars": int64(5), "commentary": "none"},
+				})
+			})
+		})
+
+		Convey("When I omit a required argument", func() {
+			err := executor.Handle(`mutation { createReview }`, w)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestDefaultResolve(t *testing.T) {
+	Convey("Given a map and a struct", t, func() {
+		m := map[string]interface{}{"name": "map"}
+		s := &droid{Name: "struct"}
+
+		Convey("Then DefaultResolve reads the key or field", func() {
+			v, err := DefaultResolve("name")(m, nil)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "map")
+
+			v, err = DefaultResolve("name")(s, nil)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "struct")
+
+			v, err = DefaultResolve("unknown")(s, nil)
+			So(err, ShouldBeNil)
+			So(v, ShouldBeNil)
+		})
+	})
+}
+
+type tenantKey struct{}
+
+func TestResolveContext(t *testing.T) {
+	Convey("Given a field resolved with the context of the request", t, func() {
+		s := &Schema{
+			Query: &ObjectType{
+				Name: "Query",
+				Fields: []*Field{
+					{
+						Name: "tenant",
+						Type: String,
+						ResolveContext: func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+							return ctx.Value(tenantKey{}), nil
+						},
+					},
+				},
+			},
+		}
+
+		Convey("When I query it", func() {
+			w := &bytes.Buffer{}
+			ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+			err := graphql.New(NewStore(s, nil)).HandleContext(ctx, `{ tenant }`, "", nil, w)
+
+			Convey("Then the resolver should be handed the context", func() {
+				So(err, ShouldBeNil)
+				So(w.String(), ShouldEqual, `{"data":{"tenant":"acme"}}`)
+			})
+		})
+	})
+}
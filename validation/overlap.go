@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/savaki/graphql/ast"
+	"github.com/savaki/graphql/schema"
+)
+
+// --[ Overlapping Fields ]-------------------------------------------
+
+// fieldAndDef pairs a selected field with its definition and the type it was selected from;
+// def is nil when the field is unknown
+type fieldAndDef struct {
+	parent schema.Named
+	field  *ast.Field
+	def    *schema.Field
+}
+
+// overlaps reports fields of a selection set, including those of the fragments within it,
+// that share a response key but can't be merged into a single result
+func (c *check) overlaps(parent schema.Named, selection *ast.Selection) {
+	fields, keys := c.collectFields(parent, selection)
+	for _, key := range keys {
+		matches := fields[key]
+		for i := 0; i < len(matches); i++ {
+			for j := i + 1; j < len(matches); j++ {
+				if reason := c.conflict(matches[i], matches[j], false); reason != "" {
+					c.errorf([]ast.Loc{matches[i].field.Loc, matches[j].field.Loc},
+						"Fields %q conflict because %v. Use different aliases on the fields to fetch both if this was intentional.", key, reason)
+				}
+			}
+		}
+	}
+}
+
+// collectFields groups the fields of a selection set by response key, expanding fragments;
+// keys holds each response key in the order first seen
+func (c *check) collectFields(parent schema.Named, selection *ast.Selection) (map[string][]fieldAndDef, []string) {
+	fields := map[string][]fieldAndDef{}
+	var keys []string
+	visited := map[string]bool{}
+
+	var collect func(parent schema.Named, selection *ast.Selection)
+	collect = func(parent schema.Named, selection *ast.Selection) {
+		if selection == nil {
+			return
+		}
+		for _, selector := range selection.Selectors {
+			switch s := selector.(type) {
+			case *ast.Field:
+				key := s.Key()
+				if _, ok := fields[key]; !ok {
+					keys = append(keys, key)
+				}
+				fields[key] = append(fields[key], fieldAndDef{parent: parent, field: s, def: c.fieldDef(parent, s.Name)})
+
+			case *ast.FragmentSpread:
+				fragment := c.doc.Fragment(s.Name)
+				if fragment == nil || visited[s.Name] {
+					continue
+				}
+				visited[s.Name] = true
+				if t, ok := c.types[fragment.On]; ok {
+					collect(t, fragment.Selection)
+				}
+
+			case *ast.InlineFragment:
+				t := parent
+				if s.On != "" {
+					var ok bool
+					if t, ok = c.types[s.On]; !ok {
+						continue
+					}
+				}
+				collect(t, s.Selection)
+			}
+		}
+	}
+	collect(parent, selection)
+
+	return fields, keys
+}
+
+// conflict returns why two fields with the same response key can't be merged or "" if they
+// can.  Fields selected from different object types never apply to the same value, so they
+// may differ in name and arguments but must still return the same shape.
+func (c *check) conflict(a, b fieldAndDef, exclusive bool) string {
+	exclusive = exclusive || (a.parent != b.parent && isObject(a.parent) && isObject(b.parent))
+	if !exclusive {
+		if a.field.Name != b.field.Name {
+			return fmt.Sprintf("%q and %q are different fields", a.field.Name, b.field.Name)
+		}
+		if !sameArgs(a.field.Args, b.field.Args) {
+			return "they have differing arguments"
+		}
+	}
+
+	if a.def == nil || b.def == nil {
+		return ""
+	}
+	if typesConflict(a.def.Type, b.def.Type) {
+		return fmt.Sprintf("they return conflicting types %q and %q", a.def.Type, b.def.Type)
+	}
+
+	if a.field.IsScalar() || b.field.IsScalar() {
+		return ""
+	}
+	subA, keys := c.collectFields(schema.Unwrap(a.def.Type), a.field.Selection)
+	subB, _ := c.collectFields(schema.Unwrap(b.def.Type), b.field.Selection)
+	for _, key := range keys {
+		for _, x := range subA[key] {
+			for _, y := range subB[key] {
+				if reason := c.conflict(x, y, exclusive); reason != "" {
+					return fmt.Sprintf("subfields %q conflict because %v", key, reason)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// typesConflict reports whether two field types have a different shape; lists and non-nulls
+// must match and leaf types must be the same
+func typesConflict(a, b schema.Type) bool {
+	switch t := a.(type) {
+	case *schema.NonNull:
+		other, ok := b.(*schema.NonNull)
+		return !ok || typesConflict(t.OfType, other.OfType)
+	case *schema.List:
+		other, ok := b.(*schema.List)
+		return !ok || typesConflict(t.OfType, other.OfType)
+	}
+
+	switch b.(type) {
+	case *schema.NonNull, *schema.List:
+		return true
+	}
+
+	if schema.IsLeaf(a) || schema.IsLeaf(b) {
+		return schema.Unwrap(a) != schema.Unwrap(b)
+	}
+	return false
+}
+
+func isObject(t schema.Named) bool {
+	_, ok := t.(*schema.ObjectType)
+	return ok
+}
+
+// sameArgs reports whether two fields were given the same arguments, in any order
+func sameArgs(a, b []*ast.Arg) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, arg := range a {
+		other := findArg(b, arg.Name)
+		if other == nil || other.Value.String() != arg.Value.String() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,525 @@
+// Package validation checks a query document against a schema before it's executed so bad
+// queries are rejected up front rather than failing part way through the response.
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/savaki/graphql"
+	"github.com/savaki/graphql/ast"
+	"github.com/savaki/graphql/schema"
+)
+
+// --[ Validator ]----------------------------------------------------
+
+// New returns a graphql.Validator that checks documents against the schema.  The schema's
+// types are indexed once here so the schema should not be modified afterwards.
+func New(s *schema.Schema) graphql.Validator {
+	return &validator{
+		schema: s,
+		types:  s.TypeMap(),
+	}
+}
+
+type validator struct {
+	schema *schema.Schema
+	types  map[string]schema.Named
+}
+
+// Validate implements graphql.Validator; the errors found are returned as graphql.Errors
+func (v *validator) Validate(doc *ast.Document) error {
+	if errs := v.validate(doc); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate checks doc against the schema and returns the errors found or nil if the document
+// is valid
+func Validate(s *schema.Schema, doc *ast.Document) graphql.Errors {
+	v := &validator{
+		schema: s,
+		types:  s.TypeMap(),
+	}
+	return v.validate(doc)
+}
+
+func (v *validator) validate(doc *ast.Document) graphql.Errors {
+	c := &check{
+		validator: v,
+		doc:       doc,
+		fragments: map[string]*scope{},
+		seen:      map[string]bool{},
+	}
+
+	for _, fragment := range doc.Fragments {
+		sc := &scope{}
+		c.fragments[fragment.Name] = sc
+		if t := c.typeCondition(fragment.On, fragment.Loc); t != nil {
+			c.selection(t, fragment.Selection, sc)
+		}
+	}
+
+	c.operationNames()
+
+	used := map[string]bool{}
+	for _, op := range doc.Operations {
+		c.operation(op, used)
+	}
+
+	for _, fragment := range doc.Fragments {
+		if !used[fragment.Name] {
+			c.errorf([]ast.Loc{fragment.Loc}, "Fragment %q is never used.", fragment.Name)
+		}
+	}
+	c.fragmentCycles()
+
+	return c.errors
+}
+
+// --[ Check ]--------------------------------------------------------
+
+// check holds the state of a single document being validated
+type check struct {
+	*validator
+	doc       *ast.Document
+	fragments map[string]*scope // what each fragment refers to
+	errors    graphql.Errors
+	seen      map[string]bool // errors already reported
+}
+
+// scope collects the variables and fragments referenced by an operation or fragment
+type scope struct {
+	variables []*usage
+	spreads   []string
+}
+
+// usage records a reference to a variable along with the type expected where it appears;
+// expected is nil when the position has no known type
+type usage struct {
+	name     string
+	expected schema.Type
+	loc      ast.Loc
+}
+
+// errorf records an error; an error found more than once, e.g. within a fragment spread in
+// several places, is only reported the first time
+func (c *check) errorf(locations []ast.Loc, format string, args ...interface{}) {
+	err := &graphql.Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: locations,
+	}
+	if key := err.Error(); !c.seen[key] {
+		c.seen[key] = true
+		c.errors = append(c.errors, err)
+	}
+}
+
+// operationNames checks that each operation may be selected by name; an anonymous operation
+// must be the only operation in the document
+func (c *check) operationNames() {
+	names := map[string]bool{}
+	for _, op := range c.doc.Operations {
+		if op.Name == "" {
+			if len(c.doc.Operations) > 1 {
+				c.errorf([]ast.Loc{op.Loc}, "This anonymous operation must be the only defined operation.")
+			}
+			continue
+		}
+		if names[op.Name] {
+			c.errorf([]ast.Loc{op.Loc}, "There can be only one operation named %q.", op.Name)
+		}
+		names[op.Name] = true
+	}
+}
+
+// operation validates an operation and marks the fragments it uses in used
+func (c *check) operation(op *ast.Operation, used map[string]bool) {
+	root := c.schema.Query
+	if op.Type == ast.OpMutation {
+		root = c.schema.Mutation
+		if root == nil {
+			c.errorf([]ast.Loc{op.Loc}, "Schema is not configured for mutations.")
+			return
+		}
+	}
+
+	sc := &scope{}
+	c.directives(op.Directives, sc)
+	c.selection(root, op.SelectionSet, sc)
+
+	// gather the variables referenced by the operation and the fragments it reaches
+	variables := sc.variables
+	reached := map[string]bool{}
+	var reach func(names []string)
+	reach = func(names []string) {
+		for _, name := range names {
+			fragment, ok := c.fragments[name]
+			if !ok || reached[name] {
+				continue
+			}
+			reached[name] = true
+			used[name] = true
+			variables = append(variables, fragment.variables...)
+			reach(fragment.spreads)
+		}
+	}
+	reach(sc.spreads)
+
+	c.variables(op, variables)
+}
+
+// variables checks the operation's variable definitions against the variables it uses
+func (c *check) variables(op *ast.Operation, usages []*usage) {
+	in := ""
+	if op.Name != "" {
+		in = fmt.Sprintf(" by operation %q", op.Name)
+	}
+
+	for _, def := range op.VariableDefinitions {
+		named := c.types[baseName(def.Type)]
+		if named == nil {
+			c.errorf([]ast.Loc{def.Loc}, "Unknown type %q.", baseName(def.Type))
+		} else if !schema.IsInput(named) {
+			c.errorf([]ast.Loc{def.Loc}, "Variable \"$%v\" cannot be non-input type %q.", def.Name, def.Type)
+		}
+
+		used := false
+		for _, u := range usages {
+			used = used || u.name == def.Name
+		}
+		if !used {
+			c.errorf([]ast.Loc{def.Loc}, "Variable \"$%v\" is never used%v.", def.Name, in)
+		}
+	}
+
+	for _, u := range usages {
+		def := op.Variable(u.name)
+		if def == nil {
+			c.errorf([]ast.Loc{u.loc, op.Loc}, "Variable \"$%v\" is not defined%v.", u.name, in)
+			continue
+		}
+		if u.expected != nil && !allowed(def, u.expected) {
+			c.errorf([]ast.Loc{def.Loc, u.loc}, "Variable \"$%v\" of type %q used in position expecting type %q.", u.name, def.Type, u.expected)
+		}
+	}
+}
+
+// fragmentCycles reports fragments that spread themselves, directly or through others
+func (c *check) fragmentCycles() {
+	visited := map[string]bool{}
+	var path []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		for index, other := range path {
+			if other == name {
+				fragment := c.doc.Fragment(name)
+				via := ""
+				if cycle := path[index+1:]; len(cycle) > 0 {
+					via = " via " + strings.Join(cycle, ", ")
+				}
+				c.errorf([]ast.Loc{fragment.Loc}, "Cannot spread fragment %q within itself%v.", name, via)
+				return
+			}
+		}
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		sc, ok := c.fragments[name]
+		if !ok {
+			return
+		}
+		path = append(path, name)
+		for _, spread := range sc.spreads {
+			visit(spread)
+		}
+		path = path[:len(path)-1]
+	}
+
+	for _, fragment := range c.doc.Fragments {
+		visit(fragment.Name)
+	}
+}
+
+// --[ Selections ]---------------------------------------------------
+
+// selection checks each selector of a selection set against the parent type
+func (c *check) selection(parent schema.Named, selection *ast.Selection, sc *scope) {
+	if selection == nil {
+		return
+	}
+
+	for _, selector := range selection.Selectors {
+		switch s := selector.(type) {
+		case *ast.Field:
+			c.directives(s.Directives, sc)
+			c.field(parent, s, sc)
+
+		case *ast.FragmentSpread:
+			c.directives(s.Directives, sc)
+			if c.doc.Fragment(s.Name) == nil {
+				c.errorf([]ast.Loc{s.Loc}, "Unknown fragment %q.", s.Name)
+				continue
+			}
+			sc.spreads = append(sc.spreads, s.Name)
+
+		case *ast.InlineFragment:
+			c.directives(s.Directives, sc)
+			t := parent
+			if s.On != "" {
+				if t = c.typeCondition(s.On, s.Loc); t == nil {
+					continue
+				}
+			}
+			c.selection(t, s.Selection, sc)
+		}
+	}
+
+	c.overlaps(parent, selection)
+}
+
+// typeCondition returns the named composite type or nil, after reporting an error, if there
+// is no such type
+func (c *check) typeCondition(name string, loc ast.Loc) schema.Named {
+	t, ok := c.types[name]
+	if !ok {
+		c.errorf([]ast.Loc{loc}, "Unknown type %q.", name)
+		return nil
+	}
+	if schema.IsLeaf(t) || schema.IsInput(t) {
+		c.errorf([]ast.Loc{loc}, "Fragment cannot condition on non composite type %q.", name)
+		return nil
+	}
+	return t
+}
+
+// field checks a field exists on the parent type, its arguments and its selection
+func (c *check) field(parent schema.Named, f *ast.Field, sc *scope) {
+	def := c.fieldDef(parent, f.Name)
+	if def == nil {
+		c.errorf([]ast.Loc{f.Loc}, "Cannot query field %q on type %q.", f.Name, parent.TypeName())
+		return
+	}
+
+	for _, arg := range f.Args {
+		if arg.Name == "" {
+			continue
+		}
+		argDef := def.Arg(arg.Name)
+		if argDef == nil {
+			c.errorf([]ast.Loc{f.Loc}, "Unknown argument %q on field \"%v.%v\".", arg.Name, parent.TypeName(), f.Name)
+			c.value(nil, arg.Value, sc)
+			continue
+		}
+		if !c.value(argDef.Type, arg.Value, sc) {
+			c.errorf([]ast.Loc{f.Loc}, "Argument %q has invalid value %v; expected type %q.", arg.Name, arg.Value, argDef.Type)
+		}
+	}
+	for _, argDef := range def.Args {
+		if isRequired(argDef) && findArg(f.Args, argDef.Name) == nil {
+			c.errorf([]ast.Loc{f.Loc}, "Field %q argument %q of type %q is required, but it was not provided.", f.Name, argDef.Name, argDef.Type)
+		}
+	}
+
+	t := schema.Unwrap(def.Type)
+	switch {
+	case schema.IsLeaf(t) && !f.IsScalar():
+		c.errorf([]ast.Loc{f.Loc}, "Field %q must not have a selection since type %q has no subfields.", f.Name, def.Type)
+	case !schema.IsLeaf(t) && f.IsScalar():
+		c.errorf([]ast.Loc{f.Loc}, "Field %q of type %q must have a selection of subfields. Did you mean \"%v { ... }\"?", f.Name, def.Type, f.Name)
+	case !f.IsScalar():
+		c.selection(t, f.Selection, sc)
+	}
+}
+
+// fieldDef returns the definition of a field of the parent type, including the introspection
+// meta fields, or nil if the type has no such field
+func (c *check) fieldDef(parent schema.Named, name string) *schema.Field {
+	switch {
+	case name == "__typename":
+		return &schema.Field{Name: name, Type: &schema.NonNull{OfType: schema.String}}
+	case name == "__schema" && parent == c.schema.Query:
+		return &schema.Field{Name: name, Type: &schema.NonNull{OfType: c.types["__Schema"]}}
+	case name == "__type" && parent == c.schema.Query:
+		return &schema.Field{
+			Name: name,
+			Type: c.types["__Type"],
+			Args: []*schema.InputValue{{Name: "name", Type: &schema.NonNull{OfType: schema.String}}},
+		}
+	}
+
+	switch t := parent.(type) {
+	case *schema.ObjectType:
+		return t.Field(name)
+	case *schema.InterfaceType:
+		return t.Field(name)
+	default:
+		return nil
+	}
+}
+
+// directives records the variables used by directive arguments; @skip and @include expect
+// a Boolean!
+func (c *check) directives(directives []*ast.Directive, sc *scope) {
+	for _, d := range directives {
+		var expected schema.Type
+		if d.Name == "skip" || d.Name == "include" {
+			expected = &schema.NonNull{OfType: schema.Boolean}
+		}
+		for _, arg := range d.Args {
+			if !c.value(expected, arg.Value, sc) {
+				c.errorf([]ast.Loc{arg.Loc}, "Argument %q of @%v has invalid value %v; expected type %q.", arg.Name, d.Name, arg.Value, expected)
+			}
+		}
+	}
+}
+
+// --[ Values ]-------------------------------------------------------
+
+// value reports whether a literal is valid for the type and records the variables it
+// references; any value is accepted when t is nil
+func (c *check) value(t schema.Type, value ast.Value, sc *scope) bool {
+	if v, ok := value.(*ast.Variable); ok {
+		sc.variables = append(sc.variables, &usage{name: v.Name, expected: t, loc: v.Loc})
+		return true
+	}
+
+	if t == nil {
+		switch v := value.(type) {
+		case *ast.ListValue:
+			for _, item := range v.Values {
+				c.value(nil, item, sc)
+			}
+		case *ast.ObjectValue:
+			for _, field := range v.Fields {
+				c.value(nil, field.Value, sc)
+			}
+		}
+		return true
+	}
+
+	if nonNull, ok := t.(*schema.NonNull); ok {
+		if _, ok := value.(*ast.NullValue); ok {
+			return false
+		}
+		return c.value(nonNull.OfType, value, sc)
+	}
+	if _, ok := value.(*ast.NullValue); ok {
+		return true
+	}
+
+	switch typ := t.(type) {
+	case *schema.List:
+		list, ok := value.(*ast.ListValue)
+		if !ok {
+			return c.value(typ.OfType, value, sc)
+		}
+		valid := true
+		for _, item := range list.Values {
+			valid = c.value(typ.OfType, item, sc) && valid
+		}
+		return valid
+
+	case *schema.InputObjectType:
+		object, ok := value.(*ast.ObjectValue)
+		if !ok {
+			return false
+		}
+		valid := true
+		for _, field := range object.Fields {
+			def := typ.Field(field.Name)
+			valid = def != nil && c.value(def.Type, field.Value, sc) && valid
+		}
+		for _, def := range typ.Fields {
+			if isRequired(def) && findObjectField(object.Fields, def.Name) == nil {
+				valid = false
+			}
+		}
+		return valid
+
+	case *schema.EnumType:
+		enum, ok := value.(*ast.EnumValue)
+		return ok && typ.Value(enum.Value) != nil
+
+	case *schema.ScalarType:
+		return validScalar(typ, value)
+
+	default:
+		return false
+	}
+}
+
+// validScalar reports whether a literal can be parsed by the scalar; literals of scalars
+// without ParseLiteral are accepted as is
+func validScalar(t *schema.ScalarType, value ast.Value) bool {
+	if t.ParseLiteral == nil {
+		return true
+	}
+	_, err := t.ParseLiteral(value)
+	return err == nil
+}
+
+// allowed reports whether a variable of the defined type may be used where the expected type
+// is required.  A nullable variable with a default may be used in a non-null position.
+func allowed(def *ast.VariableDefinition, expected schema.Type) bool {
+	if nonNull, ok := expected.(*schema.NonNull); ok && !def.Type.NonNull && def.DefaultValue != nil {
+		if _, isNull := def.DefaultValue.(*ast.NullValue); !isNull {
+			return compatible(def.Type, nonNull.OfType)
+		}
+	}
+	return compatible(def.Type, expected)
+}
+
+// compatible reports whether a variable type is the same as, or stricter than, a schema type
+func compatible(v *ast.Type, t schema.Type) bool {
+	if nonNull, ok := t.(*schema.NonNull); ok {
+		return v.NonNull && compatible(nullable(v), nonNull.OfType)
+	}
+	if v.NonNull {
+		return compatible(nullable(v), t)
+	}
+	if list, ok := t.(*schema.List); ok {
+		return v.IsList() && compatible(v.Elem, list.OfType)
+	}
+	named, ok := t.(schema.Named)
+	return ok && !v.IsList() && v.Name == named.TypeName()
+}
+
+func nullable(v *ast.Type) *ast.Type {
+	return &ast.Type{Name: v.Name, Elem: v.Elem}
+}
+
+// baseName returns the name of the named type within a variable type
+func baseName(v *ast.Type) string {
+	for v.IsList() {
+		v = v.Elem
+	}
+	return v.Name
+}
+
+func isRequired(def *schema.InputValue) bool {
+	_, nonNull := def.Type.(*schema.NonNull)
+	return nonNull && !def.HasDefaultValue
+}
+
+func findArg(args []*ast.Arg, name string) *ast.Arg {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func findObjectField(fields []*ast.ObjectField, name string) *ast.ObjectField {
+	for _, field := range fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
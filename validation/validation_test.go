@@ -0,0 +1,257 @@
+package validation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/savaki/graphql"
+	"github.com/savaki/graphql/ast"
+	"github.com/savaki/graphql/schema"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	episodeEnum = &schema.EnumType{
+		Name:   "Episode",
+		Values: []*schema.EnumValue{{Name: "NEWHOPE"}, {Name: "EMPIRE"}, {Name: "JEDI"}},
+	}
+
+	characterInterface = &schema.InterfaceType{
+		Name: "Character",
+		Fields: []*schema.Field{
+			{Name: "name", Type: &schema.NonNull{OfType: schema.String}},
+		},
+	}
+
+	humanType = &schema.ObjectType{
+		Name:       "Human",
+		Interfaces: []*schema.InterfaceType{characterInterface},
+		Fields: []*schema.Field{
+			{Name: "name", Type: &schema.NonNull{OfType: schema.String}},
+			{Name: "height", Type: schema.Float, Args: []*schema.InputValue{
+				{Name: "unit", Type: schema.String, DefaultValue: "METER", HasDefaultValue: true},
+			}},
+			{Name: "friends", Type: &schema.List{OfType: characterInterface}},
+		},
+	}
+
+	droidType = &schema.ObjectType{
+		Name:       "Droid",
+		Interfaces: []*schema.InterfaceType{characterInterface},
+		Fields: []*schema.Field{
+			{Name: "name", Type: &schema.NonNull{OfType: schema.String}},
+			{Name: "primaryFunction", Type: schema.String},
+			{Name: "height", Type: schema.Int},
+		},
+	}
+
+	reviewInput = &schema.InputObjectType{
+		Name: "ReviewInput",
+		Fields: []*schema.InputValue{
+			{Name: "stars", Type: &schema.NonNull{OfType: schema.Int}},
+			{Name: "commentary", Type: schema.String},
+		},
+	}
+
+	starWars = &schema.Schema{
+		Query: &schema.ObjectType{
+			Name: "Query",
+			Fields: []*schema.Field{
+				{Name: "hero", Type: characterInterface, Args: []*schema.InputValue{
+					{Name: "episode", Type: episodeEnum},
+				}},
+				{Name: "human", Type: humanType, Args: []*schema.InputValue{
+					{Name: "id", Type: &schema.NonNull{OfType: schema.ID}},
+				}},
+			},
+		},
+		Mutation: &schema.ObjectType{
+			Name: "Mutation",
+			Fields: []*schema.Field{
+				{Name: "createReview", Type: schema.Int, Args: []*schema.InputValue{
+					{Name: "episode", Type: &schema.NonNull{OfType: episodeEnum}},
+					{Name: "review", Type: &schema.NonNull{OfType: reviewInput}},
+				}},
+			},
+		},
+		Types: []schema.Named{droidType},
+	}
+)
+
+// validate parses and validates the query, returning the messages of the errors found
+func validate(query string) []string {
+	doc, err := ast.Parse(query)
+	So(err, ShouldBeNil)
+
+	var messages []string
+	for _, err := range Validate(starWars, doc) {
+		messages = append(messages, err.Message)
+	}
+	return messages
+}
+
+func TestValid(t *testing.T) {
+	Convey("Given valid queries", t, func() {
+		queries := []string{
+			`{ hero { name ... on Human { height(unit: "FOOT") friends { name } } } }`,
+			`query H($id: ID!, $ep: Episode = JEDI) { human(id: $id) { name } hero(episode: $ep) { ...C } }
+			fragment C on Character { name ... on Droid { primaryFunction } }`,
+			`{ hero { ... on Human { name } ... on Droid { name } } }`,
+			`{ hero { name name n: name } }`,
+			`{ __typename __schema { types { name } } __type(name: "Human") { name } hero { __typename } }`,
+			`mutation { createReview(episode: JEDI, review: {stars: 5}) }`,
+			`query human(id: 1) { name }`,
+		}
+
+		for _, query := range queries {
+			So(validate(query), ShouldBeEmpty)
+		}
+	})
+}
+
+func TestFields(t *testing.T) {
+	Convey("Given a query for a field the type doesn't define", t, func() {
+		So(validate(`{ hero { name age } }`), ShouldResemble, []string{
+			`Cannot query field "age" on type "Character".`,
+		})
+	})
+
+	Convey("Given a leaf field with a selection and an object field without one", t, func() {
+		So(validate(`{ hero { name { first } } human(id: 1) }`), ShouldResemble, []string{
+			`Field "name" must not have a selection since type "String!" has no subfields.`,
+			`Field "human" of type "Human" must have a selection of subfields. Did you mean "human { ... }"?`,
+		})
+	})
+}
+
+func TestArguments(t *testing.T) {
+	Convey("Given unknown, invalid and missing arguments", t, func() {
+		So(validate(`{ hero(episode: CLONES, season: 1) { name } human { name } }`), ShouldResemble, []string{
+			`Argument "episode" has invalid value CLONES; expected type "Episode".`,
+			`Unknown argument "season" on field "Query.hero".`,
+			`Field "human" argument "id" of type "ID!" is required, but it was not provided.`,
+		})
+	})
+
+	Convey("Given input objects missing required fields or with values out of range", t, func() {
+		So(validate(`mutation { createReview(episode: JEDI, review: {commentary: "ok"}) }`), ShouldResemble, []string{
+			`Argument "review" has invalid value {commentary: "ok"}; expected type "ReviewInput!".`,
+		})
+		So(validate(`mutation { createReview(episode: JEDI, review: {stars: 4294967296}) }`), ShouldHaveLength, 1)
+	})
+}
+
+func TestOperations(t *testing.T) {
+	Convey("Given several operations with distinct names", t, func() {
+		So(validate(`query A { hero { name } } query B($id: ID!) { human(id: $id) { name } }`), ShouldBeEmpty)
+	})
+
+	Convey("Given operations that can't be told apart by name", t, func() {
+		So(validate(`query A { hero { name } } query A { hero { name } } { hero { name } }`), ShouldResemble, []string{
+			`There can be only one operation named "A".`,
+			`This anonymous operation must be the only defined operation.`,
+		})
+	})
+}
+
+func TestVariables(t *testing.T) {
+	Convey("Given undefined and unused variables", t, func() {
+		So(validate(`query Q($unused: Int) { human(id: $id) { name } }`), ShouldResemble, []string{
+			`Variable "$unused" is never used by operation "Q".`,
+			`Variable "$id" is not defined by operation "Q".`,
+		})
+	})
+
+	Convey("Given variables used within fragments", t, func() {
+		So(validate(`query Q($id: ID!) { ...F } fragment F on Query { human(id: $id) { name } }`), ShouldBeEmpty)
+		So(validate(`{ ...F } fragment F on Query { human(id: $id) { name } }`), ShouldResemble, []string{
+			`Variable "$id" is not defined.`,
+		})
+		So(validate(`query Q { ...F } fragment F on Query { human(id: $id) { name } }`), ShouldResemble, []string{
+			`Variable "$id" is not defined by operation "Q".`,
+		})
+	})
+
+	Convey("Given a variable of the wrong type", t, func() {
+		So(validate(`query Q($id: String) { human(id: $id) { name } }`), ShouldResemble, []string{
+			`Variable "$id" of type "String" used in position expecting type "ID!".`,
+		})
+		So(validate(`query Q($h: Human) { hero { name @include(if: $h) } }`), ShouldResemble, []string{
+			`Variable "$h" cannot be non-input type "Human".`,
+			`Variable "$h" of type "Human" used in position expecting type "Boolean!".`,
+		})
+	})
+}
+
+func TestFragments(t *testing.T) {
+	Convey("Given unknown and unused fragments", t, func() {
+		So(validate(`{ hero { ...Missing } } fragment Unused on Human { name }`), ShouldResemble, []string{
+			`Unknown fragment "Missing".`,
+			`Fragment "Unused" is never used.`,
+		})
+	})
+
+	Convey("Given fragments on unknown or leaf types", t, func() {
+		So(validate(`{ hero { ... on Wookie { name } ...F } } fragment F on Episode { name }`), ShouldResemble, []string{
+			`Fragment cannot condition on non composite type "Episode".`,
+			`Unknown type "Wookie".`,
+		})
+	})
+
+	Convey("Given fragments that spread themselves", t, func() {
+		So(validate(`{ hero { ...A } } fragment A on Character { ...B } fragment B on Character { ...A }`), ShouldResemble, []string{
+			`Cannot spread fragment "A" within itself via B.`,
+		})
+	})
+}
+
+func TestOverlaps(t *testing.T) {
+	Convey("Given fields with the same response key that can't be merged", t, func() {
+		So(validate(`{ hero { name: __typename name } }`), ShouldResemble, []string{
+			`Fields "name" conflict because "__typename" and "name" are different fields. Use different aliases on the fields to fetch both if this was intentional.`,
+		})
+		So(validate(`{ human(id: 1) { height(unit: "FOOT") height } }`), ShouldResemble, []string{
+			`Fields "height" conflict because they have differing arguments. Use different aliases on the fields to fetch both if this was intentional.`,
+		})
+		So(validate(`{ hero { ... on Human { h: height } ... on Droid { h: height } } }`), ShouldResemble, []string{
+			`Fields "h" conflict because they return conflicting types "Float" and "Int". Use different aliases on the fields to fetch both if this was intentional.`,
+		})
+		So(validate(`{ human(id: 1) { friends { name } ...F } } fragment F on Human { friends { name: __typename } }`), ShouldResemble, []string{
+			`Fields "friends" conflict because subfields "name" conflict because "name" and "__typename" are different fields. Use different aliases on the fields to fetch both if this was intentional.`,
+		})
+	})
+}
+
+func TestLocations(t *testing.T) {
+	Convey("Given an invalid query", t, func() {
+		doc, err := ast.Parse("{\n  hero {\n    age\n  }\n}")
+		So(err, ShouldBeNil)
+
+		errs := Validate(starWars, doc)
+		So(errs, ShouldHaveLength, 1)
+
+		Convey("Then the error carries the location of the field", func() {
+			So(errs[0].Locations, ShouldResemble, []ast.Loc{{Start: 15, End: 18, Line: 3, Column: 5}})
+			So(errs.Error(), ShouldEqual, `Cannot query field "age" on type "Character". (3:5)`)
+		})
+	})
+}
+
+func TestExecutor(t *testing.T) {
+	Convey("Given an executor with a Validator", t, func() {
+		executor := graphql.Executor{
+			Store:     schema.NewStore(starWars, nil),
+			Validator: New(starWars),
+		}
+
+		Convey("When I execute an invalid query", func() {
+			w := &bytes.Buffer{}
+			err := executor.Handle(`{ hero { name } human(id: 1) { age } }`, w)
+
+			Convey("Then it's rejected before execution with a response that has no data", func() {
+				So(err, ShouldHaveSameTypeAs, graphql.Errors{})
+				So(w.String(), ShouldEqual, `{"errors":[{"message":"Cannot query field \"age\" on type \"Human\".","locations":[{"line":1,"column":32}]}]}`)
+			})
+		})
+	})
+}